@@ -0,0 +1,189 @@
+package compare
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ArchiveOptions specifies which of the attributes of the archive entries
+// are ignored by the archive comparison helpers.
+type ArchiveOptions struct {
+	// If IgnoreModTime is set, the modification times of the entries
+	// are not compared.
+	IgnoreModTime bool
+	// If IgnoreMode is set, the permission and mode bits of the entries
+	// are not compared, the type of the entries, i.e. whether an entry is
+	// a directory or a regular file, is nevertheless compared.
+	IgnoreMode bool
+}
+
+// ArchiveEntry represents a single entry of an archive as it is compared by
+// the archive comparison helpers. The entries of an archive are compared as
+// a map of their names to their ArchiveEntry values.
+type ArchiveEntry struct {
+	Mode    fs.FileMode
+	ModTime time.Time
+	Content string
+}
+
+// CompareTar is a wrapper around DefaultConfig.CompareTar.
+func CompareTar(got, want io.Reader, opts ArchiveOptions) error {
+	return DefaultConfig.CompareTar(got, want, opts)
+}
+
+// CompareTar reads the two given tar archives and compares their entries, i.e.
+// the names, the modes, the modification times, and the contents of the files
+// and directories they contain.
+func (conf Config) CompareTar(got, want io.Reader, opts ArchiveOptions) error {
+	gotm, err := readTar(got, opts)
+	if err != nil {
+		return fmt.Errorf("compare: failed to read got archive: %w", err)
+	}
+	wantm, err := readTar(want, opts)
+	if err != nil {
+		return fmt.Errorf("compare: failed to read want archive: %w", err)
+	}
+	return conf.compareArchives(gotm, wantm)
+}
+
+// CompareZip is a wrapper around DefaultConfig.CompareZip.
+func CompareZip(got, want *zip.Reader, opts ArchiveOptions) error {
+	return DefaultConfig.CompareZip(got, want, opts)
+}
+
+// CompareZip compares the entries of the two given zip archives, see
+// CompareTar.
+func (conf Config) CompareZip(got, want *zip.Reader, opts ArchiveOptions) error {
+	gotm, err := readZip(got, opts)
+	if err != nil {
+		return fmt.Errorf("compare: failed to read got archive: %w", err)
+	}
+	wantm, err := readZip(want, opts)
+	if err != nil {
+		return fmt.Errorf("compare: failed to read want archive: %w", err)
+	}
+	return conf.compareArchives(gotm, wantm)
+}
+
+// CompareArchiveFiles is a wrapper around DefaultConfig.CompareArchiveFiles.
+func CompareArchiveFiles(got, want string, opts ArchiveOptions) error {
+	return DefaultConfig.CompareArchiveFiles(got, want, opts)
+}
+
+// CompareArchiveFiles opens the two archive files at the given paths and
+// compares their entries, see CompareTar. The format of each archive is
+// determined by the extension of its file name, which must be one of ".zip",
+// ".tar", ".tar.gz", or ".tgz".
+func (conf Config) CompareArchiveFiles(got, want string, opts ArchiveOptions) error {
+	gotm, err := readArchiveFile(got, opts)
+	if err != nil {
+		return fmt.Errorf("compare: failed to read got archive: %w", err)
+	}
+	wantm, err := readArchiveFile(want, opts)
+	if err != nil {
+		return fmt.Errorf("compare: failed to read want archive: %w", err)
+	}
+	return conf.compareArchives(gotm, wantm)
+}
+
+// compareArchives compares the entries of two archives. Unless the Config
+// has a formatter for them, the modes of the entries are rendered in their
+// symbolic form, e.g. -rw-r--r--.
+func (conf Config) compareArchives(got, want map[string]ArchiveEntry) error {
+	modeType := reflect.TypeOf(fs.FileMode(0))
+	if _, ok := conf.Formatters[modeType]; !ok {
+		conf = conf.With(WithFormatter(modeType, func(v interface{}) string {
+			return v.(fs.FileMode).String()
+		}))
+	}
+	return conf.Compare(got, want)
+}
+
+// readArchiveFile reads the entries of the archive file with the given name.
+func readArchiveFile(name string, opts ArchiveOptions) (map[string]ArchiveEntry, error) {
+	if strings.HasSuffix(name, ".zip") {
+		rc, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return readZip(&rc.Reader, opts)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return readTar(f, opts)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return readTar(zr, opts)
+	}
+	return nil, fmt.Errorf("unknown archive format of %q", name)
+}
+
+// readTar reads the entries of the tar archive r.
+func readTar(r io.Reader, opts ArchiveOptions) (map[string]ArchiveEntry, error) {
+	m := make(map[string]ArchiveEntry)
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return m, nil
+		} else if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		m[h.Name] = newArchiveEntry(h.FileInfo(), data, opts)
+	}
+}
+
+// readZip reads the entries of the zip archive r.
+func readZip(r *zip.Reader, opts ArchiveOptions) (map[string]ArchiveEntry, error) {
+	m := make(map[string]ArchiveEntry, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		m[f.Name] = newArchiveEntry(f.FileInfo(), data, opts)
+	}
+	return m, nil
+}
+
+// newArchiveEntry returns the entry of the file with the given info and
+// content with its ignored attributes unset.
+func newArchiveEntry(fi fs.FileInfo, data []byte, opts ArchiveOptions) ArchiveEntry {
+	e := ArchiveEntry{Mode: fi.Mode(), ModTime: fi.ModTime(), Content: string(data)}
+	if opts.IgnoreModTime {
+		e.ModTime = time.Time{}
+	}
+	if opts.IgnoreMode {
+		e.Mode = e.Mode.Type()
+	}
+	return e
+}
@@ -0,0 +1,135 @@
+package compare
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testArchiveFile struct {
+	name    string
+	mode    int64
+	modTime time.Time
+	content string
+}
+
+func testTar(t *testing.T, files ...testArchiveFile) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		h := &tar.Header{Name: f.name, Mode: f.mode, ModTime: f.modTime, Size: int64(len(f.content))}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testZip(t *testing.T, files ...testArchiveFile) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		h := &zip.FileHeader{Name: f.name, Modified: f.modTime}
+		h.SetMode(os.FileMode(f.mode))
+		w, err := zw.CreateHeader(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompareArchives(t *testing.T) {
+	t1 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	base := []testArchiveFile{
+		{name: "bin/app", mode: 0755, modTime: t1, content: "binary"},
+		{name: "README", mode: 0644, modTime: t1, content: "hello"},
+	}
+	tests := []struct {
+		got  []testArchiveFile
+		opts ArchiveOptions
+		want string
+	}{{
+		got:  base,
+		want: "",
+	}, {
+		got: []testArchiveFile{
+			{name: "bin/app", mode: 0700, modTime: t2, content: "binary"},
+			{name: "README", mode: 0644, modTime: t1, content: "hello"},
+		},
+		opts: ArchiveOptions{IgnoreModTime: true, IgnoreMode: true},
+		want: "",
+	}, {
+		got: []testArchiveFile{
+			{name: "bin/app", mode: 0700, modTime: t1, content: "binary"},
+			{name: "README", mode: 0644, modTime: t2, content: "hallo"},
+		},
+		want: "- (map[string]compare.ArchiveEntry)[README].Content: Value mismatch; got=\"hallo\", want=\"hello\"; differs at byte 1 (rune 1)\n" +
+			"- (map[string]compare.ArchiveEntry)[README].ModTime: Value mismatch; got=2024-01-02T04:04:05Z, want=2024-01-02T03:04:05Z\n" +
+			"- (map[string]compare.ArchiveEntry)[bin/app].Mode: Value mismatch; got=-rwx------, want=-rwxr-xr-x\n",
+	}, {
+		got: []testArchiveFile{
+			{name: "bin/app", mode: 0755, modTime: t1, content: "binary"},
+			{name: "LICENSE", mode: 0644, modTime: t1, content: "MIT"},
+		},
+		opts: ArchiveOptions{IgnoreModTime: true},
+		want: "- (map[string]compare.ArchiveEntry)[README]: Key README missing in got; want={-rw-r--r-- 0001-01-01 00:00:00 +0000 UTC hello}\n",
+	}}
+
+	for i, tt := range tests {
+		err := CompareTar(bytes.NewReader(testTar(t, tt.got...)), bytes.NewReader(testTar(t, base...)), tt.opts)
+		if got := Golden(err); got != tt.want {
+			t.Errorf("#%d: CompareTar got:\n%s\nwant:\n%s", i, got, tt.want)
+		}
+
+		gotz, wantz := testZip(t, tt.got...), testZip(t, base...)
+		gotr, err := zip.NewReader(bytes.NewReader(gotz), int64(len(gotz)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantr, err := zip.NewReader(bytes.NewReader(wantz), int64(len(wantz)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Golden(CompareZip(gotr, wantr, tt.opts)); got != tt.want {
+			t.Errorf("#%d: CompareZip got:\n%s\nwant:\n%s", i, got, tt.want)
+		}
+	}
+}
+
+func TestCompareArchiveFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []testArchiveFile{{name: "a.txt", mode: 0644, content: "a"}}
+	tarFile, zipFile := filepath.Join(dir, "a.tar"), filepath.Join(dir, "a.zip")
+	if err := os.WriteFile(tarFile, testTar(t, files...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(zipFile, testZip(t, files...), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CompareArchiveFiles(tarFile, zipFile, ArchiveOptions{IgnoreModTime: true}); err != nil {
+		t.Errorf("CompareArchiveFiles got=%v, want=<nil>", err)
+	}
+	if err := CompareArchiveFiles(tarFile, filepath.Join(dir, "a.rar"), ArchiveOptions{}); err == nil {
+		t.Errorf("CompareArchiveFiles got=<nil>, want an error")
+	}
+}
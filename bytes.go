@@ -0,0 +1,76 @@
+package compare
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// compareBytes compares the two given byte slices as a whole, see HexDump and
+// BytesAsString. It reports whether the slices were compared, if not, they have
+// to be compared element by element.
+func (conf Config) compareBytes(got, want reflect.Value, cmp *comparison, p path) bool {
+	gotb, wantb := got.Bytes(), want.Bytes()
+	if bytes.Equal(gotb, wantb) {
+		return true
+	}
+	if _, ok := conf.Formatters[want.Type()]; ok {
+		cmp.errs.add(&valueError{got, want, p})
+		return true
+	}
+	if conf.BytesAsString && utf8.Valid(gotb) && utf8.Valid(wantb) {
+		conf.compareString(reflect.ValueOf(string(gotb)), reflect.ValueOf(string(wantb)), cmp, p)
+		return true
+	}
+	if conf.HexDump {
+		cmp.errs.add(&bytesError{gotb, wantb, p})
+		return true
+	}
+	return false
+}
+
+// hexRowLen is the number of bytes shown in a single row of a hex dump.
+const hexRowLen = 16
+
+// hexRow renders the row of a hex dump of the bytes b that starts at the
+// given offset, in the style of "hexdump -C", i.e. the offset is followed by
+// the bytes in hex, and the bytes as ASCII text, in which the non-printable
+// bytes are shown as ".". The bytes at which b differs from the other slice
+// are highlighted using the given color.
+func hexRow(pr printer, b, other []byte, offset int, color string) string {
+	var hex, text strings.Builder
+	for i := offset; i < offset+hexRowLen; i++ {
+		if i == offset+hexRowLen/2 {
+			hex.WriteByte(' ')
+		}
+		if i >= len(b) {
+			hex.WriteString("   ")
+			continue
+		}
+
+		x, c := fmt.Sprintf("%02x", b[i]), "."
+		if b[i] >= 0x20 && b[i] < 0x7f {
+			c = string(b[i])
+		}
+		if pr.colored() && (i >= len(other) || b[i] != other[i]) {
+			x, c = color+x+stopColor, color+c+stopColor
+		}
+		hex.WriteString(" " + x)
+		text.WriteString(c)
+	}
+	return fmt.Sprintf("%08x %s  |%s|", offset, hex.String(), text.String())
+}
+
+// bdiff returns the offset of the first byte at which a and b differ,
+// or the length of the shorter of the two if it is a prefix of the other.
+func bdiff(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
@@ -0,0 +1,78 @@
+package compare
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareHexDump(t *testing.T) {
+	type T struct{ Data []byte }
+
+	tests := []struct {
+		conf      Config
+		got, want interface{}
+		out       string
+	}{{
+		conf: Config{HexDump: true},
+		got:  []byte("hello, world"), want: []byte("hello, World"),
+		out: "- ([]uint8): Value mismatch (-got +want); differs at byte 7:\n" +
+			"\t- 00000000  68 65 6c 6c 6f 2c 20 77  6f 72 6c 64              |hello, world|\n" +
+			"\t+ 00000000  68 65 6c 6c 6f 2c 20 57  6f 72 6c 64              |hello, World|",
+	}, {
+		conf: Config{HexDump: true},
+		got:  T{[]byte("0123456789abcdef0123456789abcdef\x00\x01")},
+		want: T{[]byte("0123456789abcdef0123456789abcdef\x00\x02\x03")},
+		out: "- (compare.T).Data: Value mismatch (-got +want); differs at byte 33; got len=34, want len=35:\n" +
+			"\t  00000010  30 31 32 33 34 35 36 37  38 39 61 62 63 64 65 66  |0123456789abcdef|\n" +
+			"\t- 00000020  00 01                                             |..|\n" +
+			"\t+ 00000020  00 02 03                                          |...|",
+	}, {
+		conf: Config{HexDump: true},
+		got:  []byte("ab"), want: []byte("ab"),
+	}, {
+		conf: Config{BytesAsString: true},
+		got:  []byte("abc"), want: []byte("abd"),
+		out: `- ([]uint8): Value mismatch; got="abc", want="abd"; differs at byte 2 (rune 2)`,
+	}, {
+		conf: Config{BytesAsString: true, HexDump: true},
+		got:  []byte{0xff}, want: []byte{0xfe},
+		out: "- ([]uint8): Value mismatch (-got +want); differs at byte 0:\n" +
+			"\t- 00000000  ff                                                |.|\n" +
+			"\t+ 00000000  fe                                                |.|",
+	}, {
+		conf: Config{BytesAsString: true},
+		got:  []byte{0xff}, want: []byte{0xfe},
+		out: "- ([]uint8)[0]: Value mismatch; got=255, want=254",
+	}}
+
+	for i, tt := range tests {
+		tt.conf.NoColor = true
+		err := tt.conf.Compare(tt.got, tt.want)
+		if tt.out == "" {
+			if err != nil {
+				t.Errorf("#%d: Compare() got=%v, want nil", i, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.out {
+			t.Errorf("#%d: Compare() got=%v, want=%q", i, err, tt.out)
+		}
+	}
+
+	conf := Config{HexDump: true}
+	_, reason := conf.Reason([]byte("ab"), []byte("abc"))
+	if want := "- ([]uint8): Value mismatch; differs at byte 2; got len=2, want len=3"; reason != want {
+		t.Errorf("Reason() got=%q, want=%q", reason, want)
+	}
+
+	diffs := Differences(conf.Compare([]byte("ab"), []byte("ax")))
+	if len(diffs) != 1 || diffs[0].Detail != (BytesDifference{1}) {
+		t.Errorf("Differences() got=%+v", diffs)
+	}
+
+	// the differing bytes are highlighted
+	out := (&bytesError{[]byte("ab"), []byte("ax"), path{}}).format(printer{})
+	if !strings.Contains(out, diffGotColor+"62"+stopColor) || !strings.Contains(out, diffWantColor+"78"+stopColor) {
+		t.Errorf("format() got=%q, want the differing bytes highlighted", out)
+	}
+}
@@ -0,0 +1,237 @@
+package compare
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ResultCache is a cache of the results of Compare, see Config.ResultCache.
+// It holds a limited number of results and evicts the least recently used
+// ones first. A ResultCache is safe for concurrent use. Note that the cached
+// errors refer to the values of the comparison that produced them, if those
+// values are modified afterwards the cached errors will render the modified
+// values.
+type ResultCache struct {
+	mu    sync.Mutex
+	size  int
+	lru   *list.List // the cacheEntries, most recently used first
+	items map[cacheKey]*list.Element
+}
+
+type cacheKey [16]byte
+
+type cacheEntry struct {
+	key cacheKey
+	err error
+}
+
+// NewResultCache returns a new ResultCache that holds at most size results.
+// If size is not greater than 0 nothing is cached.
+func NewResultCache(size int) *ResultCache {
+	return &ResultCache{size: size, lru: list.New(), items: make(map[cacheKey]*list.Element)}
+}
+
+// Len returns the number of the cached results.
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// get returns the cached result for the given key.
+func (c *ResultCache) get(key cacheKey) (err error, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).err, true
+	}
+	return nil, false
+}
+
+// add caches the result for the given key, evicting the least recently used
+// result if the cache is full.
+func (c *ResultCache) add(key cacheKey, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		e.Value.(*cacheEntry).err = err
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.lru.PushFront(&cacheEntry{key, err})
+	if c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.items, e.Value.(*cacheEntry).key)
+	}
+}
+
+// hashValues returns the hash of the contents of the two values. The ok
+// return value reports whether the values could be hashed.
+func hashValues(got, want interface{}) (key cacheKey, ok bool) {
+	hs := &hasher{h: fnv.New128a(), ptrs: make(map[ptrKey]uint64)}
+	if !hs.value(reflect.ValueOf(got)) || !hs.value(reflect.ValueOf(want)) {
+		return key, false
+	}
+	hs.h.Sum(key[:0])
+	return key, true
+}
+
+// hasher writes the contents of values to a hash. Each non-nil pointer is
+// written once, on its first visit, the subsequent visits write the ordinal of
+// the first one, this keeps both the cyclic values and the values that share
+// their parts with each other distinct from the values that merely have the
+// same contents.
+type hasher struct {
+	h    hash.Hash
+	ptrs map[ptrKey]uint64
+	buf  [8]byte
+}
+
+type ptrKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func (hs *hasher) uint(u uint64) {
+	binary.LittleEndian.PutUint64(hs.buf[:], u)
+	hs.h.Write(hs.buf[:])
+}
+
+func (hs *hasher) string(s string) {
+	hs.uint(uint64(len(s)))
+	hs.h.Write([]byte(s))
+}
+
+// value writes the type and the contents of v, it reports whether v could be
+// hashed, i.e. whether it contains no funcs, channels or unsafe pointers, and
+// no map keys that contain pointers.
+func (hs *hasher) value(v reflect.Value) bool {
+	if !v.IsValid() {
+		hs.uint(0)
+		return true
+	}
+	t := v.Type()
+	hs.string(t.PkgPath())
+	hs.string(t.String())
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			hs.uint(1)
+		} else {
+			hs.uint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		hs.uint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		hs.uint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		hs.uint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		hs.uint(math.Float64bits(real(c)))
+		hs.uint(math.Float64bits(imag(c)))
+	case reflect.String:
+		hs.string(v.String())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !hs.value(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			hs.uint(0)
+			return true
+		}
+		hs.uint(uint64(v.Len()) + 1)
+		if t.Elem().Kind() == reflect.Uint8 {
+			hs.h.Write(v.Bytes())
+			return true
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !hs.value(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hs.value(v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			hs.uint(0)
+			return true
+		}
+		hs.uint(1)
+		return hs.value(v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			hs.uint(0)
+			return true
+		}
+		ptr := ptrKey{v.Pointer(), t}
+		if n, ok := hs.ptrs[ptr]; ok {
+			hs.uint(n)
+			return true
+		}
+		hs.ptrs[ptr] = uint64(len(hs.ptrs)) + 1
+		hs.uint(uint64(len(hs.ptrs)))
+		return hs.value(v.Elem())
+	case reflect.Map:
+		return hs.mapValue(v)
+	default:
+		return false
+	}
+	return true
+}
+
+// mapValue writes the entries of the map v ordered by the hashes of their keys,
+// which therefore must not contain pointers, since the map lookups of pointer
+// keys depend on their addresses rather than their contents.
+func (hs *hasher) mapValue(v reflect.Value) bool {
+	if v.IsNil() {
+		hs.uint(0)
+		return true
+	}
+	hs.uint(uint64(v.Len()) + 1)
+
+	type entry struct {
+		sum cacheKey
+		val reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	for it := v.MapRange(); it.Next(); {
+		kh := &hasher{h: fnv.New128a(), ptrs: make(map[ptrKey]uint64)}
+		if !kh.value(it.Key()) || len(kh.ptrs) > 0 {
+			return false
+		}
+		e := entry{val: it.Value()}
+		kh.h.Sum(e.sum[:0])
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].sum, entries[j].sum
+		return string(a[:]) < string(b[:])
+	})
+	for _, e := range entries {
+		hs.h.Write(e.sum[:])
+		if !hs.value(e.val) {
+			return false
+		}
+	}
+	return true
+}
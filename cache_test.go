@@ -0,0 +1,129 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareResultCache(t *testing.T) {
+	type Item struct {
+		ID  int
+		Tag string
+	}
+	type Config struct {
+		Name  string
+		Items []*Item
+		Attrs map[string]int
+	}
+
+	var calls int
+	conf := DefaultConfig.With(WithComparer(reflect.TypeOf(Item{}), func(got, want interface{}) bool {
+		calls++
+		return got.(Item) == want.(Item)
+	}))
+	conf.ResultCache = NewResultCache(2)
+
+	newConfig := func(tag string) Config {
+		return Config{Name: "a", Items: []*Item{{1, "x"}, {2, tag}}, Attrs: map[string]int{"a": 1, "b": 2}}
+	}
+
+	if err := conf.Compare(newConfig("y"), newConfig("y")); err != nil {
+		t.Fatalf("Compare() got=%v, want=<nil>", err)
+	}
+	if calls != 2 {
+		t.Fatalf("Compare() called the comparer %d times, want 2", calls)
+	}
+
+	// the same contents at different addresses
+	calls = 0
+	if err := conf.Compare(newConfig("y"), newConfig("y")); err != nil || calls != 0 {
+		t.Errorf("cached Compare() got=%v with %d comparer calls, want=<nil> with 0 calls", err, calls)
+	}
+
+	want := "- (compare.Config).Items[1]: Value mismatch; got={2 y}, want={2 z}\n"
+	for i := 0; i < 2; i++ {
+		calls = 0
+		if got := Golden(conf.Compare(newConfig("y"), newConfig("z"))); got != want {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, want)
+		}
+		if wantCalls := 2 * (1 - i); calls != wantCalls {
+			t.Errorf("#%d: Compare() called the comparer %d times, want %d", i, calls, wantCalls)
+		}
+	}
+
+	// the least recently used result is evicted
+	conf.Compare(newConfig("v"), newConfig("w"))
+	if n := conf.ResultCache.Len(); n != 2 {
+		t.Errorf("Len() got=%d, want=2", n)
+	}
+	calls = 0
+	conf.Compare(newConfig("y"), newConfig("y"))
+	if calls != 2 {
+		t.Errorf("Compare() of an evicted result called the comparer %d times, want 2", calls)
+	}
+
+	// the values with funcs are not cached
+	conf.ResultCache = NewResultCache(2)
+	conf.Compare(func() {}, func() {})
+	if n := conf.ResultCache.Len(); n != 0 {
+		t.Errorf("Len() after comparing funcs got=%d, want=0", n)
+	}
+}
+
+func Test_hashValues(t *testing.T) {
+	type Node struct {
+		Val  int
+		Next *Node
+	}
+	cyclic := func() *Node {
+		n := &Node{Val: 1}
+		n.Next = &Node{Val: 2, Next: n}
+		return n
+	}
+	shared := &Node{Val: 3}
+
+	tests := []struct {
+		a, b [2]interface{}
+		same bool
+	}{{
+		a:    [2]interface{}{map[string]int{"a": 1, "b": 2, "c": 3}, 1},
+		b:    [2]interface{}{map[string]int{"c": 3, "b": 2, "a": 1}, 1},
+		same: true,
+	}, {
+		a:    [2]interface{}{[]int{1, 2}, []int{3}},
+		b:    [2]interface{}{[]int{1}, []int{2, 3}},
+		same: false,
+	}, {
+		a:    [2]interface{}{int32(1), nil},
+		b:    [2]interface{}{int64(1), nil},
+		same: false,
+	}, {
+		a:    [2]interface{}{[]byte(nil), ""},
+		b:    [2]interface{}{[]byte{}, ""},
+		same: false,
+	}, {
+		a:    [2]interface{}{cyclic(), cyclic()},
+		b:    [2]interface{}{cyclic(), cyclic()},
+		same: true,
+	}, {
+		a:    [2]interface{}{[]*Node{shared, shared}, nil},
+		b:    [2]interface{}{[]*Node{{Val: 3}, {Val: 3}}, nil},
+		same: false,
+	}}
+
+	for i, tt := range tests {
+		ka, oka := hashValues(tt.a[0], tt.a[1])
+		kb, okb := hashValues(tt.b[0], tt.b[1])
+		if !oka || !okb {
+			t.Errorf("#%d: hashValues() got ok=%t,%t, want true", i, oka, okb)
+		}
+		if same := ka == kb; same != tt.same {
+			t.Errorf("#%d: hashValues() got same=%t, want=%t", i, same, tt.same)
+		}
+	}
+
+	x := 1
+	if _, ok := hashValues(map[*int]int{&x: 1}, nil); ok {
+		t.Errorf("hashValues() of a map with pointer keys got ok=true, want false")
+	}
+}
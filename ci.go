@@ -0,0 +1,124 @@
+package compare
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJUnit is a wrapper around DefaultConfig.WriteJUnit.
+func WriteJUnit(w io.Writer, suite string, cases []Case) error {
+	return DefaultConfig.WriteJUnit(w, suite, cases)
+}
+
+// WriteJUnit compares the got and want values of each of the given cases and
+// writes the results to w as a JUnit XML test suite of the given name, so that
+// they can be consumed by the CI tools that understand the format. Each case
+// is reported as a testcase, the testcase of a failed case contains a failure
+// element with the plain text of its failures. The warnings, see WarnPaths, do
+// not fail the cases, they are written to the system-out element instead.
+func (conf Config) WriteJUnit(w io.Writer, suite string, cases []Case) error {
+	pr := conf.printer()
+	pr.nocolor = true
+
+	s := junitSuite{Name: suite, Tests: len(cases)}
+	for _, c := range cases {
+		tc := junitCase{Name: c.Name}
+		err := conf.Compare(c.Got, c.Want)
+		if f := Failures(err); f != nil {
+			n := len(Differences(f))
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d difference(s)", n),
+				Type:    "mismatch",
+				Text:    pr.error(f),
+			}
+			s.Failures++
+		}
+		if wr := Warnings(err); wr != nil {
+			tc.SystemOut = pr.error(wr)
+		}
+		s.Cases = append(s.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSuite is the testsuite element of a JUnit XML report.
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+// junitCase is the testcase element of a JUnit XML report.
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure is the failure element of a JUnit XML testcase.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteTAP is a wrapper around DefaultConfig.WriteTAP.
+func WriteTAP(w io.Writer, cases []Case) error {
+	return DefaultConfig.WriteTAP(w, cases)
+}
+
+// WriteTAP compares the got and want values of each of the given cases and
+// writes the results to w in the Test Anything Protocol format. Each case is
+// reported by an "ok" or a "not ok" test line, the latter is followed by the
+// plain text of the failures of the case as diagnostics, i.e. as lines that
+// start with "# ". The warnings, see WarnPaths, do not fail the cases, they
+// are written as diagnostics too, prefixed with "[warning]".
+func (conf Config) WriteTAP(w io.Writer, cases []Case) error {
+	pr := conf.printer()
+	pr.nocolor = true
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "TAP version 13\n1..%d\n", len(cases))
+	for i, c := range cases {
+		err := conf.Compare(c.Got, c.Want)
+		f := Failures(err)
+		status := "ok"
+		if f != nil {
+			status = "not ok"
+		}
+		fmt.Fprintf(bw, "%s %d - %s\n", status, i+1, tapEscape(c.Name))
+		for _, e := range []error{f, Warnings(err)} {
+			if e == nil {
+				continue
+			}
+			for _, line := range strings.Split(pr.error(e), "\n") {
+				fmt.Fprintf(bw, "# %s\n", line)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// tapEscape escapes the characters of the given description of a TAP test
+// line that would otherwise be interpreted as a directive or as the end of
+// the line.
+func tapEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "#", `\#`)
+	return strings.NewReplacer("\n", " ", "\r", " ").Replace(s)
+}
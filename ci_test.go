@@ -0,0 +1,59 @@
+package compare
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	type Stats struct {
+		Hits, Misses int
+	}
+	cases := []Case{
+		{Name: "equal", Got: 1, Want: 1},
+		{Name: "a<b", Got: "a", Want: "b"},
+		{Name: "warn", Got: Stats{1, 2}, Want: Stats{1, 3}},
+	}
+
+	var b strings.Builder
+	conf := Config{WarnPaths: []string{".Misses"}}
+	if err := conf.WriteJUnit(&b, "suite", cases); err != nil {
+		t.Fatalf("WriteJUnit() error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="suite" tests="3" failures="1">
+  <testcase name="equal"></testcase>
+  <testcase name="a&lt;b">
+    <failure message="1 difference(s)" type="mismatch">- (string): Value mismatch; got=&#34;a&#34;, want=&#34;b&#34;; differs at byte 0 (rune 0)</failure>
+  </testcase>
+  <testcase name="warn">
+    <system-out>[warning] - (compare.Stats).Misses: Value mismatch; got=2, want=3</system-out>
+  </testcase>
+</testsuite>
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteJUnit() got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteTAP(t *testing.T) {
+	cases := []Case{
+		{Name: "equal", Got: 1, Want: 1},
+		{Name: "case #2", Got: []int{1, 2}, Want: []int{1, 3, 4}},
+	}
+
+	var b strings.Builder
+	if err := WriteTAP(&b, cases); err != nil {
+		t.Fatalf("WriteTAP() error: %v", err)
+	}
+
+	want := "TAP version 13\n" +
+		"1..2\n" +
+		"ok 1 - equal\n" +
+		"not ok 2 - case \\#2\n" +
+		"# - ([]int): Length of slice mismatch; got=2, want=3\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteTAP() got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -3,13 +3,24 @@
 package compare
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 	"unsafe"
 )
 
-// Compare is a wrapper around DefaultConfig.Compare.
-func Compare(got, want interface{}) error {
-	return DefaultConfig.Compare(got, want)
+// Compare is a wrapper around DefaultConfig.Compare. The given options, if
+// any, are applied to a copy of the DefaultConfig, see Config.With.
+func Compare(got, want interface{}, opts ...Option) error {
+	return DefaultConfig.With(opts...).Compare(got, want)
 }
 
 // Config specifies the configuration for the value comparison.
@@ -17,9 +28,50 @@ type Config struct {
 	// If IgnoreArrayOrder is set, the order of elements inside arrays and
 	// slices is ignored. That is, two array/slice values are equal if they
 	// have the same number of elements and each element in one array value
-	// has an equivalent element in the other array value.
+	// has an equivalent element in the other array value. Each element of
+	// want with no equal element in got is compared to, and its differences
+	// are reported against, the closest of the got elements that are left
+	// unmatched, i.e. the one with the fewest differences.
 	IgnoreArrayOrder bool
 
+	// SliceKey maps the element types of arrays and slices, struct types or
+	// pointers to struct types, to the names of their key fields, e.g. "ID".
+	// If IgnoreArrayOrder is set, the elements of two arrays or slices of
+	// such an element type are paired up by the values of their key fields,
+	// and each pair is then compared as usual, so that the differences of
+	// the paired elements are reported. The elements that have no pair are
+	// reported as missing in got or as unexpected in got, and the lengths of
+	// the values, if they differ, are reported as well. The paths of the
+	// elements hold their indexes in want, and of the unexpected elements
+	// their indexes in got. The key fields must be of a comparable type.
+	SliceKey map[reflect.Type]string
+
+	// SortSlices maps the element types of arrays and slices to functions
+	// that report whether the element a sorts before the element b. The
+	// elements of two arrays or slices of such an element type are sorted,
+	// in copies of the values, before they are compared by their indexes,
+	// which makes the order of the elements irrelevant just as it is with
+	// IgnoreArrayOrder, however, the paths of the reported elements hold
+	// their indexes in the sorted values, so that the elements at the same
+	// index are compared to one another. The SortSlices take precedence over
+	// IgnoreArrayOrder and SliceKey. The values that cannot be retrieved as
+	// interface{} values are compared unsorted.
+	SortSlices map[reflect.Type]func(a, b interface{}) bool
+
+	// If IgnoreChanOrder is set, the order of the elements drained from
+	// channels is ignored. That is, two channel values are equal if they
+	// contain the same number of elements and each element drained from
+	// one channel has an equivalent element drained from the other channel.
+	IgnoreChanOrder bool
+
+	// If CompareChanIdentity is set, channels are compared by their identity
+	// rather than by the contents of their buffers. That is, two channel
+	// values are equal only if they are the same channel, or if both are nil,
+	// and the channels are never drained. In strict mode the channels are
+	// always compared by their identity. Regardless of the options, the
+	// unsafe.Pointer values are compared by the addresses they hold.
+	CompareChanIdentity bool
+
 	// The tag name to be checked by Compare for optional comparison rules.
 	// If ObserveFieldTag is set, its value will be used as the name of the
 	// tag to be checked, if it is empty then no tag will be checked.
@@ -31,7 +83,436 @@ type Config struct {
 	//      fields are zero or whether they are both non-zero.
 	// "omitempty": The omitempty option omits a field from comparison iff
 	//              the field of the "want" value is empty..
+	// "round=N": The round option rounds the values of a float field to N
+	//            decimal places before comparing them, e.g. "round=2" makes
+	//            1.004 and 0.996 equal. The option is ignored for fields of
+	//            other kinds.
+	// "warn": The warn option reports the differences of a field as warnings
+	//         rather than as failures, see WarnPaths.
 	ObserveFieldTag string
+
+	// IgnoreFieldNames is a list of struct field names, the fields with any
+	// of these names are omitted from comparison regardless of the struct
+	// type they belong to and of the depth at which the struct is found,
+	// e.g. []string{"CreatedAt", "UpdatedAt"}.
+	IgnoreFieldNames []string
+
+	// If IgnoreUnexported is set, the unexported fields of all structs are
+	// omitted from comparison, and IgnoreUnexportedIn lists the values of
+	// the struct types, or of the pointers to them, whose unexported fields
+	// are omitted from comparison, e.g. []interface{}{bytes.Buffer{}}. The
+	// structs are then compared by their exported fields only.
+	IgnoreUnexported   bool
+	IgnoreUnexportedIn []interface{}
+
+	// If PublicView is set, the structs are compared only by what can be
+	// observed through their public API, i.e. by their exported fields and
+	// by the results of their exported getter methods, the methods with no
+	// arguments and a single result. The methods are called with both the
+	// got and the want struct as receivers, so they must be free of side
+	// effects, and the methods with pointer receivers are called only if
+	// both of the structs are addressable, e.g. if they are pointed to.
+	// The unexported fields of all structs are omitted from comparison.
+	PublicView bool
+
+	// If FieldFilter is set, it is called for each of the struct fields of
+	// the want values, and the returned FieldRule determines whether the
+	// field is compared, omitted from comparison, or whether only the
+	// "zero-ness" of the fields is compared. It allows applying the rules of
+	// the ObserveFieldTag options to the fields of the types that cannot be
+	// tagged, e.g. those of third-party packages. The tag options are still
+	// observed for the fields that the FieldFilter rules to be compared.
+	FieldFilter FieldFilter
+
+	// If AuditSkippedFields is set, the struct fields that are omitted from
+	// comparison by IgnoreFieldNames, IgnoreUnexported, PublicView,
+	// IgnorePaths, FieldFilter, or by the "-" and "omitempty" options of the
+	// ObserveFieldTag are reported as differences, each with the rule that
+	// omitted it, so that the leniency of a test has to be explicitly
+	// acknowledged. The same goes for the struct fields of the types
+	// sync.Mutex, sync.RWMutex, sync.WaitGroup, and sync.Once, embedded or
+	// not, which are always omitted from comparison unless Strict is set,
+	// since their state is not part of the value of the struct that holds
+	// them. If SkippedFieldsAsWarnings is also set, the fields are
+	// reported as warnings rather than as failures, see WarnPaths.
+	AuditSkippedFields      bool
+	SkippedFieldsAsWarnings bool
+
+	// ZeroFuncs maps types to functions that report whether a value of
+	// the type is to be considered zero by the "+" and "omitempty" tag
+	// options. Values of types not present in the map, or values that
+	// cannot be retrieved as interface{} values, are considered zero if
+	// they are the zero value of their type.
+	ZeroFuncs map[reflect.Type]func(v interface{}) bool
+
+	// Comparers maps types to functions that report whether two values of
+	// the type are equal, the values of those types are compared by these
+	// functions instead of by their contents. Values that cannot be retrieved
+	// as interface{} values, i.e. those obtained from non-addressable
+	// unexported struct fields, are compared by their contents.
+	// See DecimalComparer for a ready-made comparer of decimal types.
+	Comparers map[reflect.Type]func(got, want interface{}) bool
+
+	// Projections maps interface types to functions that project the values
+	// held by the non-nil interface values of the type to the values that
+	// are compared in their place, e.g. an fmt.Stringer to the result of its
+	// String method, instead of the internals of their dynamic types. The
+	// projections apply only to the values whose static type is the mapped
+	// interface type, e.g. to struct fields and map or slice elements of
+	// that type. Note that a projection may be called more than once for
+	// the same value, e.g. when the order of the elements is ignored. See
+	// StringerProjection and ReaderProjection for ready-made projections.
+	Projections map[reflect.Type]func(v interface{}) interface{}
+
+	// If UseEqualMethod is set, the values of the types that have a method
+	// of the form "Equal(T) bool", declared either on the type T or on its
+	// pointer type, e.g. net.IP, are compared by that method instead of by
+	// their contents. The time.Time values are always compared by their
+	// Equal method, see CompareTimeLocation. The Comparers take precedence
+	// over the Equal methods, and the values that cannot be retrieved as
+	// interface{} values are compared by their contents.
+	UseEqualMethod bool
+
+	// FuncArgs maps func types to lists of sample arguments. If the func
+	// type of two non-nil func values is present in the map, the values are
+	// compared by calling both of them with each of the argument lists and
+	// comparing their results, instead of being reported as different. Each
+	// argument list must match the func's signature, a nil argument stands
+	// for the zero value of the parameter's type. The funcs are expected to
+	// be pure, they are called once per argument list, and if any of them
+	// panics the panic is not recovered. Func values that cannot be retrieved
+	// as interface{} values, i.e. those obtained from non-addressable unexported
+	// struct fields, are not called.
+	FuncArgs map[reflect.Type][][]interface{}
+
+	// IgnoredMapKeys maps map types to the keys whose entries are excluded
+	// from the comparison of the maps of those types, and IgnoredMapKeysAt
+	// maps paths to the keys whose entries are excluded from the comparison
+	// of the maps at those paths. See IgnoreMapKeys and IgnoreMapKeysAt.
+	IgnoredMapKeys   map[reflect.Type][]interface{}
+	IgnoredMapKeysAt map[string][]interface{}
+
+	// IgnorePaths is a list of paths, in the same syntax as that used by
+	// Difference.Path, at which the values are omitted from comparison,
+	// e.g. ".Publisher.HQ" or ".Authors[*].MiddleName". The leading dot
+	// may be left out, and the step "[*]" matches any index or key and
+	// the step ".*" any field, e.g. "Items[*].*.ID". The paths that cannot
+	// be parsed are ignored. Unlike IgnoreFieldNames, IgnorePaths can be
+	// used to exclude a field at one path while retaining it at another.
+	IgnorePaths []string
+
+	// PathRules is a list of rules that override some of the options for
+	// the comparison of the values at the paths of the rules, and of the
+	// values nested within them, e.g. a FloatTolerance that applies only
+	// to the ".Prices" of the compared values. See also LoadRules.
+	PathRules []PathRule
+
+	// If LooseNumericTypes is set, two values of different numeric types,
+	// i.e. of any of the integer and float kinds, are compared by their
+	// numeric value instead of being reported as a type mismatch. The values
+	// are compared exactly across the full range of their types, e.g. int64
+	// 9007199254740993 and float64 9007199254740992 are not equal even though
+	// the conversion of the former to float64 would make them equal, and
+	// negative ints are never equal to uints.
+	LooseNumericTypes bool
+
+	// If FloatTolerance or FloatRelTolerance is set, two float values are
+	// equal if the absolute difference between them is at most the
+	// FloatTolerance, or at most the FloatRelTolerance multiplied by the
+	// larger of their magnitudes. The errors of the floats that differ by
+	// more than the tolerance include their difference. NaN is not within
+	// any tolerance of any value, the fields marked with the "round" tag
+	// option are compared by their rounded values only.
+	FloatTolerance    float64
+	FloatRelTolerance float64
+
+	// If EquateNaNs is set, a NaN float is equal to any other NaN float of
+	// the same type, and so are the complex values whose parts are equal
+	// or both NaN. The entries of two maps that are keyed by NaNs, which
+	// cannot be looked up by their keys, are matched with one another, the
+	// entries with equal values taking precedence.
+	EquateNaNs bool
+
+	// If IgnorePointerDepth is set, two values whose types differ only in
+	// the number of pointer indirections, e.g. **T, *T, and T, are compared
+	// by the values they ultimately point to instead of being reported as
+	// a type mismatch. The paths of the errors found by such a comparison
+	// include a node that indicates the dereference, e.g. "{**got}" if got
+	// was dereferenced twice to match want.
+	IgnorePointerDepth bool
+
+	// If AutoDeref is set, a pointer of type *T and a value of type T are
+	// compared by the value the pointer points to instead of being reported
+	// as a type mismatch, e.g. a fixture written as a value can be compared
+	// to the pointer returned by the code under test. Only a single level of
+	// indirection is bridged, see IgnorePointerDepth for pointer chains.
+	AutoDeref bool
+
+	// If AnonymousWantStructs is set, a want value of an unnamed struct type,
+	// e.g. struct{ Name string }{"a"}, can be compared to a got struct of a
+	// different type, in which case only the fields of the want struct are
+	// compared, each to the field of the same name of the got struct. The
+	// fields missing from got are reported as field mismatches. The option
+	// applies also to the pointers to, and the slices, arrays, and maps of,
+	// such structs, which makes it possible to write the want values as
+	// inline literals that specify only the fields of interest.
+	AnonymousWantStructs bool
+
+	// If IterateContainers is set, two structs of the same type whose type
+	// is a container, i.e. one that provides an iterator of its elements, are
+	// compared by the elements of the iterator rather than by their fields,
+	// e.g. two ordered maps with the same entries are equal regardless of how
+	// the entries are stored. The iterator is provided either by an All
+	// method that returns an iter.Seq or an iter.Seq2 function, or by an
+	// Iterate method of the same signature as those functions. The elements
+	// are compared in the order in which they are yielded, the key-value
+	// pairs of an iter.Seq2 as structs with the Key and Value fields.
+	IterateContainers bool
+
+	// If ShapeOnly is set, the want value is interpreted as a specification
+	// of the shape of the got value rather than as a value, i.e. only the
+	// structure of got is validated. The values of the basic kinds match any
+	// value of the same type, and so do the zero values of the other kinds,
+	// e.g. a nil pointer in want matches any pointer of the same type. The
+	// non-nil pointers and interfaces of want require non-nil got values of
+	// the same dynamic type and shape, the non-nil maps of want require the
+	// got maps to have the same keys, and the first element of a non-empty
+	// slice of want specifies the shape of every element of the got slice,
+	// whose length is otherwise ignored. The struct fields are validated
+	// individually, the field tag options are observed.
+	ShapeOnly bool
+
+	// If CompareErrorChains is set, two values that both implement the
+	// error interface are compared by unwrapping their error chains and
+	// comparing the corresponding links of the chains by their type and
+	// their own message, i.e. without the messages of the errors they wrap,
+	// the error then reports the link at which the two chains diverge. The
+	// errors that wrap multiple errors, e.g. those returned by errors.Join,
+	// are followed by the chains of each of the wrapped errors in turn. The
+	// chains with nil pointer links are compared as normal values.
+	CompareErrorChains bool
+
+	// The time.Time values are compared by the instants they represent,
+	// i.e. using their Equal method. If CompareTimeLocation is set, the
+	// names of their locations must also match, and if CompareTimeMonotonic
+	// is set, so must their monotonic clock readings, or the lack thereof.
+	// The errors report which of the components of the times differ.
+	CompareTimeLocation  bool
+	CompareTimeMonotonic bool
+
+	// If TimeTolerance is greater than 0, two time.Time values are equal if
+	// the instants they represent are at most TimeTolerance apart, e.g. to
+	// accommodate the loss of precision when a time is stored in a database.
+	// If IgnoreTimeZone is set, the times are compared by their wall clock
+	// readings instead, as if they were both in UTC, that is, 10:00 in one
+	// location is equal to 10:00 in any other location, in which case the
+	// CompareTimeLocation option has no effect. When either of them is set,
+	// the errors report the duration between the times that differ.
+	TimeTolerance  time.Duration
+	IgnoreTimeZone bool
+
+	// If Strict is set, all of the leniencies provided by the other options
+	// as well as the special cases (i.e. the use of time.Time's Equal method,
+	// the draining of channels, and the omission of the struct fields of the
+	// sync package's lock types) are disabled and the result of Compare
+	// is guaranteed to be identical to that of reflect.DeepEqual, that is,
+	// Compare returns nil if and only if reflect.DeepEqual would return true.
+	Strict bool
+
+	// If MaxDiffsPerCollection is greater than 0, at most that many of
+	// the elements of a single array, slice, map, or channel value will
+	// be reported as different, the rest of the differing elements are
+	// summarized by a single error reporting their number.
+	MaxDiffsPerCollection int
+
+	// If MaxErrors is greater than 0, at most that many differences are
+	// reported by Compare, the rest of them are summarized by a single error
+	// reporting their number. If FailFast is set, the comparison stops as
+	// soon as MaxErrors differences, or, if MaxErrors is not set, the first
+	// difference, have been found, in which case the number of the remaining
+	// differences is not known and the summary reports only that the
+	// comparison was stopped.
+	MaxErrors int
+	FailFast  bool
+
+	// If MaxDepth is greater than 0, the values nested deeper than MaxDepth
+	// levels below the compared values, e.g. the fields of structs or the
+	// elements of slices, are not compared, instead an error is reported at
+	// each of the paths at which the limit was exceeded. It protects the
+	// comparison of very deep or self-referencing graphs from unbounded
+	// recursion and output. MaxDepth is ignored in Strict mode.
+	MaxDepth int
+
+	// If IterateMaps is set, maps are compared by pairing up the entries of
+	// both maps in the order of their keys, the order in which the keys are
+	// rendered, and in addition to the differing entries the entries that
+	// are missing in got and those that are unexpected in got are reported.
+	// Additionally, if MaxDiffsPerCollection is greater than 0, the comparison
+	// stops as soon as the limit is exceeded, in which case the number of the
+	// remaining differing entries is not reported.
+	IterateMaps bool
+
+	// If AlignElements is set, the elements of two arrays or slices of
+	// different lengths are aligned using the shortest edit script, see
+	// DiffSlices, and in addition to the length mismatch the elements that
+	// are missing in got and those that are unexpected in got are reported.
+	// The paths of the missing elements hold their indexes in want and the
+	// paths of the unexpected ones their indexes in got. The elements that
+	// occupy the same position in both values but differ are compared as
+	// usual. If IgnoreArrayOrder is set, the elements are instead matched
+	// regardless of their order, as they are for arrays and slices of the
+	// same length, and the elements left unmatched are reported as missing
+	// in got or as unexpected in got.
+	AlignElements bool
+
+	// If ReportMapKeys is set, the entries of two maps of different lengths
+	// are compared as well, that is, in addition to the length mismatch the
+	// keys that are missing in got, the keys that are missing in want, i.e.
+	// the keys unexpected in got, and the mismatching values of the shared
+	// keys are reported.
+	ReportMapKeys bool
+
+	// If AggregateCollectionErrors is set, multiple errors found inside
+	// a single array, slice, map, or channel value are folded into one
+	// multi-line error, i.e. the number of reported errors corresponds
+	// to the number of differing collections rather than the number of
+	// differing elements. Errors of nested collections are folded into
+	// the error of the outermost collection.
+	AggregateCollectionErrors bool
+
+	// If ASCII is set, the errors are guaranteed to be rendered as pure
+	// ASCII text, that is, they will contain no ANSI color codes and the
+	// non-ASCII and control characters of the rendered values will be
+	// escaped, e.g. as \u00e9 or \n.
+	ASCII bool
+
+	// If NoColor is set, the errors are rendered as plain text, i.e. with
+	// no ANSI color codes. The colors are also disabled, for the errors of
+	// every Config, if the standard output is not a terminal or if the
+	// NO_COLOR environment variable is set, unless the FORCE_COLOR
+	// environment variable is set, and always if the tests are run by
+	// "go test -json", see also StripColors.
+	NoColor bool
+
+	// If NoStringHighlight is set, the first difference of two strings is
+	// not highlighted within the rendered strings, which are then rendered
+	// in their plain colors, as are the other values, e.g. for the log
+	// processors that do not handle the nested ANSI color codes of the
+	// highlight.
+	NoStringHighlight bool
+
+	// If JSONPointerPaths is set, the paths of the errors are rendered as
+	// JSON Pointers (RFC 6901), e.g. "/Authors/0/FirstName", instead of
+	// the default Go-like syntax. The struct fields are represented by
+	// their Go names.
+	JSONPointerPaths bool
+
+	// If FloatBits is set, the floats rendered in the errors are followed
+	// by their IEEE 754 bit patterns in hex, e.g. "0.1 (0x3fb999999999999a)",
+	// which helps to tell apart values that look alike, e.g. NaNs with
+	// different payloads.
+	FloatBits bool
+
+	// If LineDiff is set, two strings of which at least one contains a
+	// newline, e.g. SQL queries or JSON documents, are reported with a
+	// line-based diff in the style of a unified diff, in which the lines
+	// missing in want are marked with "-", and those missing in got with "+",
+	// instead of being rendered in full with only their first difference
+	// highlighted.
+	LineDiff bool
+
+	// If StringContext is greater than 0, two single-line strings that differ
+	// are rendered trimmed to the window around their first difference, with
+	// at most StringContext bytes shown on either side of the difference and
+	// with the trimmed parts marked by "…", instead of being rendered in full.
+	StringContext int
+
+	// If HexDump is set, two byte slices that differ are reported with a
+	// single error that contains the hex dump of the rows of the bytes
+	// around their first difference, instead of with an error for each of
+	// their differing bytes. If BytesAsString is set, two byte slices that
+	// differ and that are both valid UTF-8 are reported as strings would be,
+	// the other byte slices are then reported according to HexDump.
+	// Neither of the options has an effect on the slices compared with
+	// IgnoreArrayOrder.
+	HexDump       bool
+	BytesAsString bool
+
+	// Formatters maps types to functions that render the values of the type
+	// in the errors, e.g. to render byte slices as base64 or enums by their
+	// names. The formatters affect only the rendering of the values, not
+	// the result of the comparison. Values that cannot be retrieved as
+	// interface{} values, i.e. those obtained from non-addressable unexported
+	// struct fields, are rendered using the default format.
+	Formatters map[reflect.Type]func(v interface{}) string
+
+	// If BreadthFirst is set, the errors are ordered breadth-first, i.e. by
+	// the depth of their paths, instead of in the depth-first order in which
+	// they were found, so that the mismatches nearest to the root values are
+	// reported first. Errors of the same depth retain their relative order.
+	BreadthFirst bool
+
+	// If Verbose is set, the errors include additional information that
+	// helps to assess the extent of the differences. Currently the errors of
+	// the fields of a struct are preceded by an error summarizing how many
+	// of the struct's compared fields differ, e.g. "2/5 fields differ".
+	Verbose bool
+
+	// If ShowAddresses is set, the pointers, maps, slices, and channels
+	// rendered in the errors are followed by their addresses, e.g.
+	// "(0xc000010000)", which helps to diagnose unexpected sharing of
+	// memory between the got and want values. Note that two pointers,
+	// maps, or slices that share the same address are considered equal
+	// without their contents being compared.
+	ShowAddresses bool
+
+	// If SampleMinLen is greater than 0, the arrays, slices, and maps with at
+	// least that many elements are compared by sampling their elements rather
+	// than by comparing all of them. If SampleEvery is greater than 0, every
+	// SampleEvery'th element is compared, otherwise each element is compared
+	// with the probability SampleRate, which must be between 0 and 1. The
+	// errors of the sampled elements are followed by a summary reporting the
+	// estimated mismatch rate of the collection. Note that the differences of
+	// the elements that were not sampled go unnoticed. The sampling does not
+	// apply to the comparisons that ignore the order of the elements.
+	SampleMinLen int
+	SampleEvery  int
+	SampleRate   float64
+
+	// If Seed is not 0, the randomized behavior of the comparison, i.e. the
+	// selection of the elements by SampleRate, is driven by a source of
+	// random numbers seeded with it, and the entries of maps are compared,
+	// and their errors reported, in the order of their keys rather than in
+	// the random order of iteration, even if IterateMaps is set. The reports
+	// of comparisons with the same Seed are therefore reproducible.
+	Seed int64
+
+	// WarnPaths is a list of paths, in the same syntax as that used by
+	// Difference.Path, e.g. ".Stats" or ".Items[0].UpdatedAt", at which,
+	// or below which, the differences found by Compare are to be reported
+	// as warnings rather than as failures. The warnings are included in the
+	// error returned by Compare, use the Failures and Warnings functions to
+	// tell the two apart. The test helpers, e.g. CompareAllT, fail only on
+	// failures and log the warnings. The paths may contain the wildcards
+	// described by IgnorePaths.
+	WarnPaths []string
+
+	// If Coverage is set, Compare writes to it the list of the paths that
+	// were compared together with their status, one line per path, e.g.
+	// "match\t.Authors[0].FirstName" or "mismatch\t.Title", which allows
+	// tracking which parts of the compared values are actually asserted.
+	// The paths omitted from comparison, e.g. by the "-" tag option, are not
+	// listed. Errors returned by the writer are ignored.
+	Coverage io.Writer
+
+	// If ResultCache is set, the results of Compare are cached, keyed by a
+	// hash of the contents of the got and want values, and the comparisons
+	// of values whose contents were already compared return the cached
+	// result, see NewResultCache. The values that cannot be hashed, e.g.
+	// those that contain funcs or channels, are always compared, as are all
+	// values if Coverage or ShowAddresses is set. A cache must not be shared
+	// by Configs with different options.
+	ResultCache *ResultCache
 }
 
 // DefaultConfig is the default Config used by Compare.
@@ -41,17 +522,78 @@ var DefaultConfig Config
 // and pointers that have already been compared.
 type comparison struct {
 	errs   *errorList
-	visits map[visit]bool // track pointers already compared
+	visits map[visit]struct{} // track pointers already compared
 	zero   bool
+	// set while comparing the contents of an aggregated collection
+	aggregate bool
+	// set if the compared paths are to be recorded, see Config.Coverage
+	cover *coverage
+	// the parsed IgnorePaths
+	ignore [][]pathStep
+	// the parsed PathRules
+	rules []parsedRule
+	// the parsed WarnPaths
+	warn [][]pathStep
+	// the parsed IgnoredMapKeysAt
+	keysAt []parsedKeysAt
+	// the source of random numbers seeded with Config.Seed, or nil
+	rand *rand.Rand
+	// the values being compared, used to detect the cycles that differ
+	ancestors ancestors
+	// the func called for each of the visited values, see Walk,
+	// and whether the walk was stopped by it
+	walk   WalkFunc
+	halted bool
+	// the number of errors at which the comparison stops, see FailFast,
+	// and whether it was stopped
+	limit   int
+	stopped bool
 }
 
+// comparisonPool holds the comparison states, and most importantly their
+// visits maps, for reuse by subsequent comparisons.
+var comparisonPool = sync.Pool{New: func() interface{} {
+	return &comparison{visits: make(map[visit]struct{})}
+}}
+
+// maxPooledVisits is the maximum number of visits a map can hold for it to
+// be returned to the pool, larger maps are left to the garbage collector.
+const maxPooledVisits = 1 << 10
+
+// newComparison returns a comparison state from the pool. The state should
+// be returned to the pool with release once the comparison is done.
 func newComparison() *comparison {
-	cmp := new(comparison)
+	cmp := comparisonPool.Get().(*comparison)
 	cmp.errs = new(errorList)
-	cmp.visits = make(map[visit]bool)
 	return cmp
 }
 
+// sub returns a comparison state from the pool for a comparison nested in
+// the one of cmp, it shares the parsed paths of cmp. The cmp may be nil.
+func (cmp *comparison) sub() *comparison {
+	sub := newComparison()
+	if cmp != nil {
+		sub.ignore, sub.rules, sub.warn = cmp.ignore, cmp.rules, cmp.warn
+		sub.keysAt = cmp.keysAt
+	}
+	return sub
+}
+
+// release resets the comparison state and returns it to the pool.
+func (cmp *comparison) release() {
+	visits := cmp.visits
+	if len(visits) > maxPooledVisits {
+		return
+	}
+	clear(visits)
+	*cmp = comparison{visits: visits}
+	comparisonPool.Put(cmp)
+}
+
+// visit is the key of the visits map. The addresses are stored as pointers
+// so that they keep the visited values alive, the comparison creates
+// temporary values, e.g. the sorted copies of SortSlices, whose addresses
+// could otherwise be reused for other values once they are collected.
 type visit struct {
 	got  unsafe.Pointer
 	want unsafe.Pointer
@@ -64,31 +606,346 @@ type visit struct {
 // The comparison algorithm is a copy of the one used by reflect.DeepEqual only
 // split into multiple small functions.
 func (conf Config) Compare(got, want interface{}) error {
-	gotv := reflect.ValueOf(got)
-	wantv := reflect.ValueOf(want)
+	if c := conf.ResultCache; c != nil && conf.Coverage == nil && !conf.ShowAddresses {
+		if key, ok := hashValues(got, want); ok {
+			if err, ok := c.get(key); ok {
+				return err
+			}
+			conf.ResultCache = nil
+			err := conf.Compare(got, want)
+			c.add(key, err)
+			return err
+		}
+	}
+	if conf.Strict {
+		conf = conf.strict()
+	}
+
+	return conf.compareRoot(got, want, nil)
+}
 
+// compareRoot compares the two given values at the root path, calling the
+// visit func, if not nil, for each of the pairs of values visited by the
+// comparison, see Walk, and returns the error reporting their differences.
+func (conf Config) compareRoot(got, want interface{}, visit WalkFunc) error {
 	p := path{rootnode{reflect.TypeOf(want)}}
 	cmp := newComparison()
-	conf.compare(gotv, wantv, cmp, p)
+	defer cmp.release()
+	cmp.walk = visit
+	if conf.Coverage != nil {
+		cmp.cover = new(coverage)
+	}
+	conf.run(got, want, cmp, p)
+	if cmp.cover != nil {
+		cmp.cover.write(conf.Coverage)
+	}
+	if conf.BreadthFirst {
+		sort.SliceStable(cmp.errs.List, func(i, j int) bool {
+			return len(errorPath(cmp.errs.List[i])) < len(errorPath(cmp.errs.List[j]))
+		})
+	}
+	if conf.MaxErrors > 0 || conf.FailFast {
+		cmp.limitErrors(max(conf.MaxErrors, 1), p)
+	}
 	return cmp.errs.err()
 }
 
+// run initializes the comparison state according to the Config and compares
+// the two given values at the root path p.
+func (conf Config) run(got, want interface{}, cmp *comparison, p path) {
+	cmp.errs.pr = conf.printer()
+	if len(conf.IgnorePaths) > 0 {
+		cmp.ignore = parsePaths(conf.IgnorePaths)
+	}
+	if len(conf.PathRules) > 0 {
+		cmp.rules = conf.parsePathRules()
+	}
+	if len(conf.WarnPaths) > 0 {
+		cmp.warn = conf.parseWarnPaths()
+	}
+	if len(conf.IgnoredMapKeysAt) > 0 {
+		cmp.keysAt = conf.parseIgnoredMapKeysAt()
+	}
+	if conf.Seed != 0 {
+		cmp.rand = rand.New(rand.NewSource(conf.Seed))
+	}
+	if conf.FailFast {
+		cmp.limit = max(conf.MaxErrors, 1)
+	}
+	if m, ok := want.(Matcher); ok && !conf.Strict {
+		conf.compareMatch(m, reflect.ValueOf(got), cmp, p)
+	} else {
+		conf.compare(reflect.ValueOf(got), reflect.ValueOf(want), cmp, p)
+	}
+}
+
+// Reason is a wrapper around DefaultConfig.Reason.
+func Reason(got, want interface{}) (equal bool, reason string) {
+	return DefaultConfig.Reason(got, want)
+}
+
+// Reason compares the two given values and reports whether they are equal and,
+// if they are not, the reason why. The reason is a single line of text with no
+// ANSI color codes, the differences are separated by "; " and the newlines and
+// other control characters of the values are escaped. It is intended for use
+// in fuzz targets and property tests.
+func (conf Config) Reason(got, want interface{}) (equal bool, reason string) {
+	err := conf.Compare(got, want)
+	if err == nil {
+		return true, ""
+	}
+
+	pr := conf.printer()
+	pr.nocolor, pr.oneline = true, true
+	return false, pr.error(err)
+}
+
+// Golden returns the canonical textual representation of the given error,
+// which is expected to be an error returned by Compare, intended to be used
+// as the content of golden files. The representation is deterministic, it
+// contains no ANSI color codes and no addresses, its errors, including those
+// of aggregated collections, are sorted by their text, each of its lines is
+// terminated by a newline, and the floats and times are always rendered in
+// the same format. If err is nil the result is empty.
+func Golden(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	pr := printer{golden: true}
+	if el, ok := err.(*errorList); ok {
+		pr.ascii, pr.pointer, pr.formatters = el.pr.ascii, el.pr.pointer, el.pr.formatters
+	}
+	return pr.error(err) + "\n"
+}
+
+// strict returns a copy of the Config with all of the leniencies disabled,
+// the options that affect only the reporting of the errors are retained.
+func (conf Config) strict() Config {
+	return Config{
+		Strict:                true,
+		MaxDiffsPerCollection: conf.MaxDiffsPerCollection,
+		MaxErrors:             conf.MaxErrors,
+		FailFast:              conf.FailFast,
+		IterateMaps:           conf.IterateMaps,
+		AlignElements:         conf.AlignElements,
+		ReportMapKeys:         conf.ReportMapKeys,
+
+		AggregateCollectionErrors: conf.AggregateCollectionErrors,
+		ASCII:                     conf.ASCII,
+		NoColor:                   conf.NoColor,
+		NoStringHighlight:         conf.NoStringHighlight,
+		JSONPointerPaths:          conf.JSONPointerPaths,
+		FloatBits:                 conf.FloatBits,
+		LineDiff:                  conf.LineDiff,
+		StringContext:             conf.StringContext,
+		HexDump:                   conf.HexDump,
+		BytesAsString:             conf.BytesAsString,
+		ShowAddresses:             conf.ShowAddresses,
+		BreadthFirst:              conf.BreadthFirst,
+		Verbose:                   conf.Verbose,
+		Formatters:                conf.Formatters,
+		Coverage:                  conf.Coverage,
+		WarnPaths:                 conf.WarnPaths,
+		Seed:                      conf.Seed,
+	}
+}
+
 func (conf Config) compare(got, want reflect.Value, cmp *comparison, p path) {
+	if cmp.halted {
+		return
+	}
+	if cmp.limit > 0 && len(cmp.errs.List) >= cmp.limit {
+		cmp.stopped = true
+		return
+	}
+	if cmp.ignore != nil && matchAnyPath(cmp.ignore, p) {
+		conf.skipField("IgnorePaths", cmp, p)
+		return
+	}
+	if cmp.rules != nil {
+		conf = cmp.applyPathRules(conf, p)
+	}
+	if cmp.cover != nil {
+		i, mark := cmp.enterCover(p)
+		defer cmp.exitCover(i, mark)
+	}
+	if cmp.warn != nil && cmp.isWarnPath(p) {
+		defer cmp.markWarnings(len(cmp.errs.List))
+	}
+	if conf.MaxDepth > 0 && p.depth() > conf.MaxDepth {
+		cmp.errs.add(&depthError{conf.MaxDepth, p})
+		return
+	}
+	if m, ok := matcherOf(want); ok && !conf.Strict {
+		conf.compareMatch(m, got, cmp, p)
+		return
+	}
 	if ok := conf.compareValidity(got, want, cmp, p); !ok {
 		return
 	}
+	if conf.CompareErrorChains && isError(got) && isError(want) {
+		if conf.compareErrorChain(got, want, cmp, p) {
+			return
+		}
+	}
+	if conf.LooseNumericTypes && got.Type() != want.Type() && isNumber(got.Kind()) && isNumber(want.Kind()) {
+		if !numbersEqual(got, want) {
+			cmp.errs.add(&valueError{got, want, p})
+		}
+		return
+	}
+	if (conf.IgnorePointerDepth || conf.AutoDeref) && got.Type() != want.Type() {
+		if ok := conf.comparePointerDepths(got, want, cmp, p); ok {
+			return
+		}
+	}
+	if conf.AnonymousWantStructs && got.Type() != want.Type() && isAnonymousWant(got.Type(), want.Type()) {
+		if want.Kind() == reflect.Struct {
+			conf.compareAnonymousStruct(got, want, cmp, p)
+		} else {
+			conf.compareKind(got, want, cmp, p)
+		}
+		return
+	}
 	if ok := conf.compareType(got, want, cmp, p); !ok {
 		return
 	}
-	if ok := conf.checkVisited(got, want, cmp, p); !ok {
+	ok, entered := conf.checkVisited(got, want, cmp, p)
+	if !ok {
 		return
 	}
+	if entered {
+		defer cmp.exitCycle(got, want)
+	}
+	if cmp.walk != nil {
+		if ok := cmp.visitWalk(got, want, p); !ok {
+			return
+		}
+	}
 
 	if cmp.zero {
 		conf.compareZero(got, want, cmp, p)
 		return
 	}
+	if conf.ShapeOnly {
+		conf.compareShape(got, want, cmp, p)
+		return
+	}
+	if fn, ok := conf.Comparers[got.Type()]; ok {
+		if ok := conf.compareCustom(fn, got, want, cmp, p); ok {
+			return
+		}
+	}
+	if conf.UseEqualMethod && !conf.Strict {
+		if ok := conf.compareEqualMethod(got, want, cmp, p); ok {
+			return
+		}
+	}
+
+	if conf.IterateContainers && got.Kind() == reflect.Struct {
+		if ok := conf.compareContainer(got, want, cmp, p); ok {
+			return
+		}
+	}
+
+	if conf.AggregateCollectionErrors && !cmp.aggregate && isCollection(got.Kind()) {
+		conf.compareAggregate(got, want, cmp, p)
+		return
+	}
+	conf.compareKind(got, want, cmp, p)
+}
+
+// compareEqualMethod compares the two values using the Equal method of their
+// type, see UseEqualMethod. The ok return value reports whether the method
+// could be used.
+func (conf Config) compareEqualMethod(got, want reflect.Value, cmp *comparison, p path) (ok bool) {
+	typ := got.Type()
+	if typ.Kind() == reflect.Interface || structIsTime(got) {
+		return false
+	}
+	m, ok := equalMethod(typ)
+	if !ok {
+		return false
+	}
+	if typ.Kind() == reflect.Ptr && (got.IsNil() || want.IsNil()) {
+		// the method is not called with nil pointers, these are
+		// compared as usual, i.e. they are equal only to nil
+		return false
+	}
+	gotx, ok := interfaceOf(got)
+	if !ok {
+		return false
+	}
+	wantx, ok := interfaceOf(want)
+	if !ok {
+		return false
+	}
+
+	recv := reflect.ValueOf(gotx)
+	if m.Type.In(0) != typ {
+		// the method is declared on the pointer type
+		ptr := reflect.New(typ)
+		ptr.Elem().Set(recv)
+		recv = ptr
+	}
+	if !m.Func.Call([]reflect.Value{recv, reflect.ValueOf(wantx)})[0].Bool() {
+		cmp.errs.add(&valueError{got, want, p})
+	}
+	return true
+}
+
+// equalMethod returns the "Equal(T) bool" method of the type T, or of its
+// pointer type.
+func equalMethod(typ reflect.Type) (reflect.Method, bool) {
+	for _, t := range []reflect.Type{typ, reflect.PointerTo(typ)} {
+		m, ok := t.MethodByName("Equal")
+		if !ok {
+			continue
+		}
+		if ft := m.Type; ft.NumIn() == 2 && ft.In(1) == typ && ft.NumOut() == 1 && ft.Out(0).Kind() == reflect.Bool {
+			return m, true
+		}
+	}
+	return reflect.Method{}, false
+}
+
+// limitErrors drops the errors beyond the first n and adds an error summarizing
+// them, see MaxErrors. If the comparison was stopped the summary is added
+// regardless of the number of the errors.
+func (cmp *comparison) limitErrors(n int, p path) {
+	list := cmp.errs.List
+	switch {
+	case cmp.stopped:
+		if len(list) > n {
+			list = list[:n]
+		}
+		cmp.errs.List = append(list, &limitError{-1, n, p})
+	case len(list) > n:
+		cmp.errs.List = append(list[:n], &limitError{len(list) - n, n, p})
+	}
+}
+
+// compareCustom compares the two values using the given comparer function.
+// The ok return value reports whether the comparer could be used.
+func (conf Config) compareCustom(fn func(got, want interface{}) bool, got, want reflect.Value, cmp *comparison, p path) (ok bool) {
+	gotx, ok := interfaceOf(got)
+	if !ok {
+		return false
+	}
+	wantx, ok := interfaceOf(want)
+	if !ok {
+		return false
+	}
+	if !fn(gotx, wantx) {
+		cmp.errs.add(&valueError{gotx, wantx, p})
+	}
+	return true
+}
 
+// compareKind compares the two values, which must be of the same type, based
+// on their kind.
+func (conf Config) compareKind(got, want reflect.Value, cmp *comparison, p path) {
 	switch got.Kind() {
 	case reflect.Array:
 		conf.compareArray(got, want, cmp, p)
@@ -106,18 +963,59 @@ func (conf Config) compare(got, want reflect.Value, cmp *comparison, p path) {
 		conf.compareFunc(got, want, cmp, p)
 	case reflect.String:
 		conf.compareString(got, want, cmp, p)
+	case reflect.Float32, reflect.Float64:
+		conf.compareFloat(got, want, cmp, p)
+	case reflect.Complex64, reflect.Complex128:
+		conf.compareComplex(got, want, cmp, p)
 	case reflect.Chan:
 		conf.compareChan(got, want, cmp, p)
+	case reflect.UnsafePointer:
+		if got.Pointer() != want.Pointer() {
+			cmp.errs.add(&identityError{got, want, p})
+		}
 	default:
 		conf.compareInterfaceValue(got, want, cmp, p)
 	}
 }
 
-func (conf Config) equals(got, want reflect.Value) bool {
-	p := make(path, 0)
-	cmp := newComparison()
-	conf.compare(got, want, cmp, p)
-	return len(cmp.errs.List) == 0
+// compareAggregate compares the two collection values and folds the errors
+// found inside them into a single collectionError.
+func (conf Config) compareAggregate(got, want reflect.Value, cmp *comparison, p path) {
+	cmp.aggregate = true
+	mark := len(cmp.errs.List)
+	conf.compareKind(got, want, cmp, p)
+	cmp.aggregate = false
+
+	if errs := cmp.errs.List[mark:]; len(errs) > 1 {
+		err := &collectionError{append([]error(nil), errs...), want.Kind(), p}
+		cmp.errs.List = append(cmp.errs.List[:mark], err)
+	}
+}
+
+// equals reports whether the two values at the path p are equal. The values
+// are compared in a comparison of their own which observes the IgnorePaths,
+// PathRules and WarnPaths parsed by the enclosing comparison cmp, if any.
+func (conf Config) equals(got, want reflect.Value, cmp *comparison, p path) bool {
+	sub := cmp.sub()
+	defer sub.release()
+	conf.subConfig().compare(got, want, sub, p)
+	return len(sub.errs.List) == 0
+}
+
+// distance returns the number of the differences between the two values at
+// the path p, see equals.
+func (conf Config) distance(got, want reflect.Value, cmp *comparison, p path) int {
+	sub := cmp.sub()
+	defer sub.release()
+	conf.subConfig().compare(got, want, sub, p)
+	return len(sub.errs.differences())
+}
+
+// subConfig returns the configuration of the comparisons of equals and
+// distance, the skipped fields are not audited since they are no differences.
+func (conf Config) subConfig() Config {
+	conf.AuditSkippedFields = false
+	return conf
 }
 
 // compareValidity compares the validity of the two values. The ok return value
@@ -125,7 +1023,7 @@ func (conf Config) equals(got, want reflect.Value) bool {
 // comparison of the two values can continue.
 func (conf Config) compareValidity(got, want reflect.Value, cmp *comparison, p path) (ok bool) {
 	if got.IsValid() != want.IsValid() {
-		cmp.errs.add(&validityError{got, want, p})
+		cmp.errs.add(&validityError{got: got, want: want, path: p})
 	}
 	return got.IsValid() && want.IsValid()
 }
@@ -141,6 +1039,14 @@ func (conf Config) compareType(got, want reflect.Value, cmp *comparison, p path)
 	return true
 }
 
+func isCollection(k reflect.Kind) bool {
+	switch k {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Chan:
+		return true
+	}
+	return false
+}
+
 func (conf Config) hard(k reflect.Kind) bool {
 	switch k {
 	case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
@@ -151,84 +1057,210 @@ func (conf Config) hard(k reflect.Kind) bool {
 
 // checkVisited checks whether the values, if they are addressable, have already
 // been visited and if they haven't records a new visit into the visits map. The
-// ok return value reports whether the comparison needs to continue or not.
-func (conf Config) checkVisited(got, want reflect.Value, cmp *comparison, p path) (ok bool) {
+// ok return value reports whether the comparison needs to continue or not. Unless
+// Strict is set, the values are also checked for whether they close a cycle in
+// only one of the compared graphs of values, see enterCycle, in which case the
+// entered return value reports whether they have to be exited once compared.
+func (conf Config) checkVisited(got, want reflect.Value, cmp *comparison, p path) (ok, entered bool) {
 	if got.CanAddr() && want.CanAddr() && conf.hard(got.Kind()) {
+		if !conf.Strict {
+			if ok, entered = cmp.enterCycle(got, want, p); !ok {
+				return false, false
+			}
+		}
+
 		gotAddr := unsafe.Pointer(got.UnsafeAddr())
 		wantAddr := unsafe.Pointer(want.UnsafeAddr())
 		if uintptr(gotAddr) > uintptr(wantAddr) {
 			gotAddr, wantAddr = wantAddr, gotAddr
 		}
 
-		typ := got.Type()
-		v := visit{gotAddr, wantAddr, typ}
-		if cmp.visits[v] {
-			return false
+		v := visit{gotAddr, wantAddr, got.Type()}
+		if _, ok := cmp.visits[v]; ok {
+			if entered {
+				cmp.exitCycle(got, want)
+			}
+			return false, false
 		}
-		cmp.visits[v] = true
+		cmp.visits[v] = struct{}{}
 	}
-	return true
+	return true, entered
 }
 
 // compareSlice compares the address, length and contents of the two slice values.
 func (conf Config) compareSlice(got, want reflect.Value, cmp *comparison, p path) {
-	if got.Pointer() == want.Pointer() {
+	if got.Pointer() == want.Pointer() && got.Len() == want.Len() {
 		return
 	}
 	if got.IsNil() != want.IsNil() {
 		cmp.errs.add(&nilError{got, want, p})
 		return
 	}
+	if (conf.HexDump || conf.BytesAsString) && !conf.IgnoreArrayOrder && got.Type().Elem().Kind() == reflect.Uint8 {
+		if conf.compareBytes(got, want, cmp, p) {
+			return
+		}
+	}
 	conf.compareArray(got, want, cmp, p)
 }
 
 // compareArray compares the length and contents of the two array values.
 func (conf Config) compareArray(got, want reflect.Value, cmp *comparison, p path) {
+	sorted := false
+	if conf.SortSlices != nil {
+		got, want, sorted = conf.sortElems(got, want)
+	}
+	if conf.IgnoreArrayOrder && !sorted && conf.SliceKey != nil {
+		if index, ok := conf.sliceKeyField(got.Type()); ok {
+			conf.compareArrayByKey(index, got, want, cmp, p)
+			return
+		}
+	}
 	if got.Len() != want.Len() {
 		cmp.errs.add(&lenError{got, want, p})
-		// TODO(mkopriva): might be good to compare the contents and
-		// point out the "missing" or the "extra" elements...
+		if conf.AlignElements && conf.IgnoreArrayOrder && !sorted {
+			conf.compareArrayIgnoreOrder(got, want, cmp, p, newArrnode)
+		} else if conf.AlignElements {
+			conf.compareArrayAligned(got, want, cmp, p)
+		}
+		return
+	}
+	if conf.equalPrimitiveElems(got, want, cmp) {
 		return
 	}
 
-	if conf.IgnoreArrayOrder {
-		conf.compareArrayIgnoreOrder(got, want, cmp, p)
+	if conf.IgnoreArrayOrder && !sorted {
+		conf.compareArrayIgnoreOrder(got, want, cmp, p, newArrnode)
 		return
 	}
 
+	diffs := conf.newElemDiffs(cmp)
+	sample := conf.newSampler(cmp, want.Len())
 	for i := 0; i < want.Len(); i++ {
+		if sample != nil && !sample.next() {
+			continue
+		}
 		q := p.add(arrnode{i})
 		ithGot := got.Index(i)
 		ithWant := want.Index(i)
+		mark := diffs.mark()
 		conf.compare(ithGot, ithWant, cmp, q)
+		if sample != nil && diffs.mark() > mark {
+			sample.differ++
+		}
+		diffs.check(mark)
+	}
+	diffs.done(want.Kind(), p)
+	if sample != nil {
+		sample.done(cmp, want.Len(), want.Kind(), p)
 	}
 }
 
-func (conf Config) compareArrayIgnoreOrder(got, want reflect.Value, cmp *comparison, p path) {
-	gotidx := make([]int, got.Len())
-	for i := range gotidx {
-		gotidx[i] = i
+// compareArrayAligned aligns the elements of the two array values using the
+// shortest edit script and reports the elements that are missing in got and
+// those that are unexpected in got. Within each run of edits the deleted and
+// the inserted elements are paired up in order and compared to one another.
+func (conf Config) compareArrayAligned(got, want reflect.Value, cmp *comparison, p path) {
+	edits := diffEdits(got.Len(), want.Len(), func(i, j int) bool {
+		return conf.equals(got.Index(i), want.Index(j), cmp, p.add(arrnode{j}))
+	})
+
+	diffs := conf.newElemDiffs(cmp)
+	for len(edits) > 0 {
+		if edits[0].Kind == KeepEdit {
+			edits = edits[1:]
+			continue
+		}
+
+		var dels, ins []int
+		for len(edits) > 0 && edits[0].Kind != KeepEdit {
+			if e := edits[0]; e.Kind == DeleteEdit {
+				dels = append(dels, e.A)
+			} else {
+				ins = append(ins, e.B)
+			}
+			edits = edits[1:]
+		}
+		for len(dels) > 0 || len(ins) > 0 {
+			mark := diffs.mark()
+			switch {
+			case len(dels) > 0 && len(ins) > 0:
+				i, j := dels[0], ins[0]
+				conf.compare(got.Index(i), want.Index(j), cmp, p.add(arrnode{j}))
+				dels, ins = dels[1:], ins[1:]
+			case len(dels) > 0:
+				i := dels[0]
+				cmp.errs.add(&elemError{got: got.Index(i), path: p.add(arrnode{i})})
+				dels = dels[1:]
+			default:
+				j := ins[0]
+				cmp.errs.add(&elemError{want: want.Index(j), path: p.add(arrnode{j})})
+				ins = ins[1:]
+			}
+			diffs.check(mark)
+		}
 	}
+	diffs.done(want.Kind(), p)
+}
 
+// compareArrayIgnoreOrder compares the contents of the two array values ignoring
+// the order of their elements. The node func is used to construct the path nodes
+// of the elements. The want elements with no equal got element are each compared
+// to the closest of the unmatched got elements, and the elements that are left
+// without a counterpart are reported as missing or unexpected.
+func (conf Config) compareArrayIgnoreOrder(got, want reflect.Value, cmp *comparison, p path, node func(i int) pathnode) {
+	matched := make([]bool, got.Len())
+	var unmatched []int // the indexes of the want elements with no equal got element
 	for i := 0; i < want.Len(); i++ {
-		q := p.add(arrnode{i})
 		ithWant := want.Index(i)
 
 		var foundEqual bool
-		for i, j := range gotidx {
-			ithGot := got.Index(j)
-			if conf.equals(ithGot, ithWant) {
-				gotidx = append(gotidx[:i], gotidx[i+1:]...)
-				foundEqual = true
+		for j := range matched {
+			if !matched[j] && conf.equals(got.Index(j), ithWant, cmp, p.add(node(i))) {
+				matched[j], foundEqual = true, true
+				if cmp.cover != nil {
+					cmp.exitCover(cmp.enterCover(p.add(node(i))))
+				}
 				break
 			}
 		}
 		if !foundEqual {
-			// For the purposes of error reporting, if no match
-			// is found, execute comparison for the elements at i.
-			conf.compare(got.Index(i), ithWant, cmp, q)
+			unmatched = append(unmatched, i)
+		}
+	}
+
+	diffs := conf.newElemDiffs(cmp)
+	for _, i := range unmatched {
+		q := p.add(node(i))
+		ithWant := want.Index(i)
+
+		closest, fewest := -1, 0
+		for j := range matched {
+			if matched[j] {
+				continue
+			}
+			if n := conf.distance(got.Index(j), ithWant, cmp, q); closest < 0 || n < fewest {
+				closest, fewest = j, n
+			}
+		}
+
+		mark := diffs.mark()
+		if closest < 0 {
+			cmp.errs.add(&elemError{want: ithWant, path: q})
+		} else {
+			matched[closest] = true
+			conf.compare(got.Index(closest), ithWant, cmp, q)
+		}
+		diffs.check(mark)
+	}
+	for j := range matched {
+		if !matched[j] {
+			mark := diffs.mark()
+			cmp.errs.add(&elemError{got: got.Index(j), path: p.add(node(j))})
+			diffs.check(mark)
 		}
 	}
+	diffs.done(want.Kind(), p)
 }
 
 // compareInterface compares the underlying element values of the two interface values.
@@ -237,6 +1269,11 @@ func (conf Config) compareInterface(got, want reflect.Value, cmp *comparison, p
 		cmp.errs.add(&nilError{got, want, p})
 		return
 	}
+	if fn, ok := conf.Projections[got.Type()]; ok && !conf.Strict && !got.IsNil() {
+		if ok := conf.compareProjected(fn, got, want, cmp, p); ok {
+			return
+		}
+	}
 	got = got.Elem()
 	want = want.Elem()
 	conf.compare(got, want, cmp, p)
@@ -252,36 +1289,398 @@ func (conf Config) comparePointer(got, want reflect.Value, cmp *comparison, p pa
 	conf.compare(got, want, cmp, p)
 }
 
-// compareStruct compares the corresponding fields of the two given struct values.
-func (conf Config) compareStruct(got, want reflect.Value, cmp *comparison, p path) {
-	if structIsTime(got) {
-		// CanInterface is used here to determine whether or not
-		// the value was obtained from an unexported field.
-		if m := got.MethodByName("Equal"); m.CanInterface() {
-			if !m.Call([]reflect.Value{want})[0].Bool() {
-				cmp.errs.add(&valueError{got, want, p})
-			}
-			return
-		}
+// comparePointerDepths compares the two given values if their types differ
+// only in the number of pointer indirections, e.g. **T and T, or, unless
+// IgnorePointerDepth is set, only in a single indirection. The value with
+// the fewer indirections is addressed until the depths match, so that nil
+// pointers are reported at the level at which they occur. It reports whether
+// the values were compared.
+func (conf Config) comparePointerDepths(got, want reflect.Value, cmp *comparison, p path) (ok bool) {
+	gt, gn := ptrDepth(got.Type())
+	wt, wn := ptrDepth(want.Type())
+	if gt != wt || gn == wn {
+		return false
+	}
+	if !conf.IgnorePointerDepth && gn-wn != 1 && wn-gn != 1 {
+		return false
 	}
 
-	for i, n := 0, want.NumField(); i < n; i++ {
-		f := want.Type().Field(i)
+	node := derefnode{side: "got", n: gn - wn}
+	if gn < wn {
+		node = derefnode{side: "want", n: wn - gn}
+	}
+	for ; gn < wn; gn++ {
+		if got, ok = addrOf(got); !ok {
+			return false
+		}
+	}
+	for ; wn < gn; wn++ {
+		if want, ok = addrOf(want); !ok {
+			return false
+		}
+	}
+	conf.compare(got, want, cmp, p.add(node))
+	return true
+}
+
+// ptrDepth returns the base type of the pointer type t and the number of
+// pointer indirections leading to it. Recursive pointer types, e.g. type P *P,
+// are followed only up to an arbitrary limit.
+func ptrDepth(t reflect.Type) (base reflect.Type, n int) {
+	for t.Kind() == reflect.Ptr && n < 64 {
+		t, n = t.Elem(), n+1
+	}
+	return t, n
+}
+
+// addrOf returns a pointer to the value v, or to a copy of v if it is not
+// addressable. The result is invalid if v can be neither addressed nor copied,
+// i.e. if it was obtained from an unexported field of a non-addressable struct.
+func addrOf(v reflect.Value) (reflect.Value, bool) {
+	if v.CanAddr() {
+		return v.Addr(), true
+	}
+	if v.CanInterface() {
+		return ptrCopy(v), true
+	}
+	return v, false
+}
+
+// compareStruct compares the corresponding fields of the two given struct values.
+func (conf Config) compareStruct(got, want reflect.Value, cmp *comparison, p path) {
+	if !conf.Strict && structIsTime(got) {
+		// CanInterface is used here to determine whether or not
+		// the value was obtained from an unexported field.
+		if m := got.MethodByName("Equal"); m.CanInterface() {
+			if conf.TimeTolerance > 0 || conf.IgnoreTimeZone {
+				conf.compareTimeDelta(got, want, cmp, p)
+			} else if !m.Call([]reflect.Value{want})[0].Bool() {
+				cmp.errs.add(&valueError{got, want, p})
+			} else if conf.CompareTimeLocation || conf.CompareTimeMonotonic {
+				conf.compareTimeParts(got, want, cmp, p)
+			}
+			return
+		}
+	}
+
+	mark, total, differ := len(cmp.errs.List), 0, 0
+	for i, n := 0, want.NumField(); i < n; i++ {
+		f := want.Type().Field(i)
+		q := p.add(structnode{f.Name})
+		if !conf.selectField(want, i, cmp, q) {
+			continue
+		}
+		places, warn := -1, false
 		if len(conf.ObserveFieldTag) > 0 {
 			switch tag := f.Tag.Get(conf.ObserveFieldTag); {
-			case tag == "omitempty" && isZero(want.Field(i)):
-				continue
-			case tag == "-":
-				continue
-			case tag == "+":
-				cmp.zero = true
+			case tag == "warn":
+				warn = true
+			case strings.HasPrefix(tag, "round="):
+				if n, err := strconv.Atoi(tag[len("round="):]); err == nil && isFloat(want.Field(i).Kind()) {
+					places = n
+				}
 			}
 		}
-		q := p.add(structnode{f.Name})
 		fieldGot := got.Field(i)
 		fieldWant := want.Field(i)
-		conf.compare(fieldGot, fieldWant, cmp, q)
+
+		total++
+		before := len(cmp.errs.List)
+		if places >= 0 {
+			conf.compareRounded(fieldGot, fieldWant, places, cmp, q)
+		} else {
+			conf.compare(fieldGot, fieldWant, cmp, q)
+		}
+		// compare may return before reaching compareZero, for example
+		// for an ignored path, so the "+" tag must not leak into the
+		// next field
+		cmp.zero = false
+		if warn {
+			cmp.markWarnings(before)
+		}
+		if len(cmp.errs.List) > before {
+			differ++
+		}
+	}
+
+	if conf.PublicView {
+		conf.compareGetters(got, want, cmp, p)
+	}
+
+	// in verbose mode the errors of the fields are preceded by a summary
+	if conf.Verbose && differ > 0 {
+		errs := append([]error{&fieldsError{differ, total, p}}, cmp.errs.List[mark:]...)
+		cmp.errs.List = append(cmp.errs.List[:mark], errs...)
+	}
+}
+
+// isAnonymousWant reports whether the want type is an unnamed struct type, or
+// a pointer, slice, array, or map of such a type, whose values can be compared
+// to the values of the got type, see AnonymousWantStructs.
+func isAnonymousWant(got, want reflect.Type) bool {
+	if got.Kind() != want.Kind() {
+		return false
+	}
+	switch want.Kind() {
+	case reflect.Struct:
+		return want.Name() == ""
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return isAnonymousWant(got.Elem(), want.Elem())
+	case reflect.Map:
+		return got.Key() == want.Key() && isAnonymousWant(got.Elem(), want.Elem())
+	}
+	return false
+}
+
+// compareAnonymousStruct compares the fields of the want struct of an unnamed
+// type to the fields of the same name of the got struct.
+func (conf Config) compareAnonymousStruct(got, want reflect.Value, cmp *comparison, p path) {
+	for i := 0; i < want.NumField(); i++ {
+		f := want.Type().Field(i)
+		if conf.isIgnoredField(f.Name) {
+			conf.skipField(`IgnoreFieldNames`, cmp, p.add(structnode{f.Name}))
+			continue
+		}
+		if !conf.Strict && isLockType(f.Type) {
+			conf.skipField("the lock type "+f.Type.String(), cmp, p.add(structnode{f.Name}))
+			continue
+		}
+		if conf.FieldFilter != nil && !conf.filterField(want, i, cmp, p.add(structnode{f.Name})) {
+			continue
+		}
+		if len(conf.ObserveFieldTag) > 0 && f.Tag.Get(conf.ObserveFieldTag) == "-" {
+			conf.skipField(`the "-" tag option`, cmp, p.add(structnode{f.Name}))
+			continue
+		}
+
+		q := p.add(structnode{f.Name})
+		gf, ok := got.Type().FieldByName(f.Name)
+		if !ok {
+			cmp.errs.add(&fieldError{nil, f.Type, q})
+			continue
+		}
+		fieldGot, err := got.FieldByIndexErr(gf.Index)
+		if err != nil {
+			// the field is promoted through a nil embedded pointer
+			cmp.errs.add(&validityError{got: fieldGot, want: want.Field(i), path: q})
+			continue
+		}
+		conf.compare(fieldGot, want.Field(i), cmp, q)
+	}
+}
+
+// selectField reports whether the ith field of the want struct at the path p
+// is to be compared, reporting the fields that are omitted from comparison,
+// see skipField. If only the "zero-ness" of the field is to be compared
+// cmp.zero is set.
+func (conf Config) selectField(want reflect.Value, i int, cmp *comparison, p path) (ok bool) {
+	f := want.Type().Field(i)
+	if conf.isIgnoredField(f.Name) {
+		conf.skipField(`IgnoreFieldNames`, cmp, p)
+		return false
+	}
+	if !conf.Strict && isLockType(f.Type) {
+		conf.skipField("the lock type "+f.Type.String(), cmp, p)
+		return false
+	}
+	if !f.IsExported() && conf.PublicView {
+		conf.skipField(`PublicView`, cmp, p)
+		return false
 	}
+	if !f.IsExported() && conf.isIgnoredUnexported(want.Type()) {
+		conf.skipField(`IgnoreUnexported`, cmp, p)
+		return false
+	}
+	if conf.FieldFilter != nil && !conf.filterField(want, i, cmp, p) {
+		return false
+	}
+	if len(conf.ObserveFieldTag) > 0 {
+		switch f.Tag.Get(conf.ObserveFieldTag) {
+		case "omitempty":
+			if conf.isZero(want.Field(i)) {
+				conf.skipField(`the "omitempty" tag option`, cmp, p)
+				return false
+			}
+		case "-":
+			conf.skipField(`the "-" tag option`, cmp, p)
+			return false
+		case "+":
+			cmp.zero = true
+		}
+	}
+	return true
+}
+
+// skipField reports the field at the path p that was omitted from comparison
+// by the given rule, if AuditSkippedFields is set.
+func (conf Config) skipField(rule string, cmp *comparison, p path) {
+	if !conf.AuditSkippedFields {
+		return
+	}
+	var err error = &skipError{rule, p}
+	if conf.SkippedFieldsAsWarnings {
+		err = &warningError{err}
+	}
+	cmp.errs.add(err)
+}
+
+// isIgnoredUnexported reports whether the unexported fields of the struct type
+// are omitted from comparison, see IgnoreUnexported.
+func (conf Config) isIgnoredUnexported(typ reflect.Type) bool {
+	if conf.IgnoreUnexported {
+		return true
+	}
+	for _, v := range conf.IgnoreUnexportedIn {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// isIgnoredField reports whether the field of the given name is listed in
+// IgnoreFieldNames.
+func (conf Config) isIgnoredField(name string) bool {
+	for _, n := range conf.IgnoreFieldNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// compareRounded compares the two float values rounded to the given number
+// of decimal places.
+func (conf Config) compareRounded(got, want reflect.Value, places int, cmp *comparison, p path) {
+	bits := want.Type().Bits()
+	if conf.bothNaN(got.Float(), want.Float()) {
+		return
+	}
+	if roundFloat(got.Float(), places, bits) != roundFloat(want.Float(), places, bits) {
+		cmp.errs.add(&valueError{got, want, p})
+	}
+}
+
+// roundFloat rounds f to the given number of decimal places. The exact binary
+// value of f is rounded, with ties rounded to even, e.g. 1.005, whose binary
+// value is slightly below 1.005, is rounded to 1.00 and 0.125 to 0.12.
+func roundFloat(f float64, places, bits int) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'f', places, bits), 64)
+	return r
+}
+
+// compareFloat compares the two float values, within the tolerance if one is
+// set.
+func (conf Config) compareFloat(got, want reflect.Value, cmp *comparison, p path) {
+	if conf.bothNaN(got.Float(), want.Float()) {
+		return
+	}
+	if conf.FloatTolerance <= 0 && conf.FloatRelTolerance <= 0 {
+		conf.compareInterfaceValue(got, want, cmp, p)
+		return
+	}
+	if g, w := got.Float(), want.Float(); !conf.withinTolerance(g, w) {
+		cmp.errs.add(&floatError{got, want, math.Abs(g - w), p})
+	}
+}
+
+// compareComplex compares the two complex values, with their NaN parts equal
+// if EquateNaNs is set.
+func (conf Config) compareComplex(got, want reflect.Value, cmp *comparison, p path) {
+	if conf.EquateNaNs {
+		g, w := got.Complex(), want.Complex()
+		if (real(g) == real(w) || conf.bothNaN(real(g), real(w))) && (imag(g) == imag(w) || conf.bothNaN(imag(g), imag(w))) {
+			return
+		}
+	}
+	conf.compareInterfaceValue(got, want, cmp, p)
+}
+
+// bothNaN reports whether EquateNaNs is set and both of the floats are NaN.
+func (conf Config) bothNaN(got, want float64) bool {
+	return conf.EquateNaNs && math.IsNaN(got) && math.IsNaN(want)
+}
+
+// isNaNKey reports whether the map key is a NaN float or a complex value
+// with a NaN part.
+func isNaNKey(key reflect.Value) bool {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	switch key.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(key.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := key.Complex()
+		return math.IsNaN(real(c)) || math.IsNaN(imag(c))
+	}
+	return false
+}
+
+// nanEntries holds the values of the entries of a map that are keyed by NaNs,
+// see EquateNaNs.
+type nanEntries struct {
+	vals []reflect.Value
+	used []bool
+}
+
+// newNaNEntries returns the entries of the map m that are keyed by NaNs.
+func newNaNEntries(m reflect.Value) *nanEntries {
+	e := &nanEntries{}
+	for it := m.MapRange(); it.Next(); {
+		if isNaNKey(it.Key()) {
+			e.vals = append(e.vals, it.Value())
+		}
+	}
+	e.used = make([]bool, len(e.vals))
+	return e
+}
+
+// next returns the value of the first unmatched entry and marks it as matched.
+func (e *nanEntries) next() reflect.Value {
+	for i, v := range e.vals {
+		if !e.used[i] {
+			e.used[i] = true
+			return v
+		}
+	}
+	return reflect.Value{}
+}
+
+// matchNaNEntry returns the value of an unmatched entry of e that is equal to
+// the want value or, if there is no such entry, the value of any unmatched
+// entry. The result is invalid if all of the entries have been matched.
+func (conf Config) matchNaNEntry(e *nanEntries, want reflect.Value, cmp *comparison, p path) reflect.Value {
+	for _, eq := range []bool{true, false} {
+		for i, v := range e.vals {
+			if !e.used[i] && (!eq || conf.equals(v, want, cmp, p)) {
+				e.used[i] = true
+				return v
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// withinTolerance reports whether the two floats are equal within the
+// FloatTolerance or the FloatRelTolerance.
+func (conf Config) withinTolerance(got, want float64) bool {
+	if got == want {
+		return true
+	}
+	delta := math.Abs(got - want)
+	if delta <= conf.FloatTolerance {
+		return true
+	}
+	return delta <= conf.FloatRelTolerance*math.Max(math.Abs(got), math.Abs(want))
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
 }
 
 // compareMap compares the length and contents of the two given map values.
@@ -293,31 +1692,292 @@ func (conf Config) compareMap(got, want reflect.Value, cmp *comparison, p path)
 		cmp.errs.add(&nilError{got, want, p})
 		return
 	}
+	if len(conf.IgnoredMapKeys) > 0 || cmp.keysAt != nil {
+		if keys := cmp.ignoredKeys(conf, want.Type(), p); len(keys) > 0 {
+			conf.compareMapIgnoreKeys(got, want, keys, cmp, p)
+			return
+		}
+	}
 	if got.Len() != want.Len() {
 		cmp.errs.add(&lenError{got, want, p})
-		// TODO(mkopriva): might be good to compare the contents and
-		// point out the "missing" or the "extra" elements...
+		if conf.ReportMapKeys {
+			// with no ignored keys all of the entries are compared
+			// and the keys missing from either of the maps reported
+			conf.compareMapIgnoreKeys(got, want, nil, cmp, p)
+		}
 		return
 	}
 
-	for _, key := range want.MapKeys() {
+	if conf.IterateMaps && conf.Seed == 0 {
+		conf.compareMapIter(got, want, cmp, p)
+		return
+	}
+
+	keys := want.MapKeys()
+	if conf.Seed != 0 {
+		sortMapKeys(keys)
+	}
+
+	var nans, wantNaNs *nanEntries
+	diffs := conf.newElemDiffs(cmp)
+	sample := conf.newSampler(cmp, want.Len())
+	for _, key := range keys {
+		if sample != nil && !sample.next() {
+			continue
+		}
 		q := p.add(mapnode{key})
 		valGot := got.MapIndex(key)
 		valWant := want.MapIndex(key)
+		if !valWant.IsValid() && conf.EquateNaNs && isNaNKey(key) {
+			// the NaN keys cannot be looked up, each occurrence of
+			// such a key in the keys of want stands for one of its
+			// NaN keyed entries
+			if nans == nil {
+				nans, wantNaNs = newNaNEntries(got), newNaNEntries(want)
+			}
+			valWant = wantNaNs.next()
+			valGot = conf.matchNaNEntry(nans, valWant, cmp, q)
+		}
 
+		mark := diffs.mark()
 		if !valGot.IsValid() || !valWant.IsValid() {
-			cmp.errs.add(&validityError{valGot, valWant, q})
-			continue
+			cmp.errs.add(&validityError{valGot, valWant, q, key})
+		} else {
+			conf.compare(valGot, valWant, cmp, q)
+		}
+		if sample != nil && diffs.mark() > mark {
+			sample.differ++
+		}
+		diffs.check(mark)
+	}
+	diffs.done(want.Kind(), p)
+	if sample != nil {
+		sample.done(cmp, want.Len(), want.Kind(), p)
+	}
+}
+
+// sortMapKeys sorts the map keys by their Go-syntax representation.
+func sortMapKeys(keys []reflect.Value) {
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprintf("%#v", keys[i]) < fmt.Sprintf("%#v", keys[j])
+	})
+}
+
+// compareMapIter compares the contents of the two map values by pairing up
+// the entries of both maps in the order of their keys, see IterateMaps, the
+// comparison stops once the limit of differing entries is exceeded.
+func (conf Config) compareMapIter(got, want reflect.Value, cmp *comparison, p path) {
+	gs, ws := sortedEntries(got), sortedEntries(want)
+	diffs := conf.newElemDiffs(cmp)
+	sample := conf.newSampler(cmp, want.Len())
+
+loop:
+	for i, j := 0, 0; i < len(ws) || j < len(gs); {
+		// the runs of the entries whose keys have the same
+		// textual representation, usually a single entry
+		var str string
+		switch {
+		case j == len(gs):
+			str = ws[i].str
+		case i == len(ws):
+			str = gs[j].str
+		default:
+			str = min(ws[i].str, gs[j].str)
+		}
+		wrun, grun := entryRun(ws[i:], str), entryRun(gs[j:], str)
+		i, j = i+len(wrun), j+len(grun)
+
+		for _, w := range wrun {
+			if diffs.exceeded() {
+				diffs.stopped = true
+				break loop
+			}
+			q := p.add(mapnode{w.key})
+			valGot := conf.takeEntry(grun, w, cmp, q)
+			if sample != nil && !sample.next() {
+				continue
+			}
+
+			mark := diffs.mark()
+			if !valGot.IsValid() {
+				cmp.errs.add(&validityError{valGot, w.val, q, w.key})
+			} else {
+				conf.compare(valGot, w.val, cmp, q)
+			}
+			if sample != nil && diffs.mark() > mark {
+				sample.differ++
+			}
+			diffs.check(mark)
+		}
+		for _, g := range grun {
+			if !g.key.IsValid() {
+				continue // paired up with a want entry
+			}
+			if diffs.exceeded() {
+				diffs.stopped = true
+				break loop
+			}
+			mark := diffs.mark()
+			cmp.errs.add(&validityError{g.val, reflect.Value{}, p.add(mapnode{g.key}), g.key})
+			diffs.check(mark)
+		}
+	}
+	diffs.done(want.Kind(), p)
+	if sample != nil && !diffs.stopped {
+		sample.done(cmp, want.Len(), want.Kind(), p)
+	}
+}
+
+// mapEntry is an entry of a map along with the textual representation of its
+// key by which the entries are sorted.
+type mapEntry struct {
+	key, val reflect.Value
+	str      string
+}
+
+// sortedEntries returns the entries of the map m sorted by the textual
+// representation of their keys, i.e. in the order in which the printer
+// renders them.
+func sortedEntries(m reflect.Value) []mapEntry {
+	entries := make([]mapEntry, 0, m.Len())
+	for it := m.MapRange(); it.Next(); {
+		key := it.Key()
+		entries = append(entries, mapEntry{key, it.Value(), fmt.Sprintf("%#v", key)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].str < entries[j].str
+	})
+	return entries
+}
+
+// entryRun returns the leading entries whose keys have the textual representation str.
+func entryRun(entries []mapEntry, str string) []mapEntry {
+	n := 0
+	for n < len(entries) && entries[n].str == str {
+		n++
+	}
+	return entries[:n]
+}
+
+// takeEntry returns the value of the entry of the run whose key is equal to
+// the key of the entry w and removes the entry from the run. NaN keys, which
+// are equal to no key, are matched to other NaN keys if EquateNaNs is set,
+// preferably to those of an equal value. The result is invalid if the run
+// has no matching entry.
+func (conf Config) takeEntry(run []mapEntry, w mapEntry, cmp *comparison, p path) reflect.Value {
+	if !conf.EquateNaNs || !isNaNKey(w.key) {
+		for k, g := range run {
+			if g.key.IsValid() && g.key.Equal(w.key) {
+				run[k] = mapEntry{}
+				return g.val
+			}
 		}
-		conf.compare(valGot, valWant, cmp, q)
+		return reflect.Value{}
 	}
+	for _, eq := range []bool{true, false} {
+		for k, g := range run {
+			if g.key.IsValid() && isNaNKey(g.key) && (!eq || conf.equals(g.val, w.val, cmp, p)) {
+				run[k] = mapEntry{}
+				return g.val
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// elemDiffs keeps count of the elements of a single collection that were
+// found to differ and drops the errors of those elements that exceed the
+// configured MaxDiffsPerCollection limit.
+type elemDiffs struct {
+	cmp   *comparison
+	max   int
+	count int
+	// set if the comparison of the collection was stopped early
+	stopped bool
+}
+
+func (conf Config) newElemDiffs(cmp *comparison) elemDiffs {
+	return elemDiffs{cmp: cmp, max: conf.MaxDiffsPerCollection}
+}
+
+// mark returns the current number of errors, it is intended to be
+// called just before the comparison of a collection's element.
+func (d *elemDiffs) mark() int {
+	return len(d.cmp.errs.List)
 }
 
-// compareFunc only checks whether the two given func values are nil.
+// check counts the element as different if its comparison produced any
+// new errors and, if the limit was exceeded, drops those new errors.
+func (d *elemDiffs) check(mark int) {
+	if d.max <= 0 || len(d.cmp.errs.List) == mark {
+		return
+	}
+	if d.count++; d.count > d.max {
+		d.cmp.errs.List = d.cmp.errs.List[:mark]
+	}
+}
+
+// exceeded reports whether the number of differing elements exceeds the limit.
+func (d *elemDiffs) exceeded() bool {
+	return d.max > 0 && d.count > d.max
+}
+
+// done adds an error summarizing the number of dropped elements, if any.
+func (d *elemDiffs) done(kind reflect.Kind, p path) {
+	if d.stopped {
+		d.cmp.errs.add(&moreError{-1, kind, p})
+		return
+	}
+	if more := d.count - d.max; d.max > 0 && more > 0 {
+		d.cmp.errs.add(&moreError{more, kind, p})
+	}
+}
+
+// compareFunc checks whether the two given func values are nil, or if both
+// are non-nil and the FuncArgs are set for their type, it compares the two
+// func values by their results.
 func (conf Config) compareFunc(got, want reflect.Value, cmp *comparison, p path) {
-	if !got.IsNil() || !want.IsNil() {
-		cmp.errs.add(&funcError{got, want, p})
+	if got.IsNil() && want.IsNil() {
+		return
 	}
+	if samples, ok := conf.FuncArgs[want.Type()]; ok && !got.IsNil() && !want.IsNil() {
+		gotx, gok := interfaceOf(got)
+		wantx, wok := interfaceOf(want)
+		if gok && wok {
+			conf.compareFuncResults(reflect.ValueOf(gotx), reflect.ValueOf(wantx), samples, cmp, p)
+			return
+		}
+	}
+	cmp.errs.add(&funcError{got, want, p})
+}
+
+// compareFuncResults calls the two func values with each of the sample argument
+// lists and compares the results.
+func (conf Config) compareFuncResults(got, want reflect.Value, samples [][]interface{}, cmp *comparison, p path) {
+	typ := want.Type()
+	for i, sample := range samples {
+		args := make([]reflect.Value, len(sample))
+		for j, arg := range sample {
+			args[j] = funcArg(typ, j, arg)
+		}
+
+		gotOut, wantOut := got.Call(args), want.Call(args)
+		for j := range wantOut {
+			q := p.add(callnode{i, j, len(wantOut)})
+			conf.compare(gotOut[j], wantOut[j], cmp, q)
+		}
+	}
+}
+
+// funcArg returns the jth argument of a call to a func of the given type.
+func funcArg(typ reflect.Type, j int, arg interface{}) reflect.Value {
+	if arg != nil {
+		return reflect.ValueOf(arg)
+	}
+	if typ.IsVariadic() && j >= typ.NumIn()-1 {
+		return reflect.Zero(typ.In(typ.NumIn() - 1).Elem())
+	}
+	return reflect.Zero(typ.In(j))
 }
 
 // compareString
@@ -326,11 +1986,62 @@ func (conf Config) compareString(got, want reflect.Value, cmp *comparison, p pat
 	if gots == wants {
 		return
 	}
+	if _, ok := conf.Formatters[want.Type()]; ok {
+		cmp.errs.add(&valueError{got, want, p})
+		return
+	}
+	if conf.LineDiff && (strings.Contains(gots, "\n") || strings.Contains(wants, "\n")) {
+		cmp.errs.add(&linesError{gots, wants, p})
+		return
+	}
 	cmp.errs.add(newStringError(gots, wants, p))
 }
 
-// compareChan
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// equalPrimitiveElems reports whether the two arrays or slices, if their
+// elements are of a primitive kind, have equal elements, without comparing
+// the elements one by one. If the elements are not equal, or not of a
+// primitive kind, they have to be compared individually in order for their
+// differences to be reported.
+func (conf Config) equalPrimitiveElems(got, want reflect.Value, cmp *comparison) bool {
+	if cmp.cover != nil || cmp.walk != nil || got.Len() != want.Len() {
+		return false
+	}
+	elem := got.Type().Elem()
+	if _, ok := conf.Comparers[elem]; ok {
+		return false
+	}
+	if got.Type() == bytesType {
+		return bytes.Equal(got.Bytes(), want.Bytes())
+	}
+	switch elem.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+	default:
+		return false
+	}
+	for i := 0; i < got.Len(); i++ {
+		if !primitiveEqual(got.Index(i), want.Index(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareChan compares the contents of the two given channel values, in strict
+// mode, or if CompareChanIdentity is set, the two channel values are compared
+// by their identity instead.
 func (conf Config) compareChan(got, want reflect.Value, cmp *comparison, p path) {
+	if conf.Strict || conf.CompareChanIdentity {
+		if got.Pointer() != want.Pointer() {
+			cmp.errs.add(&identityError{got, want, p})
+		}
+		return
+	}
+
 	if got.Len() != want.Len() {
 		cmp.errs.add(&lenError{got, want, p})
 		// TODO(mkopriva): might be good to compare the contents and
@@ -338,31 +2049,165 @@ func (conf Config) compareChan(got, want reflect.Value, cmp *comparison, p path)
 		return
 	}
 
+	if conf.IgnoreChanOrder {
+		gotElems, wantElems := drainChan(got), drainChan(want)
+		conf.compareArrayIgnoreOrder(gotElems, wantElems, cmp, p, newChannode)
+		return
+	}
+
 	if length := want.Len(); length > 0 {
+		diffs := conf.newElemDiffs(cmp)
 		for i := 1; i <= length; i++ {
 			q := p.add(channode{i})
 			ithGot, _ := got.Recv()
 			ithWant, _ := want.Recv()
+			mark := diffs.mark()
 			conf.compare(ithGot, ithWant, cmp, q)
+			diffs.check(mark)
 		}
+		diffs.done(want.Kind(), p)
 	}
 }
 
+// drainChan receives all of the elements currently buffered in the given
+// channel and returns them as a slice.
+func drainChan(c reflect.Value) reflect.Value {
+	n := c.Len()
+	elems := reflect.MakeSlice(reflect.SliceOf(c.Type().Elem()), 0, n)
+	for i := 0; i < n; i++ {
+		elem, _ := c.Recv()
+		elems = reflect.Append(elems, elem)
+	}
+	return elems
+}
+
 // compareInterfaceValue compares the two given values as normal interface{} values.
 func (conf Config) compareInterfaceValue(got, want reflect.Value, cmp *comparison, p path) {
-	if g, w := valueInterface(got), valueInterface(want); g != w {
-		cmp.errs.add(&valueError{g, w, p})
+	// the values are boxed only once they are known to differ
+	if primitiveEqual(got, want) {
+		return
+	}
+	if _, ok := conf.Formatters[want.Type()]; ok {
+		// retain the type so that the values can be formatted
+		cmp.errs.add(&valueError{got, want, p})
+		return
 	}
+	cmp.errs.add(&valueError{valueInterface(got), valueInterface(want), p})
+}
+
+// compareErrorChain compares the error chains of the two given error values
+// link by link and reports the first link at which the two chains diverge.
+// The links are compared by their type and their own message, i.e. without
+// the messages of the errors they wrap, which are compared by the next links
+// of the chains. It reports false, and does not compare the chains, if any
+// of the links is a nil pointer, whose Error method cannot be called, the
+// values are then to be compared as normal values.
+func (conf Config) compareErrorChain(got, want reflect.Value, cmp *comparison, p path) bool {
+	gotChain := errorChain(got.Interface())
+	wantChain := errorChain(want.Interface())
+	for _, chain := range [][]error{gotChain, wantChain} {
+		for _, link := range chain {
+			if isNilError(link) {
+				return false
+			}
+		}
+	}
+
+	for i := 0; i < len(gotChain) || i < len(wantChain); i++ {
+		if i >= len(gotChain) || i >= len(wantChain) {
+			cmp.errs.add(&chainError{gotChain, wantChain, i, p})
+			return true
+		}
+		g, w := gotChain[i], wantChain[i]
+		if reflect.TypeOf(g) != reflect.TypeOf(w) || ownMessage(g) != ownMessage(w) {
+			cmp.errs.add(&chainError{gotChain, wantChain, i, p})
+			return true
+		}
+	}
+	return true
 }
 
 // compareZero checks whether the two given values are both zero or both non-zero values.
 func (conf Config) compareZero(got, want reflect.Value, cmp *comparison, p path) {
-	if g, w := isZero(got), isZero(want); g != w {
+	if g, w := conf.isZero(got), conf.isZero(want); g != w {
 		cmp.errs.add(&zeroError{g, w, p})
 	}
 	cmp.zero = false
 }
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// isError reports whether the given value implements the error interface
+// and whether its underlying error value can be retrieved.
+func isError(v reflect.Value) bool {
+	return v.Type().Implements(errorType) && v.CanInterface()
+}
+
+// errorChain returns the chain of errors obtained by repeatedly unwrapping
+// the given error, the first element of the chain is the error itself. The
+// errors that wrap multiple errors, i.e. with an "Unwrap() []error" method,
+// are followed by the chains of each of the wrapped errors, that is, the
+// tree of errors is flattened depth-first, in the order in which errors.Is
+// visits it. If the given interface value is nil the returned chain will be
+// empty.
+func errorChain(v interface{}) (chain []error) {
+	err, _ := v.(error)
+	return appendErrorChain(chain, err)
+}
+
+func appendErrorChain(chain []error, err error) []error {
+	for err != nil {
+		chain = append(chain, err)
+		if isNilError(err) {
+			// the nil pointers are not unwrapped, their methods may panic
+			return chain
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				chain = appendErrorChain(chain, err)
+			}
+			return chain
+		default:
+			return chain
+		}
+	}
+	return chain
+}
+
+// ownMessage returns the message of the given error without the messages of
+// the errors it wraps, e.g. "open: " for an error created by fmt.Errorf with
+// the "open: %w" format.
+func ownMessage(err error) string {
+	msg := err.Error()
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := x.Unwrap(); inner != nil {
+			msg = strings.TrimSuffix(msg, inner.Error())
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range x.Unwrap() {
+			if inner != nil {
+				msg = strings.Replace(msg, inner.Error(), "", 1)
+			}
+		}
+	}
+	return msg
+}
+
+// isNilError reports whether the given non-nil error interface value holds
+// a nil pointer, or a nil value of another kind that can be nil.
+func isNilError(err error) bool {
+	v := reflect.ValueOf(err)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
+
 func structIsTime(v reflect.Value) bool {
 	typ := v.Type()
 	return typ.PkgPath() == "time" && typ.Name() == "Time"
@@ -398,16 +2243,34 @@ func valueInterface(v reflect.Value) interface{} {
 	return v.Interface()
 }
 
-func isZero(v reflect.Value) bool {
+// interfaceOf returns the interface{} value of v. If v was obtained from an
+// unexported struct field, the value can be retrieved only if v is addressable.
+func interfaceOf(v reflect.Value) (x interface{}, ok bool) {
+	if v.CanInterface() {
+		return v.Interface(), true
+	}
+	if v.CanAddr() {
+		return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem().Interface(), true
+	}
+	return nil, false
+}
+
+// isZero reports whether v is zero, using ZeroFuncs for the types present in it.
+func (conf Config) isZero(v reflect.Value) bool {
 	if !v.IsValid() {
 		return true
 	}
+	if fn, ok := conf.ZeroFuncs[v.Type()]; ok {
+		if x, ok := interfaceOf(v); ok {
+			return fn(x)
+		}
+	}
 	if v.Kind() != reflect.Struct {
 		return v.IsZero()
 	}
 
 	for i, n := 0, v.NumField(); i < n; i++ {
-		if !isZero(v.Field(i)) {
+		if !conf.isZero(v.Field(i)) {
 			return false
 		}
 	}
@@ -1,11 +1,16 @@
 package compare
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"net"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 type Basic struct {
@@ -198,6 +203,7 @@ var compareTests = []CompareTest{
 				rootnode{rtof(map[int]string{})},
 				mapnode{key: rvof(2)},
 			},
+			key: rvof(2),
 		}),
 	}, {
 		a: map[int]string{1: "one", 2: "txo"},
@@ -300,6 +306,7 @@ var compareTests = []CompareTest{
 				rootnode{rtof(map[float64]float64{})},
 				mapnode{key: rvof(1)},
 			},
+			key: rvof(1),
 		}),
 	}, {
 		a: map[float64]float64{math.NaN(): 1}, b: self{},
@@ -460,14 +467,14 @@ var compareTests = []CompareTest{
 	},
 }
 
-func TestCompare(t *testing.T) {
-	var errstr = func(err error) string {
-		if err == nil {
-			return "<nil>"
-		}
-		return err.Error()
+func errstr(err error) string {
+	if err == nil {
+		return "<nil>"
 	}
+	return err.Error()
+}
 
+func TestCompare(t *testing.T) {
 	conf := Config{ObserveFieldTag: "cmp"}
 
 	for _, test := range compareTests {
@@ -482,6 +489,968 @@ func TestCompare(t *testing.T) {
 	}
 }
 
+func TestCompareStrict(t *testing.T) {
+	conf := Config{ObserveFieldTag: "cmp", CompareErrorChains: true, Strict: true}
+	ints := []int{1, 2}
+
+	tests := append([]CompareTest{
+		{a: ints[:1], b: ints},
+		{a: chanint(1), b: chanint(1)},
+		{a: now1, b: now2},
+		{a: Tagged{"abc", "foo", ""}, b: Tagged{"def", "bar", ""}},
+		{a: fmt.Errorf("wrap: %w", plainErr("x")), b: fmt.Errorf("wrap: %w", plainErr("x"))},
+	}, compareTests...)
+
+	for _, test := range tests {
+		if test.b == (self{}) {
+			test.b = test.a
+		}
+
+		err := conf.Compare(test.a, test.b)
+		if deq := reflect.DeepEqual(test.a, test.b); (err == nil) != deq {
+			t.Errorf("Compare(%v, %v) = %v, reflect.DeepEqual = %t", test.a, test.b, err, deq)
+		}
+	}
+}
+
+func TestCompareMaxDiffsPerCollection(t *testing.T) {
+	tests := []CompareTest{
+		{a: []int{1, 2, 3}, b: []int{1, 2, 3}, err: nil},
+		{
+			a: []int{1, 2, 3}, b: []int{1, 5, 3},
+			err: elist(&valueError{
+				got: int(2), want: int(5),
+				path: path{rootnode{rtof([]int{})}, arrnode{1}},
+			}),
+		}, {
+			a: []int{1, 2, 3, 4}, b: []int{5, 6, 7, 4},
+			err: elist(&valueError{
+				got: int(1), want: int(5),
+				path: path{rootnode{rtof([]int{})}, arrnode{0}},
+			}, &valueError{
+				got: int(2), want: int(6),
+				path: path{rootnode{rtof([]int{})}, arrnode{1}},
+			}, &moreError{
+				count: 1, kind: reflect.Slice,
+				path: path{rootnode{rtof([]int{})}},
+			}),
+		}, {
+			a: [][]int{{1, 2, 3}, {4}, {5}},
+			b: [][]int{{0, 0, 0}, {0}, {0}},
+			err: elist(&valueError{
+				got: int(1), want: int(0),
+				path: path{rootnode{rtof([][]int{})}, arrnode{0}, arrnode{0}},
+			}, &valueError{
+				got: int(2), want: int(0),
+				path: path{rootnode{rtof([][]int{})}, arrnode{0}, arrnode{1}},
+			}, &moreError{
+				count: 1, kind: reflect.Slice,
+				path: path{rootnode{rtof([][]int{})}, arrnode{0}},
+			}, &valueError{
+				got: int(4), want: int(0),
+				path: path{rootnode{rtof([][]int{})}, arrnode{1}, arrnode{0}},
+			}, &moreError{
+				count: 1, kind: reflect.Slice,
+				path: path{rootnode{rtof([][]int{})}},
+			}),
+		}, {
+			a: map[string]int{"a": 1, "b": 2, "c": 3},
+			b: map[string]int{"a": 0, "b": 0, "c": 0},
+			err: elist(&moreError{
+				count: 1, kind: reflect.Map,
+				path: path{rootnode{rtof(map[string]int{})}},
+			}),
+		},
+	}
+
+	conf := Config{MaxDiffsPerCollection: 2}
+	for _, test := range tests {
+		err := conf.Compare(test.a, test.b)
+		if el, ok := err.(*errorList); ok && reflect.TypeOf(test.b).Kind() == reflect.Map {
+			// the order of map entries is random, check only the summary
+			if len(el.List) != 3 {
+				t.Errorf("Compare(%v, %v) got %d errors, want 3", test.a, test.b, len(el.List))
+				continue
+			}
+			err = elist(el.List[2])
+		}
+		if errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+}
+
+func TestCompareIterateMaps(t *testing.T) {
+	big1, big2 := make(map[int]int), make(map[int]int)
+	for i := 0; i < 100; i++ {
+		big1[i], big2[i] = i, -i
+	}
+
+	tests := []struct {
+		conf Config
+		CompareTest
+	}{{
+		conf: Config{IterateMaps: true},
+		CompareTest: CompareTest{
+			a:   map[int]string{1: "one", 2: "two"},
+			b:   map[int]string{2: "two", 1: "one"},
+			err: nil,
+		},
+	}, {
+		conf: Config{IterateMaps: true},
+		CompareTest: CompareTest{
+			a: map[int]string{1: "one", 3: "two"},
+			b: map[int]string{2: "two", 1: "one"},
+			err: elist(&validityError{
+				got: rvof(nil), want: rvof("two"),
+				path: path{
+					rootnode{rtof(map[int]string{})},
+					mapnode{key: rvof(2)},
+				},
+				key: rvof(2),
+			}, &validityError{
+				got: rvof("two"), want: rvof(nil),
+				path: path{
+					rootnode{rtof(map[int]string{})},
+					mapnode{key: rvof(3)},
+				},
+				key: rvof(3),
+			}),
+		},
+	}, {
+		conf: Config{IterateMaps: true, EquateNaNs: true},
+		CompareTest: CompareTest{
+			a:   map[float64]int{math.NaN(): 1, math.NaN(): 2, 0: 0},
+			b:   map[float64]int{math.NaN(): 2, math.NaN(): 1, 0: 0},
+			err: nil,
+		},
+	}, {
+		conf: Config{IterateMaps: true},
+		CompareTest: CompareTest{
+			a: map[int]string{1: "one", 2: "txo"},
+			b: map[int]string{2: "two", 1: "one"},
+			err: elist(newStringError(
+				"txo", "two",
+				path{
+					rootnode{rtof(map[int]string{})},
+					mapnode{key: rvof(2)},
+				})),
+		},
+	}, {
+		conf: Config{IterateMaps: true, Strict: true},
+		CompareTest: CompareTest{
+			a: map[int]string{1: "one", 2: "txo"},
+			b: map[int]string{2: "two", 1: "one"},
+			err: elist(newStringError(
+				"txo", "two",
+				path{
+					rootnode{rtof(map[int]string{})},
+					mapnode{key: rvof(2)},
+				})),
+		},
+	}}
+
+	for _, test := range tests {
+		err := test.conf.Compare(test.a, test.b)
+		if errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+
+	// the iteration stops once the limit is exceeded
+	conf := Config{IterateMaps: true, MaxDiffsPerCollection: 2}
+	err := conf.Compare(big1, big2)
+	el, ok := err.(*errorList)
+	if !ok || len(el.List) != 3 {
+		t.Fatalf("Compare(big1, big2) = %v, want 3 errors", err)
+	}
+	want := elist(&moreError{
+		count: -1, kind: reflect.Map,
+		path: path{rootnode{rtof(map[int]int{})}},
+	})
+	if got := elist(el.List[2]); errstr(got) != errstr(want) {
+		t.Errorf("\"%s\" != \"%s\"", errstr(got), errstr(want))
+	}
+
+	// the entries are compared in the order of their keys
+	for i, key := range []int{1, 10} {
+		want := elist(&valueError{
+			got: key, want: -key,
+			path: path{rootnode{rtof(map[int]int{})}, mapnode{key: rvof(key)}},
+		})
+		if got := elist(el.List[i]); errstr(got) != errstr(want) {
+			t.Errorf("\"%s\" != \"%s\"", errstr(got), errstr(want))
+		}
+	}
+}
+
+func TestCompareAggregateCollectionErrors(t *testing.T) {
+	type T struct {
+		A []int
+		B [][]string
+		C string
+	}
+
+	tests := []CompareTest{
+		{a: T{A: []int{1, 2}}, b: T{A: []int{1, 2}}, err: nil},
+		{
+			a: T{A: []int{1, 2, 3}, B: [][]string{{"a"}, {"b", "c"}}, C: "x"},
+			b: T{A: []int{1, 5, 6}, B: [][]string{{"z"}, {"b", "y"}}, C: "y"},
+			err: elist(&collectionError{
+				errs: []error{&valueError{
+					got: int(2), want: int(5),
+					path: path{rootnode{rtof(T{})}, structnode{"A"}, arrnode{1}},
+				}, &valueError{
+					got: int(3), want: int(6),
+					path: path{rootnode{rtof(T{})}, structnode{"A"}, arrnode{2}},
+				}},
+				kind: reflect.Slice,
+				path: path{rootnode{rtof(T{})}, structnode{"A"}},
+			}, &collectionError{
+				errs: []error{newStringError("a", "z", path{
+					rootnode{rtof(T{})}, structnode{"B"}, arrnode{0}, arrnode{0},
+				}), newStringError("c", "y", path{
+					rootnode{rtof(T{})}, structnode{"B"}, arrnode{1}, arrnode{1},
+				})},
+				kind: reflect.Slice,
+				path: path{rootnode{rtof(T{})}, structnode{"B"}},
+			}, newStringError("x", "y", path{
+				rootnode{rtof(T{})}, structnode{"C"},
+			})),
+		}, {
+			a: T{A: []int{1, 2}},
+			b: T{A: []int{1, 3}},
+			err: elist(&valueError{
+				got: int(2), want: int(3),
+				path: path{rootnode{rtof(T{})}, structnode{"A"}, arrnode{1}},
+			}),
+		},
+	}
+
+	conf := Config{AggregateCollectionErrors: true}
+	for _, test := range tests {
+		if err := conf.Compare(test.a, test.b); errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+}
+
+func TestCompareIgnoreChanOrder(t *testing.T) {
+	tests := []CompareTest{
+		{a: chanint(), b: chanint(), err: nil},
+		{a: chanint(1, 2, 3), b: chanint(3, 1, 2), err: nil},
+		{a: chanint(1, 2, 2), b: chanint(2, 1, 2), err: nil},
+		{
+			a: chanint(1, 2, 3), b: chanint(3, 1, 4),
+			err: elist(&valueError{
+				got: int(2), want: int(4),
+				path: path{rootnode{rtof(make(chan int))}, channode{3}},
+			}),
+		}, {
+			a: chanint(1, 2), b: chanint(1),
+			err: elist(&lenError{
+				got: rvof(chanint(1, 2)), want: rvof(chanint(1)),
+				path: path{rootnode{rtof(make(chan int))}},
+			}),
+		},
+	}
+
+	conf := Config{IgnoreChanOrder: true}
+	for _, test := range tests {
+		if err := conf.Compare(test.a, test.b); errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+}
+
+func TestCompareIgnoreArrayOrderClosest(t *testing.T) {
+	type T struct {
+		ID         int
+		Name, Role string
+	}
+
+	tests := []struct {
+		a, b interface{}
+		err  string
+	}{{
+		a:   []T{{1, "a", "x"}, {2, "b", "y"}, {3, "c", "z"}},
+		b:   []T{{3, "c", "z"}, {2, "b", "w"}, {1, "a", "x"}},
+		err: "- ([]compare.T)[1].Role: Value mismatch; got=\"y\", want=\"w\"; differs at byte 0 (rune 0)\n",
+	}, {
+		a: []T{{1, "a", "x"}, {2, "b", "y"}, {3, "c", "z"}},
+		b: []T{{9, "c", "z"}, {1, "a", "x"}, {8, "b", "y"}},
+		err: "- ([]compare.T)[0].ID: Value mismatch; got=3, want=9\n" +
+			"- ([]compare.T)[2].ID: Value mismatch; got=2, want=8\n",
+	}}
+
+	conf := Config{IgnoreArrayOrder: true}
+	for _, tt := range tests {
+		if got := Golden(conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("Compare(%v, %v) got:\n%s\nwant:\n%s", tt.a, tt.b, got, tt.err)
+		}
+	}
+}
+
+type ID string
+
+type ZeroTagged struct {
+	ID   ID `cmp:"+"`
+	id   ID `cmp:"+"`
+	Name string
+}
+
+func TestCompareZeroFuncs(t *testing.T) {
+	tests := []CompareTest{
+		{a: ZeroTagged{ID: "000"}, b: ZeroTagged{ID: ""}, err: nil},
+		{a: &ZeroTagged{id: "000"}, b: &ZeroTagged{id: ""}, err: nil},
+		{a: ZeroTagged{ID: "123"}, b: ZeroTagged{ID: "456"}, err: nil},
+		{
+			a: ZeroTagged{ID: "000"}, b: ZeroTagged{ID: "123"},
+			err: elist(&zeroError{true, false, path{
+				rootnode{rtof(ZeroTagged{})},
+				structnode{field: "ID"},
+			}}),
+		}, {
+			a: &ZeroTagged{id: "123"}, b: &ZeroTagged{id: "000"},
+			err: elist(&zeroError{false, true, path{
+				rootnode{rtof(&ZeroTagged{})},
+				structnode{field: "id"},
+			}}),
+		},
+	}
+
+	conf := Config{ObserveFieldTag: "cmp", ZeroFuncs: map[reflect.Type]func(interface{}) bool{
+		rtof(ID("")): func(v interface{}) bool {
+			return len(strings.Trim(string(v.(ID)), "0")) == 0
+		},
+	}}
+	for _, test := range tests {
+		if err := conf.Compare(test.a, test.b); errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+}
+
+func TestCompareZeroTagEarlyReturn(t *testing.T) {
+	type T struct {
+		E error `cmp:"+"`
+		N int
+	}
+	q := path{rootnode{rtof(T{})}, structnode{field: "N"}}
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+	}{
+		{conf: Config{ObserveFieldTag: "cmp", IgnorePaths: []string{"E"}}, a: T{N: 1}, b: T{N: 2}},
+		{conf: Config{ObserveFieldTag: "cmp"}, a: T{E: errors.New("foo"), N: 1}, b: T{E: ErrorContains("foo"), N: 2}},
+	}
+	for _, test := range tests {
+		want := elist(&valueError{got: 1, want: 2, path: q})
+		if err := test.conf.Compare(test.a, test.b); errstr(err) != errstr(want) {
+			t.Errorf("Compare(%v, %v) = %v, want %v", test.a, test.b, err, want)
+		}
+	}
+}
+
+func TestCompareRoundTag(t *testing.T) {
+	type Price struct {
+		Amount float64 `cmp:"round=2"`
+		Rate   float32 `cmp:"round=0"`
+		Count  int     `cmp:"round=2"`
+		amount float64 `cmp:"round=1"`
+	}
+
+	tests := []CompareTest{
+		{a: Price{Amount: 1.004}, b: Price{Amount: 0.996}, err: nil},
+		{a: Price{Amount: 10.1 + 0.2}, b: Price{Amount: 10.3}, err: nil},
+		{a: Price{Rate: 2.4}, b: Price{Rate: 1.6}, err: nil},
+		{a: &Price{amount: 0.04}, b: &Price{amount: 0.01}, err: nil},
+		{
+			a: Price{Amount: 1.006}, b: Price{Amount: 1.004},
+			err: elist(&valueError{
+				got: float64(1.006), want: float64(1.004),
+				path: path{rootnode{rtof(Price{})}, structnode{field: "Amount"}},
+			}),
+		}, {
+			a: Price{Count: 1}, b: Price{Count: 2},
+			err: elist(&valueError{
+				got: int(1), want: int(2),
+				path: path{rootnode{rtof(Price{})}, structnode{field: "Count"}},
+			}),
+		}, {
+			a: &Price{amount: 0.06}, b: &Price{amount: 0.01},
+			err: elist(&valueError{
+				got: float64(0.06), want: float64(0.01),
+				path: path{rootnode{rtof(&Price{})}, structnode{field: "amount"}},
+			}),
+		},
+	}
+
+	conf := Config{ObserveFieldTag: "cmp"}
+	for _, test := range tests {
+		if err := conf.Compare(test.a, test.b); errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+}
+
+func TestCompareFloatTolerance(t *testing.T) {
+	type T struct {
+		F64 float64
+		F32 float32
+		R   float64 `cmp:"round=1"`
+	}
+
+	tests := []struct {
+		conf   Config
+		a, b   interface{}
+		reason string
+	}{{
+		conf: Config{FloatTolerance: 1e-9},
+		a:    T{F64: 0.1 + 0.2, F32: 1}, b: T{F64: 0.3, F32: 1},
+		reason: "",
+	}, {
+		conf: Config{FloatTolerance: 0.01},
+		a:    T{F64: 1.5, F32: 2.5}, b: T{F64: 1.505, F32: 2.52},
+		reason: "- (compare.T).F32: Value mismatch; got=2.5, want=2.52, delta=0.01999998",
+	}, {
+		conf: Config{FloatRelTolerance: 0.01},
+		a:    []float64{1000, 1000, 0}, b: []float64{1009, 1011, 0},
+		reason: "- ([]float64)[1]: Value mismatch; got=1000, want=1011, delta=11",
+	}, {
+		conf: Config{FloatTolerance: 1},
+		a:    []float64{math.NaN(), math.Inf(1)}, b: []float64{math.NaN(), math.Inf(1)},
+		reason: "- ([]float64)[0]: Value mismatch; got=NaN, want=NaN, delta=NaN",
+	}, {
+		conf: Config{FloatTolerance: 1, ObserveFieldTag: "cmp"},
+		a:    T{R: 1.0}, b: T{R: 1.5},
+		reason: "- (compare.T).R: Value mismatch; got=1, want=1.5",
+	}}
+
+	for _, tt := range tests {
+		if _, reason := tt.conf.Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+		if eq := tt.conf.EqualNoReport(tt.a, tt.b); eq != (tt.reason == "") {
+			t.Errorf("EqualNoReport(%v, %v) got=%t, want=%t", tt.a, tt.b, eq, tt.reason == "")
+		}
+	}
+}
+
+func TestCompareIgnorePointerDepth(t *testing.T) {
+	type T struct {
+		V interface{}
+	}
+	one, two := 1, 2
+	pone, ptwo := &one, &two
+	var nilp *int
+
+	tests := []struct {
+		a, b   interface{}
+		reason string
+	}{
+		{a: &pone, b: 1, reason: ""},
+		{a: 1, b: &pone, reason: ""},
+		{a: T{&pone}, b: T{pone}, reason: ""},
+		{
+			a: T{&ptwo}, b: T{pone},
+			reason: `- (compare.T).V{*got}: Value mismatch; got=2, want=1`,
+		}, {
+			a: T{two}, b: T{&pone},
+			reason: `- (compare.T).V{**want}: Value mismatch; got=2, want=1`,
+		}, {
+			a: &nilp, b: 1,
+			reason: `- (int){**got}: Validity mismatch; got=INVALID, want=VALID`,
+		}, {
+			a: &one, b: "1",
+			reason: `- (string): Type mismatch; got=*int, want=string`,
+		},
+	}
+
+	conf := Config{IgnorePointerDepth: true}
+	for _, tt := range tests {
+		if _, reason := conf.Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+		if eq := conf.EqualNoReport(tt.a, tt.b); eq != (tt.reason == "") {
+			t.Errorf("EqualNoReport(%v, %v) got=%t, want=%t", tt.a, tt.b, eq, tt.reason == "")
+		}
+	}
+}
+
+func TestCompareAutoDeref(t *testing.T) {
+	type T struct {
+		Name string
+	}
+	one := 1
+	pone := &one
+
+	tests := []struct {
+		a, b   interface{}
+		reason string
+	}{
+		{a: &T{"a"}, b: T{"a"}, reason: ""},
+		{a: T{"a"}, b: &T{"a"}, reason: ""},
+		{
+			a: &T{"a"}, b: T{"b"},
+			reason: `- (compare.T){*got}.Name: Value mismatch; got="a", want="b"; differs at byte 0 (rune 0)`,
+		}, {
+			a: (*T)(nil), b: T{"b"},
+			reason: `- (compare.T){*got}: Validity mismatch; got=INVALID, want=VALID`,
+		}, {
+			a: &pone, b: 1,
+			reason: `- (int): Type mismatch; got=**int, want=int`,
+		},
+	}
+
+	conf := Config{AutoDeref: true}
+	for _, tt := range tests {
+		if _, reason := conf.Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+		if eq := conf.EqualNoReport(tt.a, tt.b); eq != (tt.reason == "") {
+			t.Errorf("EqualNoReport(%v, %v) got=%t, want=%t", tt.a, tt.b, eq, tt.reason == "")
+		}
+	}
+}
+
+func TestCompareIgnoreFieldNames(t *testing.T) {
+	type Author struct {
+		Name      string
+		CreatedAt int
+	}
+	type Book struct {
+		Title     string
+		Authors   []*Author
+		CreatedAt int
+		UpdatedAt int
+	}
+
+	tests := []CompareTest{
+		{
+			a:   Book{Title: "a", CreatedAt: 1, UpdatedAt: 1, Authors: []*Author{{Name: "x", CreatedAt: 1}}},
+			b:   Book{Title: "a", CreatedAt: 2, UpdatedAt: 2, Authors: []*Author{{Name: "x", CreatedAt: 2}}},
+			err: nil,
+		}, {
+			a: Book{Title: "a", CreatedAt: 1, Authors: []*Author{{Name: "x", CreatedAt: 1}}},
+			b: Book{Title: "b", CreatedAt: 2, Authors: []*Author{{Name: "y", CreatedAt: 2}}},
+			err: elist(&stringError{
+				got: "a", want: "b",
+				path: path{rootnode{rtof(Book{})}, structnode{field: "Title"}},
+			}, &stringError{
+				got: "x", want: "y",
+				path: path{rootnode{rtof(Book{})}, structnode{field: "Authors"}, arrnode{0}, structnode{field: "Name"}},
+			}),
+		},
+	}
+
+	conf := Config{IgnoreFieldNames: []string{"CreatedAt", "UpdatedAt"}}
+	for _, test := range tests {
+		if err := conf.Compare(test.a, test.b); errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+		if eq := conf.EqualNoReport(test.a, test.b); eq != (test.err == nil) {
+			t.Errorf("EqualNoReport(%v, %v) got=%t, want=%t", test.a, test.b, eq, test.err == nil)
+		}
+	}
+}
+
+func TestCompareAnonymousWantStructs(t *testing.T) {
+	type Author struct {
+		Name string
+		Born int
+	}
+	type Meta struct {
+		Tags []string
+	}
+	type Book struct {
+		*Meta
+		ID      int
+		Title   string
+		Author  *Author
+		Authors []Author
+	}
+
+	book := Book{
+		ID: 1, Title: "a", Author: &Author{Name: "x", Born: 1900},
+		Authors: []Author{{Name: "y"}, {Name: "z"}},
+	}
+	tests := []struct {
+		a, b   interface{}
+		reason string
+	}{{
+		a: book, b: struct{ ID int }{1},
+		reason: "",
+	}, {
+		a: book, b: struct {
+			Title  string
+			Author *struct{ Name string }
+		}{"a", &struct{ Name string }{"x"}},
+		reason: "",
+	}, {
+		a: &book, b: &struct {
+			Authors []struct{ Name string }
+		}{[]struct{ Name string }{{"y"}, {"w"}}},
+		reason: `- (*struct { Authors []struct { Name string } }).Authors[1].Name: Value mismatch; got="z", want="w"; differs at byte 0 (rune 0)`,
+	}, {
+		a: book, b: struct{ ISBN string }{},
+		reason: "- (struct { ISBN string }).ISBN: Field mismatch; got=<none>, want=string",
+	}, {
+		a: book, b: struct{ Tags []string }{},
+		reason: "- (struct { Tags []string }).Tags: Validity mismatch; got=INVALID, want=VALID",
+	}, {
+		a: book, b: struct{ ID string }{"1"},
+		reason: "- (struct { ID string }).ID: Type mismatch; got=int, want=string",
+	}, {
+		a: map[string]Author{"a": {Name: "x"}}, b: map[string]struct{ Name string }{"a": {"x"}},
+		reason: "",
+	}}
+
+	conf := Config{AnonymousWantStructs: true}
+	for _, tt := range tests {
+		if _, reason := conf.Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+	}
+	if equal, _ := (Config{}).Reason(book, struct{ ID int }{1}); equal {
+		t.Errorf("Reason without AnonymousWantStructs got=true, want=false")
+	}
+}
+
+func TestCompareEquateNaNs(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		a, b   interface{}
+		reason string
+	}{{
+		a: nan, b: nan, reason: "",
+	}, {
+		a: []float32{float32(nan), 1}, b: []float32{float32(nan), 1}, reason: "",
+	}, {
+		a: complex(nan, 1), b: complex(nan, 1), reason: "",
+	}, {
+		a: complex(nan, 1), b: complex(nan, 2), reason: "- (complex128): Value mismatch; got=(NaN+1i), want=(NaN+2i)",
+	}, {
+		a: nan, b: 1.0, reason: "- (float64): Value mismatch; got=NaN, want=1",
+	}, {
+		a: map[float64]int{nan: 1, 2: 2}, b: map[float64]int{nan: 1, 2: 2}, reason: "",
+	}, {
+		a: map[float64]int{nan: 1, math.NaN(): 2}, b: map[float64]int{nan: 2, math.NaN(): 1}, reason: "",
+	}, {
+		a: map[float64]int{nan: 1, 2: 2}, b: map[float64]int{nan: 3, 2: 2}, reason: "- (map[float64]int)[NaN]: Value mismatch; got=1, want=3",
+	}, {
+		a: map[float64]int{1: 1, 2: 2}, b: map[float64]int{nan: 1, 2: 2}, reason: "- (map[float64]int)[NaN]: Key NaN missing in got; want=1",
+	}}
+
+	conf := Config{EquateNaNs: true}
+	for _, tt := range tests {
+		if _, reason := conf.Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+		if eq := conf.EqualNoReport(tt.a, tt.b); eq != (tt.reason == "") {
+			t.Errorf("EqualNoReport(%v, %v) got=%t, want=%t", tt.a, tt.b, eq, tt.reason == "")
+		}
+	}
+
+	// the maps with NaN keys are compared in the same way when iterated
+	conf.IterateMaps = true
+	if equal, reason := conf.Reason(map[float64]int{nan: 1}, map[float64]int{math.NaN(): 1}); !equal {
+		t.Errorf("Reason with IterateMaps got=%q, want=\"\"", reason)
+	}
+	if equal, _ := (Config{}).Reason(nan, nan); equal {
+		t.Errorf("Reason without EquateNaNs got=true, want=false")
+	}
+}
+
+func TestCompareAuditSkippedFields(t *testing.T) {
+	type T struct {
+		ID        int
+		Name      string `cmp:"-"`
+		Note      string `cmp:"omitempty"`
+		UpdatedAt time.Time
+	}
+
+	a := T{ID: 1, Name: "a", Note: "x", UpdatedAt: time.Now()}
+	b := T{ID: 1, Name: "b"}
+	conf := Config{ObserveFieldTag: "cmp", IgnoreFieldNames: []string{"UpdatedAt"}, AuditSkippedFields: true}
+
+	want := "- (compare.T).Name: Field skipped by the \"-\" tag option\n" +
+		"- (compare.T).Note: Field skipped by the \"omitempty\" tag option\n" +
+		"- (compare.T).UpdatedAt: Field skipped by IgnoreFieldNames\n"
+	err := conf.Compare(a, b)
+	if got := Golden(err); got != want {
+		t.Errorf("Compare got:\n%s\nwant:\n%s", got, want)
+	}
+	if d := Differences(err); len(d) != 3 || d[2].Kind != SkipDiff || d[2].Detail != `IgnoreFieldNames` {
+		t.Errorf("Differences got=%+v", d)
+	}
+
+	b.Note = "x"
+	conf.SkippedFieldsAsWarnings = true
+	err = conf.Compare(a, b)
+	if f := Failures(err); f != nil {
+		t.Errorf("Failures got=%v, want=<nil>", f)
+	}
+	want = "[warning] - (compare.T).Name: Field skipped by the \"-\" tag option\n" +
+		"[warning] - (compare.T).UpdatedAt: Field skipped by IgnoreFieldNames\n"
+	if got := Golden(Warnings(err)); got != want {
+		t.Errorf("Warnings got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCompareFuncArgs(t *testing.T) {
+	type Strategy func(price float64, qty int) float64
+	type Table struct {
+		Discount Strategy
+		Split    func(s string, sep ...string) (string, bool)
+	}
+
+	half := Strategy(func(p float64, q int) float64 { return p * float64(q) / 2 })
+	half2 := Strategy(func(p float64, q int) float64 { return p * float64(q) * 0.5 })
+	full := Strategy(func(p float64, q int) float64 { return p * float64(q) })
+	split := func(s string, sep ...string) (string, bool) { return s, len(sep) > 0 }
+	split2 := func(s string, sep ...string) (string, bool) { return s, true }
+
+	conf := Config{FuncArgs: map[reflect.Type][][]interface{}{
+		rtof(half):  {{1.5, 2}, {10.0, 0}},
+		rtof(split): {{"a"}, {"b", "-"}},
+	}}
+
+	tests := []CompareTest{
+		{a: Table{}, b: Table{}, err: nil},
+		{a: Table{Discount: half2, Split: split}, b: Table{Discount: half, Split: split}, err: nil},
+		{
+			a: Table{Discount: full}, b: Table{Discount: half},
+			err: elist(&valueError{
+				got: float64(3), want: float64(1.5),
+				path: path{rootnode{rtof(Table{})}, structnode{field: "Discount"}, callnode{0, 0, 1}},
+			}),
+		}, {
+			a: Table{Split: split2}, b: Table{Split: split},
+			err: elist(&valueError{
+				got: true, want: false,
+				path: path{rootnode{rtof(Table{})}, structnode{field: "Split"}, callnode{0, 1, 2}},
+			}),
+		}, {
+			a: Table{}, b: Table{Discount: half},
+			err: elist(&funcError{
+				got: rvof(Strategy(nil)), want: rvof(half),
+				path: path{rootnode{rtof(Table{})}, structnode{field: "Discount"}},
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		if err := conf.Compare(test.a, test.b); errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+
+	// without FuncArgs non-nil funcs are always different
+	if err := Compare(Table{Discount: half}, Table{Discount: half}); err == nil {
+		t.Errorf("Compare() got=<nil>, want an error")
+	}
+}
+
+func TestCompareBreadthFirst(t *testing.T) {
+	type Inner struct {
+		X int
+		Y []int
+	}
+	type T struct {
+		A Inner
+		B int
+		C string
+	}
+	a := T{A: Inner{X: 1, Y: []int{1}}, B: 1, C: "a"}
+	b := T{A: Inner{X: 2, Y: []int{2}}, B: 2, C: "a"}
+
+	want := elist(&valueError{
+		got: int(1), want: int(2),
+		path: path{rootnode{rtof(T{})}, structnode{field: "B"}},
+	}, &valueError{
+		got: int(1), want: int(2),
+		path: path{rootnode{rtof(T{})}, structnode{field: "A"}, structnode{field: "X"}},
+	}, &valueError{
+		got: int(1), want: int(2),
+		path: path{rootnode{rtof(T{})}, structnode{field: "A"}, structnode{field: "Y"}, arrnode{0}},
+	})
+
+	conf := Config{BreadthFirst: true}
+	if err := conf.Compare(a, b); errstr(err) != errstr(want) {
+		t.Errorf("Compare(%v, %v) = %v\n\n", a, b, err)
+		t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(want))
+	}
+}
+
+func TestCompareVerbose(t *testing.T) {
+	type Publisher struct {
+		Name string
+		HQ   string
+	}
+	type Book struct {
+		Title     string
+		Pages     int
+		Publisher *Publisher
+		Internal  string `cmp:"-"`
+	}
+	a := Book{Title: "a", Pages: 1, Publisher: &Publisher{Name: "x", HQ: "y"}, Internal: "a"}
+	b := Book{Title: "a", Pages: 2, Publisher: &Publisher{Name: "z", HQ: "y"}, Internal: "b"}
+
+	want := elist(&fieldsError{
+		differ: 2, total: 3,
+		path: path{rootnode{rtof(Book{})}},
+	}, &valueError{
+		got: int(1), want: int(2),
+		path: path{rootnode{rtof(Book{})}, structnode{field: "Pages"}},
+	}, &fieldsError{
+		differ: 1, total: 2,
+		path: path{rootnode{rtof(Book{})}, structnode{field: "Publisher"}},
+	}, newStringError("x", "z", path{
+		rootnode{rtof(Book{})},
+		structnode{field: "Publisher"},
+		structnode{field: "Name"},
+	}))
+
+	conf := Config{Verbose: true, ObserveFieldTag: "cmp"}
+	if err := conf.Compare(a, b); errstr(err) != errstr(want) {
+		t.Errorf("Compare(%v, %v) = %v\n\n", a, b, err)
+		t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(want))
+	}
+	if err := conf.Compare(a, a); err != nil {
+		t.Errorf("Compare(%v, %v) = %v, want <nil>", a, a, err)
+	}
+}
+
+type wrapErr struct {
+	msg string
+	err error
+}
+
+func (e *wrapErr) Error() string { return e.msg + ": " + e.err.Error() }
+func (e *wrapErr) Unwrap() error { return e.err }
+
+type multiErr struct {
+	msg  string
+	errs []error
+}
+
+func (e *multiErr) Error() string   { return e.msg }
+func (e *multiErr) Unwrap() []error { return e.errs }
+
+type plainErr string
+
+func (e plainErr) Error() string { return string(e) }
+
+type errField struct {
+	Err error
+}
+
+func TestCompareErrorChains(t *testing.T) {
+	base := errors.New("base")
+	other := errors.New("other")
+	chain := func(errs ...error) []error { return errs }
+
+	w1 := fmt.Errorf("wrap: %w", base)
+	w2 := &wrapErr{"wrap", base}
+	w3 := fmt.Errorf("wrap: %w", other)
+	w4 := fmt.Errorf("wrap: %w", plainErr("base"))
+	w5 := fmt.Errorf("other: %w", base)
+	j1 := &multiErr{"multi", []error{w1, other}}
+	j2 := &multiErr{"multi", []error{w1, plainErr("other")}}
+	j3 := &multiErr{"multi", []error{w1}}
+
+	tests := []CompareTest{
+		{a: base, b: base, err: nil},
+		{a: fmt.Errorf("wrap: %w", base), b: w1, err: nil},
+		{a: errField{w1}, b: errField{fmt.Errorf("wrap: %w", base)}, err: nil},
+		{a: errField{nil}, b: errField{nil}, err: nil},
+		{
+			a: w1, b: w2,
+			err: elist(&chainError{
+				got: chain(w1, base), want: chain(w2, base), index: 0,
+				path: path{rootnode{rtof(w2)}},
+			}),
+		}, {
+			a: w1, b: w4,
+			err: elist(&chainError{
+				got: chain(w1, base), want: chain(w4, plainErr("base")), index: 1,
+				path: path{rootnode{rtof(w4)}},
+			}),
+		}, {
+			a: w1, b: base,
+			err: elist(&chainError{
+				got: chain(w1, base), want: chain(base), index: 0,
+				path: path{rootnode{rtof(base)}},
+			}),
+		}, {
+			a: errField{w1}, b: errField{w3},
+			err: elist(&chainError{
+				got: chain(w1, base), want: chain(w3, other), index: 1,
+				path: path{rootnode{rtof(errField{})}, structnode{field: "Err"}},
+			}),
+		}, {
+			a: w1, b: w5,
+			err: elist(&chainError{
+				got: chain(w1, base), want: chain(w5, base), index: 0,
+				path: path{rootnode{rtof(w5)}},
+			}),
+		}, {
+			a: errField{nil}, b: errField{base},
+			err: elist(&chainError{
+				got: nil, want: chain(base), index: 0,
+				path: path{rootnode{rtof(errField{})}, structnode{field: "Err"}},
+			}),
+		},
+		{a: errors.Join(w1, other), b: errors.Join(w1, other), err: nil},
+		{a: fmt.Errorf("%w, %w", base, other), b: fmt.Errorf("%w, %w", base, other), err: nil},
+		{
+			a: j1, b: j2,
+			err: elist(&chainError{
+				got: chain(j1, w1, base, other), want: chain(j2, w1, base, plainErr("other")), index: 3,
+				path: path{rootnode{rtof(j2)}},
+			}),
+		}, {
+			a: j1, b: j3,
+			err: elist(&chainError{
+				got: chain(j1, w1, base, other), want: chain(j3, w1, base), index: 3,
+				path: path{rootnode{rtof(j3)}},
+			}),
+		},
+	}
+
+	conf := Config{CompareErrorChains: true}
+	for _, test := range tests {
+		if err := conf.Compare(test.a, test.b); errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+
+	// the chains with nil pointer links are compared as normal values
+	nilErr := errField{(*wrapErr)(nil)}
+	if err := conf.Compare(nilErr, nilErr); err != nil {
+		t.Errorf("Compare(%v, %v) = %v, want <nil>", nilErr, nilErr, err)
+	}
+	for _, want := range []errField{{base}, {w2}, {&wrapErr{"wrap", (*wrapErr)(nil)}}} {
+		if err := conf.Compare(nilErr, want); err == nil {
+			t.Errorf("Compare(%v, %v) = <nil>, want error", nilErr, want)
+		}
+		if err := conf.Compare(want, nilErr); err == nil {
+			t.Errorf("Compare(%v, %v) = <nil>, want error", want, nilErr)
+		}
+	}
+}
+
 // Below is the example code used for generating the example output.
 
 type Author struct {
@@ -536,3 +1505,417 @@ func TestExample(t *testing.T) {
 		fmt.Println(err)
 	}
 }
+
+func TestCompareAlignElements(t *testing.T) {
+	type T struct {
+		ID   int
+		Name string
+	}
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: Config{AlignElements: true},
+		a:    []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, b: []int{1, 2, 3, 4, 42, 5, 6, 7, 8, 9, 10},
+		err: "- ([]int): Length of slice mismatch; got=10, want=11\n" +
+			"- ([]int)[4]: Element missing in got; want=42\n",
+	}, {
+		conf: Config{AlignElements: true},
+		a:    []int{1, 2, 7, 3}, b: []int{1, 2, 3},
+		err: "- ([]int): Length of slice mismatch; got=4, want=3\n" +
+			"- ([]int)[2]: Element unexpected in got; got=7\n",
+	}, {
+		conf: Config{AlignElements: true},
+		a:    []T{{1, "a"}, {2, "x"}, {4, "d"}}, b: []T{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}},
+		err: "- ([]compare.T): Length of slice mismatch; got=3, want=4\n" +
+			"- ([]compare.T)[1].Name: Value mismatch; got=\"x\", want=\"b\"; differs at byte 0 (rune 0)\n" +
+			"- ([]compare.T)[2]: Element missing in got; want={3 c}\n",
+	}, {
+		conf: Config{AlignElements: true, MaxDiffsPerCollection: 1},
+		a:    []int{}, b: []int{1, 2, 3},
+		err: "- ([]int): ...and 2 more differing elements of slice\n" +
+			"- ([]int): Length of slice mismatch; got=0, want=3\n" +
+			"- ([]int)[0]: Element missing in got; want=1\n",
+	}, {
+		conf: Config{AlignElements: true, IgnoreArrayOrder: true},
+		a:    []int{1, 2}, b: []int{3, 1, 2},
+		err: "- ([]int): Length of slice mismatch; got=2, want=3\n" +
+			"- ([]int)[0]: Element missing in got; want=3\n",
+	}, {
+		conf: Config{AlignElements: true, IgnoreArrayOrder: true},
+		a:    []int{4, 1, 2}, b: []int{2, 1},
+		err: "- ([]int): Length of slice mismatch; got=3, want=2\n" +
+			"- ([]int)[0]: Element unexpected in got; got=4\n",
+	}, {
+		conf: Config{},
+		a:    []int{1, 2}, b: []int{1, 2, 3},
+		err: "- ([]int): Length of slice mismatch; got=2, want=3\n",
+	}}
+
+	for _, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("Compare(%v, %v) got:\n%s\nwant:\n%s", tt.a, tt.b, got, tt.err)
+		}
+	}
+
+	diffs := Differences(Config{AlignElements: true}.Compare([]string{"a", "b"}, []string{"b"}))
+	want := []Difference{
+		{"", LenDiff, 2, 1, LenDifference{reflect.Slice, 2, 1}},
+		{"[0]", ElemDiff, "a", nil, nil},
+	}
+	if err := Compare(diffs, want); err != nil {
+		t.Errorf("Differences() %v", err)
+	}
+}
+
+func TestCompareReportMapKeys(t *testing.T) {
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: Config{ReportMapKeys: true},
+		a:    map[string]int{"a": 1, "b": 2, "x": 9}, b: map[string]int{"a": 1, "b": 3},
+		err: "- (map[string]int): Length of map mismatch; got=3, want=2\n" +
+			"- (map[string]int)[b]: Value mismatch; got=2, want=3\n" +
+			"- (map[string]int)[x]: Key x missing in want; got=9\n",
+	}, {
+		conf: Config{ReportMapKeys: true},
+		a:    map[int]string{1: "a"}, b: map[int]string{1: "a", 2: "b", 3: "c"},
+		err: "- (map[int]string): Length of map mismatch; got=1, want=3\n" +
+			"- (map[int]string)[2]: Key 2 missing in got; want=b\n" +
+			"- (map[int]string)[3]: Key 3 missing in got; want=c\n",
+	}, {
+		conf: Config{},
+		a:    map[int]string{1: "a"}, b: map[int]string{1: "a", 2: "b"},
+		err: "- (map[int]string): Length of map mismatch; got=1, want=2\n",
+	}}
+
+	for _, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("Compare(%v, %v) got:\n%s\nwant:\n%s", tt.a, tt.b, got, tt.err)
+		}
+	}
+}
+
+// caseless is a string type whose values are equal regardless of case.
+type caseless string
+
+func (s caseless) Equal(t caseless) bool { return strings.EqualFold(string(s), string(t)) }
+
+// money has an Equal method declared on its pointer type.
+type money struct {
+	cents    int64
+	currency string
+}
+
+func (m *money) Equal(n money) bool {
+	return m.cents == n.cents && strings.EqualFold(m.currency, n.currency)
+}
+
+// revision has an Equal method whose receiver and argument are pointers.
+type revision struct {
+	n    int
+	note string
+}
+
+func (r *revision) Equal(s *revision) bool { return r.n == s.n }
+
+func TestCompareUseEqualMethod(t *testing.T) {
+	type T struct {
+		IP    net.IP
+		Name  caseless
+		Price money
+		Prev  *money
+	}
+	type R struct {
+		Rev *revision
+	}
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: Config{UseEqualMethod: true},
+		a:    T{net.IPv4(10, 0, 0, 1), "Joe", money{100, "eur"}, &money{1, "usd"}},
+		b:    T{net.IP{10, 0, 0, 1}, "JOE", money{100, "EUR"}, &money{1, "USD"}},
+		err:  "",
+	}, {
+		conf: Config{UseEqualMethod: true},
+		a:    T{net.IPv4(10, 0, 0, 1), "Joe", money{100, "eur"}, nil},
+		b:    T{net.IPv4(10, 0, 0, 2), "Jane", money{101, "eur"}, nil},
+		err: "- (compare.T).IP: Value mismatch; got=10.0.0.1, want=10.0.0.2\n" +
+			"- (compare.T).Name: Value mismatch; got=Joe, want=Jane\n" +
+			"- (compare.T).Price: Value mismatch; got={100 eur}, want={101 eur}\n",
+	}, {
+		conf: Config{UseEqualMethod: true, Comparers: map[reflect.Type]func(got, want interface{}) bool{
+			reflect.TypeOf(caseless("")): func(got, want interface{}) bool { return got == want },
+		}},
+		a:   caseless("a"),
+		b:   caseless("A"),
+		err: "- (compare.caseless): Value mismatch; got=a, want=A\n",
+	}, {
+		conf: Config{UseEqualMethod: true},
+		a:    R{&revision{1, "a"}},
+		b:    R{&revision{1, "b"}},
+		err:  "",
+	}, {
+		conf: Config{UseEqualMethod: true},
+		a:    R{nil},
+		b:    R{nil},
+		err:  "",
+	}, {
+		conf: Config{UseEqualMethod: true},
+		a:    R{nil},
+		b:    R{&revision{1, "b"}},
+		err:  "- (compare.R).Rev: Validity mismatch; got=INVALID, want=VALID\n",
+	}, {
+		conf: Config{UseEqualMethod: true},
+		a:    R{&revision{1, "a"}},
+		b:    R{nil},
+		err:  "- (compare.R).Rev: Validity mismatch; got=VALID, want=INVALID\n",
+	}, {
+		conf: Config{},
+		a:    caseless("a"),
+		b:    caseless("A"),
+		err:  "- (compare.caseless): Value mismatch; got=\"a\", want=\"A\"; differs at byte 0 (rune 0)\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+		if eq := tt.conf.EqualNoReport(tt.a, tt.b); eq != (tt.err == "") {
+			t.Errorf("#%d: EqualNoReport() got=%t, want=%t", i, eq, tt.err == "")
+		}
+	}
+}
+
+func TestCompareIgnoreUnexported(t *testing.T) {
+	type cache struct {
+		hits int
+	}
+	type Client struct {
+		Name  string
+		mu    sync.Mutex
+		cache *cache
+	}
+	type Wrapper struct {
+		Client *Client
+		note   string
+	}
+	a := Wrapper{&Client{Name: "a", cache: &cache{1}}, "x"}
+	b := Wrapper{&Client{Name: "a", cache: &cache{2}}, "y"}
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: Config{IgnoreUnexported: true},
+		a:    a, b: b,
+		err: "",
+	}, {
+		conf: Config{IgnoreUnexportedIn: []interface{}{(*Client)(nil)}},
+		a:    a, b: b,
+		err: "- (compare.Wrapper).note: Value mismatch; got=\"x\", want=\"y\"; differs at byte 0 (rune 0)\n",
+	}, {
+		conf: Config{IgnoreUnexportedIn: []interface{}{Wrapper{}}},
+		a:    a, b: b,
+		err: "- (compare.Wrapper).Client.cache.hits: Value mismatch; got=1, want=2\n",
+	}, {
+		conf: Config{IgnoreUnexported: true, AuditSkippedFields: true},
+		a:    Wrapper{note: "x"}, b: Wrapper{note: "y"},
+		err: "- (compare.Wrapper).note: Field skipped by IgnoreUnexported\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+	}
+}
+
+func TestCompareMaxErrors(t *testing.T) {
+	type T struct {
+		A, B, C int
+		S       []int
+	}
+	a := T{1, 2, 3, []int{1, 2, 3}}
+	b := T{4, 5, 6, []int{4, 5, 6}}
+
+	tests := []struct {
+		conf Config
+		err  string
+	}{{
+		conf: Config{MaxErrors: 2},
+		err: "- (compare.T).A: Value mismatch; got=1, want=4\n" +
+			"- (compare.T).B: Value mismatch; got=2, want=5\n" +
+			"- (compare.T): ...and 4 more differences\n",
+	}, {
+		conf: Config{MaxErrors: 6},
+		err: "- (compare.T).A: Value mismatch; got=1, want=4\n" +
+			"- (compare.T).B: Value mismatch; got=2, want=5\n" +
+			"- (compare.T).C: Value mismatch; got=3, want=6\n" +
+			"- (compare.T).S[0]: Value mismatch; got=1, want=4\n" +
+			"- (compare.T).S[1]: Value mismatch; got=2, want=5\n" +
+			"- (compare.T).S[2]: Value mismatch; got=3, want=6\n",
+	}, {
+		conf: Config{FailFast: true},
+		err: "- (compare.T).A: Value mismatch; got=1, want=4\n" +
+			"- (compare.T): ...the comparison was stopped after 1 differences\n",
+	}, {
+		conf: Config{FailFast: true, MaxErrors: 4},
+		err: "- (compare.T).A: Value mismatch; got=1, want=4\n" +
+			"- (compare.T).B: Value mismatch; got=2, want=5\n" +
+			"- (compare.T).C: Value mismatch; got=3, want=6\n" +
+			"- (compare.T).S[0]: Value mismatch; got=1, want=4\n" +
+			"- (compare.T): ...the comparison was stopped after 4 differences\n",
+	}, {
+		conf: Config{FailFast: true, Strict: true},
+		err: "- (compare.T).A: Value mismatch; got=1, want=4\n" +
+			"- (compare.T): ...the comparison was stopped after 1 differences\n",
+	}}
+
+	for i, tt := range tests {
+		tt.conf.NoColor = true
+		if got := tt.conf.Compare(a, b).Error() + "\n"; got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+	}
+	if err := (Config{FailFast: true}).Compare(a, a); err != nil {
+		t.Errorf("Compare() with FailFast of equal values got=%v, want=<nil>", err)
+	}
+
+	diffs := Differences(Config{MaxErrors: 5}.Compare(a, b))
+	if d := diffs[len(diffs)-1]; d.Kind != MoreDiff || d.Got != 1 {
+		t.Errorf("Differences() got=%v, want a MoreDiff of 1", d)
+	}
+}
+
+func TestCompareMaxDepth(t *testing.T) {
+	type node struct {
+		V    int
+		Next *node
+	}
+	list := func(vs ...int) *node {
+		var n *node
+		for i := len(vs) - 1; i >= 0; i-- {
+			n = &node{vs[i], n}
+		}
+		return n
+	}
+
+	tests := []struct {
+		conf      Config
+		got, want interface{}
+		err       string
+	}{{
+		conf: Config{MaxDepth: 2},
+		got:  list(1, 2, 3, 4),
+		want: list(1, 2, 5, 6),
+		err: "- (*compare.node).Next.Next.V: Maximum depth of 2 exceeded; the values were not compared\n" +
+			"- (*compare.node).Next.Next.Next: Maximum depth of 2 exceeded; the values were not compared\n",
+	}, {
+		conf: Config{MaxDepth: 3},
+		got:  list(1, 2, 3, 4),
+		want: list(1, 2, 5, 6),
+		err: "- (*compare.node).Next.Next.V: Value mismatch; got=3, want=5\n" +
+			"- (*compare.node).Next.Next.Next.V: Maximum depth of 3 exceeded; the values were not compared\n" +
+			"- (*compare.node).Next.Next.Next.Next: Maximum depth of 3 exceeded; the values were not compared\n",
+	}, {
+		conf: Config{MaxDepth: 1},
+		got:  [][]int{{1}, {2}},
+		want: [][]int{{1}, {3}},
+		err:  "- ([][]int)[1][0]: Maximum depth of 1 exceeded; the values were not compared\n",
+	}, {
+		conf: Config{MaxDepth: 2},
+		got:  [][]int{{1}, {2}},
+		want: [][]int{{1}, {3}},
+		err:  "- ([][]int)[1][0]: Value mismatch; got=2, want=3\n",
+	}}
+
+	for i, tt := range tests {
+		tt.conf.NoColor = true
+		if got := tt.conf.Compare(tt.got, tt.want).Error() + "\n"; got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+	}
+
+	diffs := Differences(Config{MaxDepth: 1}.Compare([][]int{{1}}, [][]int{{2}}))
+	if len(diffs) != 1 || diffs[0].Kind != DepthDiff || diffs[0].Got != 1 {
+		t.Errorf("Differences() got=%v, want a single DepthDiff of 1", diffs)
+	}
+
+	// MaxDepth would report equal values as unequal, and so it is not
+	// retained by Strict
+	strict := Config{Strict: true, MaxDepth: 1, NoColor: true}
+	if err := strict.Compare([][]int{{1}}, [][]int{{1}}); err != nil {
+		t.Errorf("strict Compare() got=%v, want <nil>", err)
+	}
+	want := "- ([][]int)[0][0]: Value mismatch; got=1, want=2"
+	if err := strict.Compare([][]int{{1}}, [][]int{{2}}); errstr(err) != want {
+		t.Errorf("strict Compare() got=%v, want %s", err, want)
+	}
+}
+
+func TestCompareIdentity(t *testing.T) {
+	type T struct {
+		P unsafe.Pointer
+		C chan int
+	}
+	x, y := 1, 2
+	px, py := unsafe.Pointer(&x), unsafe.Pointer(&y)
+	c1, c2 := chanint(1), chanint(1)
+
+	tests := []struct {
+		conf      Config
+		got, want interface{}
+		err       string
+	}{{
+		got: T{px, chanint(1)}, want: T{px, chanint(1)},
+		err: "<nil>",
+	}, {
+		conf: Config{CompareChanIdentity: true},
+		got:  T{px, c1}, want: T{px, c1},
+		err: "<nil>",
+	}, {
+		conf: Config{CompareChanIdentity: true},
+		got:  T{px, nil}, want: T{px, nil},
+		err: "<nil>",
+	}, {
+		conf: Config{CompareChanIdentity: true},
+		got:  T{px, c1}, want: T{px, c2},
+		err: fmt.Sprintf("- (compare.T).C: Channel identity mismatch; got=%#x, want=%#x",
+			reflect.ValueOf(c1).Pointer(), reflect.ValueOf(c2).Pointer()),
+	}, {
+		conf: Config{Strict: true},
+		got:  T{px, nil}, want: T{px, c2},
+		err: fmt.Sprintf("- (compare.T).C: Channel identity mismatch; got=nil, want=%#x",
+			reflect.ValueOf(c2).Pointer()),
+	}, {
+		got: T{px, nil}, want: T{py, nil},
+		err: fmt.Sprintf("- (compare.T).P: Unsafe pointer mismatch; got=%#x, want=%#x", px, py),
+	}, {
+		got: T{nil, nil}, want: T{py, nil},
+		err: fmt.Sprintf("- (compare.T).P: Unsafe pointer mismatch; got=nil, want=%#x", py),
+	}}
+
+	for i, tt := range tests {
+		tt.conf.NoColor = true
+		if got := errstr(tt.conf.Compare(tt.got, tt.want)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+	}
+	if len(c1) != 1 || len(c2) != 1 {
+		t.Errorf("Compare() with CompareChanIdentity drained the channels")
+	}
+
+	diffs := Differences(Compare(T{px, nil}, T{py, nil}))
+	if len(diffs) != 1 || diffs[0].Kind != IdentityDiff || diffs[0].Got != uintptr(px) || diffs[0].Want != uintptr(py) {
+		t.Errorf("Differences() got=%v, want a single IdentityDiff", diffs)
+	}
+}
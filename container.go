@@ -0,0 +1,111 @@
+package compare
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// containerElems returns the elements of the container value v, see
+// Config.IterateContainers. The elements of containers that yield single
+// values are returned as a slice of those values, and the elements of the
+// containers that yield key-value pairs as a slice of structs with the Key
+// and Value fields. The ok return value reports whether v is a container.
+func containerElems(v reflect.Value) (elems reflect.Value, ok bool) {
+	if !v.CanInterface() {
+		if !v.CanAddr() {
+			return v, false
+		}
+		v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+	}
+
+	var iterate reflect.Value // func(yield func(...) bool)
+	if m := methodByName(v, "All"); m.IsValid() {
+		t := m.Type()
+		if t.NumIn() != 0 || t.NumOut() != 1 || !isSeq(t.Out(0)) {
+			return v, false
+		}
+		iterate = m.Call(nil)[0]
+	} else if m := methodByName(v, "Iterate"); m.IsValid() {
+		if !isSeq(m.Type()) {
+			return v, false
+		}
+		iterate = m
+	} else {
+		return v, false
+	}
+	if iterate.IsNil() {
+		return v, false
+	}
+	return collectSeq(iterate), true
+}
+
+// collectSeq returns the elements yielded by the iterator function iterate,
+// see isSeq, as a slice of the yielded values, or of structs with the Key and
+// Value fields if the iterator yields pairs.
+func collectSeq(iterate reflect.Value) reflect.Value {
+	yieldType := iterate.Type().In(0)
+	elemType := yieldType.In(0)
+	if yieldType.NumIn() == 2 {
+		elemType = reflect.StructOf([]reflect.StructField{
+			{Name: "Key", Type: yieldType.In(0)},
+			{Name: "Value", Type: yieldType.In(1)},
+		})
+	}
+	elems := reflect.MakeSlice(reflect.SliceOf(elemType), 0, 0)
+	if iterate.IsNil() {
+		return elems
+	}
+	yield := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+		elem := args[0]
+		if len(args) == 2 {
+			elem = reflect.New(elemType).Elem()
+			elem.Field(0).Set(args[0])
+			elem.Field(1).Set(args[1])
+		}
+		elems = reflect.Append(elems, elem)
+		return []reflect.Value{reflect.ValueOf(true)}
+	})
+	iterate.Call([]reflect.Value{yield})
+	return elems
+}
+
+// methodByName returns the method of the given name of v or of a pointer to v.
+func methodByName(v reflect.Value, name string) reflect.Value {
+	if m := v.MethodByName(name); m.IsValid() {
+		return m
+	}
+	if v.CanAddr() {
+		return v.Addr().MethodByName(name)
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr.MethodByName(name)
+}
+
+// isSeq reports whether t is the type of an iterator function, i.e. of the
+// form func(yield func(V) bool) or func(yield func(K, V) bool).
+func isSeq(t reflect.Type) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+	yield := t.In(0)
+	if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0).Kind() != reflect.Bool {
+		return false
+	}
+	return yield.NumIn() == 1 || yield.NumIn() == 2
+}
+
+// compareContainer compares the elements of the two container values, it
+// reports whether the values are containers.
+func (conf Config) compareContainer(got, want reflect.Value, cmp *comparison, p path) (ok bool) {
+	gotElems, ok := containerElems(got)
+	if !ok {
+		return false
+	}
+	wantElems, ok := containerElems(want)
+	if !ok {
+		return false
+	}
+	conf.compareArray(gotElems, wantElems, cmp, p)
+	return true
+}
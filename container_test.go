@@ -0,0 +1,102 @@
+package compare
+
+import (
+	"testing"
+)
+
+// orderedMap is a minimal ordered map whose internals depend on the history
+// of its modifications.
+type orderedMap[K comparable, V any] struct {
+	keys []K
+	vals map[K]V
+	dels int
+}
+
+func newOrderedMap[K comparable, V any](kvs ...interface{}) *orderedMap[K, V] {
+	m := &orderedMap[K, V]{vals: make(map[K]V)}
+	for i := 0; i < len(kvs); i += 2 {
+		m.Set(kvs[i].(K), kvs[i+1].(V))
+	}
+	return m
+}
+
+func (m *orderedMap[K, V]) Set(k K, v V) {
+	if _, ok := m.vals[k]; !ok {
+		m.keys = append(m.keys, k)
+	}
+	m.vals[k] = v
+}
+
+func (m *orderedMap[K, V]) Delete(k K) {
+	for i, key := range m.keys {
+		if key == k {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+		}
+	}
+	delete(m.vals, k)
+	m.dels++
+}
+
+func (m *orderedMap[K, V]) All() func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		for _, k := range m.keys {
+			if !yield(k, m.vals[k]) {
+				return
+			}
+		}
+	}
+}
+
+// set is a container that yields single values through an Iterate method.
+type set struct {
+	elems map[int]bool
+}
+
+func (s set) Iterate(yield func(int) bool) {
+	for i := 0; i < 10; i++ {
+		if s.elems[i] && !yield(i) {
+			return
+		}
+	}
+}
+
+func TestCompareIterateContainers(t *testing.T) {
+	deleted := newOrderedMap[string, int]("a", 1, "x", 0, "b", 2)
+	deleted.Delete("x")
+
+	tests := []struct {
+		a, b   interface{}
+		reason string
+	}{{
+		a: deleted, b: newOrderedMap[string, int]("a", 1, "b", 2),
+		reason: "",
+	}, {
+		a: newOrderedMap[string, int]("a", 1, "b", 3), b: newOrderedMap[string, int]("a", 1, "b", 2),
+		reason: "- (*compare.orderedMap[string,int])[1].Value: Value mismatch; got=3, want=2",
+	}, {
+		a: newOrderedMap[string, int]("b", 2, "a", 1), b: newOrderedMap[string, int]("a", 1, "b", 2),
+		reason: `- (*compare.orderedMap[string,int])[0].Key: Value mismatch; got="b", want="a"; differs at byte 0 (rune 0); ` +
+			`- (*compare.orderedMap[string,int])[0].Value: Value mismatch; got=2, want=1; ` +
+			`- (*compare.orderedMap[string,int])[1].Key: Value mismatch; got="a", want="b"; differs at byte 0 (rune 0); ` +
+			`- (*compare.orderedMap[string,int])[1].Value: Value mismatch; got=1, want=2`,
+	}, {
+		a: newOrderedMap[string, int]("a", 1), b: newOrderedMap[string, int]("a", 1, "b", 2),
+		reason: "- (*compare.orderedMap[string,int]): Length of slice mismatch; got=1, want=2",
+	}, {
+		a: set{map[int]bool{1: true, 2: true, 3: false}}, b: set{map[int]bool{1: true, 2: true}},
+		reason: "",
+	}, {
+		a: set{map[int]bool{1: true}}, b: set{map[int]bool{2: true}},
+		reason: "- (compare.set)[0]: Value mismatch; got=1, want=2",
+	}}
+
+	conf := Config{IterateContainers: true}
+	for _, tt := range tests {
+		if _, reason := conf.Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+	}
+	if equal, _ := (Config{}).Reason(tests[0].a, tests[0].b); equal {
+		t.Errorf("Reason without IterateContainers got=true, want=false")
+	}
+}
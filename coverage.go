@@ -0,0 +1,58 @@
+package compare
+
+import (
+	"bufio"
+	"io"
+)
+
+// coverage holds the paths compared by a single comparison, in the order in
+// which their comparison started, see Config.Coverage.
+type coverage struct {
+	entries []coverEntry
+}
+
+type coverEntry struct {
+	path     path
+	mismatch bool
+	// set if no other path was compared within the entry's path
+	leaf bool
+}
+
+// enterCover records the start of the comparison at the path p and returns
+// the index of its entry together with the current number of errors.
+func (cmp *comparison) enterCover(p path) (i, mark int) {
+	cmp.cover.entries = append(cmp.cover.entries, coverEntry{path: p})
+	return len(cmp.cover.entries) - 1, len(cmp.errs.List)
+}
+
+// exitCover records the end of the comparison of the ith entry, the entry is
+// marked as a mismatch if its comparison produced any errors.
+func (cmp *comparison) exitCover(i, mark int) {
+	cmp.cover.entries[i].mismatch = len(cmp.errs.List) > mark
+	cmp.cover.entries[i].leaf = len(cmp.cover.entries) == i+1
+}
+
+// write writes the coverage to w, one line per compared path. Each
+// line consists of the status of the path, i.e. either "match" or "mismatch",
+// followed by a tab and the path, in the same syntax as that of the paths of
+// the Differences. The root values are compared at the empty path, and the
+// pointers share the path of the values they point to, in which case the path
+// is written only once.
+func (c *coverage) write(w io.Writer) {
+	bw := bufio.NewWriter(w)
+	var last string
+	for i, e := range c.entries {
+		s := e.path.relpath()
+		if i > 0 && s == last {
+			continue
+		}
+		last = s
+
+		status := "match"
+		if e.mismatch {
+			status = "mismatch"
+		}
+		bw.WriteString(status + "\t" + s + "\n")
+	}
+	bw.Flush()
+}
@@ -0,0 +1,38 @@
+package compare
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareCoverage(t *testing.T) {
+	type Author struct {
+		Name string
+		Age  int `cmp:"-"`
+	}
+	type Book struct {
+		Title   string
+		Authors []*Author
+		Tags    map[string]int
+	}
+
+	got := Book{Title: "a", Authors: []*Author{{Name: "x"}}, Tags: map[string]int{"k": 1}}
+	want := Book{Title: "b", Authors: []*Author{{Name: "x"}}, Tags: map[string]int{"k": 1}}
+
+	var buf strings.Builder
+	conf := Config{ObserveFieldTag: "cmp", Coverage: &buf}
+	if err := conf.Compare(got, want); err == nil {
+		t.Fatalf("Compare() got=<nil>, want an error")
+	}
+
+	lines := "mismatch\t\n" +
+		"mismatch\t.Title\n" +
+		"match\t.Authors\n" +
+		"match\t.Authors[0]\n" +
+		"match\t.Authors[0].Name\n" +
+		"match\t.Tags\n" +
+		"match\t.Tags[k]\n"
+	if buf.String() != lines {
+		t.Errorf("Coverage got:\n%s\nwant:\n%s", buf.String(), lines)
+	}
+}
@@ -0,0 +1,71 @@
+package compare
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// ancestor identifies a value, by its address and type, on one of the sides of
+// the comparison whose contents are being compared, i.e. one that is an ancestor
+// of the currently compared values.
+type ancestor struct {
+	addr unsafe.Pointer
+	typ  reflect.Type
+}
+
+// ancestors holds the addressable values of the hard kinds that are being
+// compared, it is used to detect the cycles of the compared graphs of values.
+type ancestors struct {
+	// the indexes of the values on the stack
+	got, want map[ancestor]int
+	// the paths at which the values were entered
+	paths []path
+}
+
+// enterCycle checks whether the given got and want values, which are expected
+// to be addressable, close a cycle in either of the compared graphs of values.
+// If they close a cycle in both graphs that starts at the same pair of values,
+// the values are equal as far as the cycle is concerned and the comparison does
+// not need to continue. If they close a cycle in only one of the graphs, or two
+// cycles that start at different values, the structures of the graphs differ
+// and the difference is reported by a cycleError. The ok return value reports
+// whether the comparison needs to continue, and entered whether the values
+// were recorded as ancestors, in which case they must be removed from the
+// ancestors, with exitCycle, once their comparison is done.
+func (cmp *comparison) enterCycle(got, want reflect.Value, p path) (ok, entered bool) {
+	as := &cmp.ancestors
+	g := ancestor{unsafe.Pointer(got.UnsafeAddr()), got.Type()}
+	w := ancestor{unsafe.Pointer(want.UnsafeAddr()), want.Type()}
+
+	gi, gok := as.got[g]
+	wi, wok := as.want[w]
+	if gok && wok && gi == wi {
+		return false, false
+	}
+	if gok || wok {
+		err := &cycleError{path: p}
+		if gok {
+			err.got = as.paths[gi]
+		}
+		if wok {
+			err.want = as.paths[wi]
+		}
+		cmp.errs.add(err)
+		return false, false
+	}
+
+	if as.got == nil {
+		as.got, as.want = make(map[ancestor]int), make(map[ancestor]int)
+	}
+	as.got[g], as.want[w] = len(as.paths), len(as.paths)
+	as.paths = append(as.paths, p)
+	return true, true
+}
+
+// exitCycle removes the given got and want values from the ancestors.
+func (cmp *comparison) exitCycle(got, want reflect.Value) {
+	as := &cmp.ancestors
+	delete(as.got, ancestor{unsafe.Pointer(got.UnsafeAddr()), got.Type()})
+	delete(as.want, ancestor{unsafe.Pointer(want.UnsafeAddr()), want.Type()})
+	as.paths = as.paths[:len(as.paths)-1]
+}
@@ -0,0 +1,82 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+)
+
+type cycleNode struct {
+	V    int
+	Next *cycleNode
+}
+
+// ring returns the first node of a ring of nodes with the given values.
+func ring(vals ...int) *cycleNode {
+	nodes := make([]*cycleNode, len(vals))
+	for i, v := range vals {
+		nodes[i] = &cycleNode{V: v}
+	}
+	for i := range nodes {
+		nodes[i].Next = nodes[(i+1)%len(nodes)]
+	}
+	return nodes[0]
+}
+
+func TestCompareCycles(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		err  string
+	}{{
+		a: ring(1), b: ring(1),
+		err: "",
+	}, {
+		a: ring(1, 2), b: ring(1, 2),
+		err: "",
+	}, {
+		a: ring(1, 2), b: ring(1, 3),
+		err: "- (*compare.cycleNode).Next.V: Value mismatch; got=2, want=3\n",
+	}, {
+		a: ring(1), b: ring(1, 1),
+		err: "- (*compare.cycleNode).Next.Next: Cycle mismatch; " +
+			"got=cycle to .Next, want=<no cycle>\n",
+	}, {
+		a: ring(1, 1), b: ring(1),
+		err: "- (*compare.cycleNode).Next.Next: Cycle mismatch; " +
+			"got=<no cycle>, want=cycle to .Next\n",
+	}, {
+		// a cycle in got against an equal but finite chain in want
+		a: ring(1), b: &cycleNode{1, &cycleNode{1, nil}},
+		err: "- (*compare.cycleNode).Next.Next: Cycle mismatch; " +
+			"got=cycle to .Next, want=<no cycle>\n",
+	}}
+
+	for i, tt := range tests {
+		err := Compare(tt.a, tt.b)
+		if got := Golden(err); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+		if eq := EqualNoReport(tt.a, tt.b); eq != (err == nil) {
+			t.Errorf("#%d: EqualNoReport() got=%t, want=%t", i, eq, err == nil)
+		}
+	}
+
+	// the values that are shared differently but have no cycles are equal
+	type pair struct{ A, B *cycleNode }
+	n := &cycleNode{V: 1}
+	if err := Compare(pair{n, n}, pair{&cycleNode{V: 1}, &cycleNode{V: 1}}); err != nil {
+		t.Errorf("Compare() of shared values got=%v, want=<nil>", err)
+	}
+
+	diffs := Differences(Compare(ring(1), ring(1, 1)))
+	want := []Difference{{".Next.Next", CycleDiff, ".Next", nil, nil}}
+	if err := Compare(diffs, want); err != nil {
+		t.Errorf("Differences() got=%+v, want=%+v: %v", diffs, want, err)
+	}
+
+	// the Strict mode matches reflect.DeepEqual, which
+	// considers the differing cycles to be equal
+	a, b := ring(1), ring(1, 1)
+	if err := (Config{Strict: true}).Compare(a, b); (err == nil) != reflect.DeepEqual(a, b) {
+		t.Errorf("Compare() with Strict got=%v, want the result of reflect.DeepEqual", err)
+	}
+}
@@ -0,0 +1,71 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DecimalComparer returns a comparer, intended to be used with Config.Comparers,
+// that compares two values of the given decimal type by their numeric value,
+// e.g. 1.5 and 1.50 are equal. The type must have a method with the signature
+// of either
+//
+//	func (d T) Cmp(x T) int    // e.g. github.com/shopspring/decimal.Decimal
+//	func (d *T) Cmp(x *T) int  // e.g. github.com/cockroachdb/apd.Decimal
+//
+// where T is the given type, or, if the given type is a pointer type, its
+// element type. DecimalComparer panics if the type has no such method.
+//
+// The values of the decimal types are rendered in the errors by their String
+// method, if they have one, which for the types mentioned above produces their
+// canonical, non-exponential, form.
+func DecimalComparer(typ reflect.Type) func(got, want interface{}) bool {
+	elem, ptr := typ, false
+	if typ.Kind() == reflect.Ptr {
+		elem, ptr = typ.Elem(), true
+	}
+
+	if m, ok := elem.MethodByName("Cmp"); ok && isCmpMethod(m, elem) {
+		return func(got, want interface{}) bool {
+			g, w := reflect.ValueOf(got), reflect.ValueOf(want)
+			if ptr {
+				if g.IsNil() || w.IsNil() {
+					return g.IsNil() && w.IsNil()
+				}
+				g, w = g.Elem(), w.Elem()
+			}
+			return g.Method(m.Index).Call([]reflect.Value{w})[0].Int() == 0
+		}
+	}
+
+	pt := reflect.PointerTo(elem)
+	if m, ok := pt.MethodByName("Cmp"); ok && isCmpMethod(m, pt) {
+		return func(got, want interface{}) bool {
+			g, w := reflect.ValueOf(got), reflect.ValueOf(want)
+			if ptr {
+				if g.IsNil() || w.IsNil() {
+					return g.IsNil() && w.IsNil()
+				}
+			} else {
+				g, w = ptrCopy(g), ptrCopy(w)
+			}
+			return g.Method(m.Index).Call([]reflect.Value{w})[0].Int() == 0
+		}
+	}
+
+	panic(fmt.Sprintf("compare: %s has no Cmp method", typ))
+}
+
+// isCmpMethod reports whether the method m of the type typ has the signature
+// of a Cmp method, i.e. func(typ) int.
+func isCmpMethod(m reflect.Method, typ reflect.Type) bool {
+	t := m.Type // includes the receiver
+	return t.NumIn() == 2 && t.In(1) == typ && t.NumOut() == 1 && t.Out(0).Kind() == reflect.Int
+}
+
+// ptrCopy returns a pointer to a copy of the value v.
+func ptrCopy(v reflect.Value) reflect.Value {
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p
+}
@@ -0,0 +1,99 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// valDecimal mimics the API of shopspring/decimal.Decimal.
+type valDecimal struct {
+	coef int64
+	exp  int
+}
+
+func (d valDecimal) norm() int64 {
+	c := d.coef
+	for i := -4; i < d.exp; i++ {
+		c *= 10
+	}
+	return c
+}
+
+func (d valDecimal) Cmp(x valDecimal) int {
+	if a, b := d.norm(), x.norm(); a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func (d valDecimal) String() string {
+	return fmt.Sprintf("%de%d", d.coef, d.exp)
+}
+
+// ptrDecimal mimics the API of cockroachdb/apd.Decimal.
+type ptrDecimal struct {
+	d valDecimal
+}
+
+func (d *ptrDecimal) Cmp(x *ptrDecimal) int { return d.d.Cmp(x.d) }
+
+func TestDecimalComparer(t *testing.T) {
+	type Order struct {
+		Total valDecimal
+		Tax   ptrDecimal
+		Fee   *ptrDecimal
+	}
+
+	d := func(coef int64, exp int) valDecimal { return valDecimal{coef, exp} }
+	conf := Config{Comparers: map[reflect.Type]func(got, want interface{}) bool{
+		rtof(valDecimal{}):  DecimalComparer(rtof(valDecimal{})),
+		rtof(ptrDecimal{}):  DecimalComparer(rtof(ptrDecimal{})),
+		rtof(&ptrDecimal{}): DecimalComparer(rtof(&ptrDecimal{})),
+	}}
+
+	tests := []CompareTest{
+		{
+			a:   Order{Total: d(15, -1), Tax: ptrDecimal{d(2, 0)}, Fee: &ptrDecimal{d(100, -2)}},
+			b:   Order{Total: d(150, -2), Tax: ptrDecimal{d(20, -1)}, Fee: &ptrDecimal{d(1, 0)}},
+			err: nil,
+		}, {
+			a:   Order{},
+			b:   Order{},
+			err: nil,
+		}, {
+			a: Order{Total: d(15, -1), Fee: &ptrDecimal{}},
+			b: Order{Total: d(151, -2)},
+			err: elist(&valueError{
+				got: d(15, -1), want: d(151, -2),
+				path: path{rootnode{rtof(Order{})}, structnode{field: "Total"}},
+			}, &valueError{
+				got: &ptrDecimal{}, want: (*ptrDecimal)(nil),
+				path: path{rootnode{rtof(Order{})}, structnode{field: "Fee"}},
+			}),
+		}, {
+			a: Order{Tax: ptrDecimal{d(1, 0)}},
+			b: Order{Tax: ptrDecimal{d(2, 0)}},
+			err: elist(&valueError{
+				got: ptrDecimal{d(1, 0)}, want: ptrDecimal{d(2, 0)},
+				path: path{rootnode{rtof(Order{})}, structnode{field: "Tax"}},
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		if err := conf.Compare(test.a, test.b); errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("DecimalComparer(int) did not panic")
+		}
+	}()
+	DecimalComparer(rtof(0))
+}
@@ -0,0 +1,439 @@
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// DiffKind identifies the kind of a Difference.
+type DiffKind string
+
+const (
+	// One of the values is valid while the other is not, e.g. a map
+	// key present in one map and missing in the other.
+	ValidityDiff DiffKind = "validity"
+	// The values are of different types.
+	TypeDiff DiffKind = "type"
+	// One of the values is nil while the other is not.
+	NilDiff DiffKind = "nil"
+	// The lengths of the values are different.
+	LenDiff DiffKind = "length"
+	// The element is missing in one of the arrays or slices, i.e. the got
+	// or the want value is nil, see Config.AlignElements.
+	ElemDiff DiffKind = "element"
+	// At least one of the two func values is not nil.
+	FuncDiff DiffKind = "func"
+	// The values are different.
+	ValueDiff DiffKind = "value"
+	// The channel or unsafe.Pointer values are not identical, the Got and
+	// the Want of the Difference are the addresses they hold as uintptrs,
+	// see Config.CompareChanIdentity.
+	IdentityDiff DiffKind = "identity"
+	// Only one of the graphs of values cycles back at the path, or both
+	// cycle back but to different values, the Got and the Want of the
+	// Difference are the paths at which the cycles were entered, or nil.
+	CycleDiff DiffKind = "cycle"
+	// One of the values is zero while the other is not.
+	ZeroDiff DiffKind = "zero"
+	// The error chains of the values diverge.
+	ErrorChainDiff DiffKind = "error chain"
+	// The field is present in one of the struct types and missing from the
+	// other, see CompareTypes.
+	FieldDiff DiffKind = "field"
+	// The tags of the struct fields are different, see CompareTypes.
+	TagDiff DiffKind = "tag"
+	// The method is missing from the method set of a value or its signature
+	// is different from that declared by the interface, see Implements.
+	MethodDiff DiffKind = "method"
+	// The got value does not match the Matcher of the want value.
+	MatchDiff DiffKind = "match"
+	// The path of an expectation could not be resolved, see CompareTable.
+	PathDiff DiffKind = "path"
+	// The values are equal while they were expected to differ, see NotEqual.
+	EqualDiff DiffKind = "equal"
+	// The value was changed relative to the base value in got, in want,
+	// identically in both, or differently in both, see Compare3.
+	GotChangeDiff  DiffKind = "got change"
+	WantChangeDiff DiffKind = "want change"
+	BothChangeDiff DiffKind = "both change"
+	ConflictDiff   DiffKind = "conflict"
+	// The summary of the sampled elements of a collection, see SampleMinLen.
+	SampleDiff DiffKind = "sample"
+	// The struct field was omitted from comparison, see AuditSkippedFields.
+	SkipDiff DiffKind = "skip"
+	// The values are nested deeper than MaxDepth and were not compared,
+	// the Got of the Difference is the MaxDepth.
+	DepthDiff DiffKind = "depth"
+	// The number of differing elements of a collection that were
+	// not reported due to the MaxDiffsPerCollection limit, or of the
+	// differences that were not reported due to the MaxErrors limit,
+	// or -1 if the comparison was stopped early.
+	MoreDiff DiffKind = "more"
+)
+
+// Difference describes a single difference found by the comparison of two values.
+type Difference struct {
+	// The path to the differing values, e.g. ".Authors[0].FirstName",
+	// the path of the root values is empty.
+	Path string
+	// The kind of the difference.
+	Kind DiffKind
+	// The got and want values, or the properties of the values that were
+	// found to be different, e.g. for a LenDiff the lengths of the values
+	// and for a TypeDiff the reflect.Type of the values. Values that cannot
+	// be retrieved as interface{} values, i.e. those obtained from unexported
+	// struct fields, are represented by their textual representation.
+	Got, Want interface{}
+	// The kind specific details of the difference, or nil if there are
+	// none. A LenDiff carries a LenDifference, and a ValueDiff of two
+	// strings carries a StringDifference, that of two byte slices
+	// compared as a whole carries a BytesDifference, and the differences reported
+	// by Compare3 carry a ChangeDifference. A ValueDiff of two times of
+	// the same instant carries a TimeDifference. A SampleDiff carries a
+	// SampleDifference, and a SkipDiff the rule that omitted the field
+	// as a string.
+	Detail interface{}
+}
+
+// LenDifference is the Detail of a LenDiff.
+type LenDifference struct {
+	// The kind of the values, e.g. reflect.Slice.
+	Kind reflect.Kind
+	// The lengths of the got and want values.
+	Got, Want int
+}
+
+// StringDifference is the Detail of a ValueDiff of two strings. It holds the
+// byte range of the got string at which the two strings first differ. If the
+// got string is a prefix of the want string, Start and End equal its length.
+type StringDifference struct {
+	Start, End int
+}
+
+// BytesDifference is the Detail of a ValueDiff of two byte slices that are
+// compared as a whole, see Config.HexDump. It holds the offset of the first
+// byte at which the two slices differ.
+type BytesDifference struct {
+	Offset int
+}
+
+// SampleDifference is the Detail of a SampleDiff.
+type SampleDifference struct {
+	// The number of the compared elements and of all the elements
+	// of the collection.
+	Sampled, Total int
+	// The number of the compared elements that differ.
+	Differ int
+	// The estimated mismatch rate of the collection, i.e. Differ/Sampled.
+	Rate float64
+}
+
+// TimeDifference is the Detail of a ValueDiff of two times that represent the
+// same instant but differ in one of their other components, in which case the
+// Got and Want of the Difference are the textual representations of the
+// differing components.
+type TimeDifference struct {
+	// The component of the times that differs, i.e. either "location"
+	// or "monotonic clock reading".
+	Component string
+}
+
+// ChangeDifference is the Detail of the differences reported by Compare3.
+type ChangeDifference struct {
+	// The base value, or nil if the value is missing from the base.
+	Base interface{}
+}
+
+// differ is implemented by the errors of this package that represent
+// differences between two values.
+type differ interface {
+	differences() []Difference
+}
+
+// Differences returns the list of differences represented by the given error,
+// which is expected to be an error returned by Compare. If err is nil or if it
+// was not returned by Compare, the result will be nil.
+func Differences(err error) []Difference {
+	if d, ok := err.(differ); ok {
+		return d.differences()
+	}
+	return nil
+}
+
+// Mismatch is a single difference of a Diff.
+type Mismatch = Difference
+
+// Diff is the structured result of a comparison, see Config.Diff.
+type Diff struct {
+	// The differences found by the comparison, in the order in which
+	// they are reported by Compare.
+	Mismatches []Mismatch
+}
+
+// Diff compares the two given values just like Compare does and returns the
+// differences it found as a Diff that can be inspected programmatically, e.g.
+// by tooling that renders the differences on its own. The returned error is
+// the same as that returned by Compare, i.e. it is nil if the values are
+// equal, in which case the Diff has no Mismatches. Note that the Diff is only
+// a method, to use the DefaultConfig call DefaultConfig.Diff.
+func (conf Config) Diff(got, want interface{}) (*Diff, error) {
+	err := conf.Compare(got, want)
+	return &Diff{Mismatches: Differences(err)}, err
+}
+
+// Equal reports whether the Diff has no mismatches.
+func (d *Diff) Equal() bool {
+	return len(d.Mismatches) == 0
+}
+
+// Paths returns the paths of the mismatches.
+func (d *Diff) Paths() []string {
+	paths := make([]string, len(d.Mismatches))
+	for i, m := range d.Mismatches {
+		paths[i] = m.Path
+	}
+	return paths
+}
+
+// Filter returns a Diff with only those mismatches of d for which keep
+// returns true.
+func (d *Diff) Filter(keep func(m Mismatch) bool) *Diff {
+	out := &Diff{}
+	for _, m := range d.Mismatches {
+		if keep(m) {
+			out.Mismatches = append(out.Mismatches, m)
+		}
+	}
+	return out
+}
+
+// relpath returns the textual representation of the path without its root node.
+func (p path) relpath() string {
+	if len(p) > 0 {
+		if _, ok := p[0].(rootnode); ok {
+			p = p[1:]
+		}
+	}
+	return p.format(printer{})
+}
+
+// valueOf returns the interface{} value of v, or if that's not possible
+// the textual representation of v. If v is invalid the result is nil.
+func valueOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return printer{}.value("%v", v)
+}
+
+func (el *errorList) differences() (diffs []Difference) {
+	for _, err := range el.List {
+		diffs = append(diffs, Differences(err)...)
+	}
+	return diffs
+}
+
+func (err *validityError) differences() []Difference {
+	return []Difference{{err.path.relpath(), ValidityDiff, valueOf(err.got), valueOf(err.want), nil}}
+}
+
+func (err *typeError) differences() []Difference {
+	return []Difference{{err.path.relpath(), TypeDiff, err.got.Type(), err.want.Type(), nil}}
+}
+
+func (err *nilError) differences() []Difference {
+	return []Difference{{err.path.relpath(), NilDiff, valueOf(err.got), valueOf(err.want), nil}}
+}
+
+func (err *lenError) differences() []Difference {
+	g, w := err.got.Len(), err.want.Len()
+	return []Difference{{err.path.relpath(), LenDiff, g, w, LenDifference{err.want.Kind(), g, w}}}
+}
+
+func (err *elemError) differences() []Difference {
+	return []Difference{{err.path.relpath(), ElemDiff, valueOf(err.got), valueOf(err.want), nil}}
+}
+
+func (err *funcError) differences() []Difference {
+	return []Difference{{err.path.relpath(), FuncDiff, valueOf(err.got), valueOf(err.want), nil}}
+}
+
+func (err *timeError) differences() []Difference {
+	got, want := timeComponent(err.got, err.component), timeComponent(err.want, err.component)
+	return []Difference{{err.path.relpath(), ValueDiff, got, want, TimeDifference{err.component}}}
+}
+
+func (err *timeDeltaError) differences() []Difference {
+	return []Difference{{err.path.relpath(), ValueDiff, err.got, err.want, nil}}
+}
+
+func (err *floatError) differences() []Difference {
+	return []Difference{{err.path.relpath(), ValueDiff, valueOf(err.got), valueOf(err.want), nil}}
+}
+
+func (err *skipError) differences() []Difference {
+	return []Difference{{err.path.relpath(), SkipDiff, nil, nil, err.rule}}
+}
+
+func (err *identityError) differences() []Difference {
+	return []Difference{{err.path.relpath(), IdentityDiff, err.got.Pointer(), err.want.Pointer(), nil}}
+}
+
+func (err *cycleError) differences() []Difference {
+	var got, want interface{}
+	if err.got != nil {
+		got = err.got.relpath()
+	}
+	if err.want != nil {
+		want = err.want.relpath()
+	}
+	return []Difference{{err.path.relpath(), CycleDiff, got, want, nil}}
+}
+
+func (err *valueError) differences() []Difference {
+	got, want := err.got, err.want
+	if v, ok := got.(reflect.Value); ok {
+		got = valueOf(v)
+	}
+	if v, ok := want.(reflect.Value); ok {
+		want = valueOf(v)
+	}
+	return []Difference{{err.path.relpath(), ValueDiff, got, want, nil}}
+}
+
+func (err *zeroError) differences() []Difference {
+	return []Difference{{err.path.relpath(), ZeroDiff, err.got, err.want, nil}}
+}
+
+func (err *chainError) differences() []Difference {
+	var got, want error
+	if err.index < len(err.got) {
+		got = err.got[err.index]
+	}
+	if err.index < len(err.want) {
+		want = err.want[err.index]
+	}
+	return []Difference{{err.path.relpath(), ErrorChainDiff, got, want, nil}}
+}
+
+func (err *moreError) differences() []Difference {
+	return []Difference{{err.path.relpath(), MoreDiff, err.count, nil, nil}}
+}
+
+func (err *depthError) differences() []Difference {
+	return []Difference{{err.path.relpath(), DepthDiff, err.max, nil, nil}}
+}
+
+func (err *limitError) differences() []Difference {
+	return []Difference{{err.path.relpath(), MoreDiff, err.count, nil, nil}}
+}
+
+func (err *collectionError) differences() (diffs []Difference) {
+	for _, e := range err.errs {
+		diffs = append(diffs, Differences(e)...)
+	}
+	return diffs
+}
+
+// The fieldsError is a summary of other differences, it represents none itself.
+func (err *fieldsError) differences() []Difference {
+	return nil
+}
+
+func (err *stringError) differences() []Difference {
+	var detail interface{}
+	if d := sdiff(err.got, err.want); d != nil {
+		detail = StringDifference{d.start, d.end}
+	}
+	return []Difference{{err.path.relpath(), ValueDiff, err.got, err.want, detail}}
+}
+
+func (err *linesError) differences() []Difference {
+	return newStringError(err.got, err.want, err.path).differences()
+}
+
+func (err *bytesError) differences() []Difference {
+	detail := BytesDifference{bdiff(err.got, err.want)}
+	return []Difference{{err.path.relpath(), ValueDiff, err.got, err.want, detail}}
+}
+
+// jsonDifference is the JSON representation of a Difference.
+type jsonDifference struct {
+	Path    string          `json:"path"`
+	Kind    DiffKind        `json:"kind"`
+	Got     json.RawMessage `json:"got"`
+	Want    json.RawMessage `json:"want"`
+	Detail  json.RawMessage `json:"detail,omitempty"`
+	Case    string          `json:"case,omitempty"`
+	Warning bool            `json:"warning,omitempty"`
+	Message string          `json:"message"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The list is encoded as
+// an array of its differences, each of which is an object with the "path",
+// "kind", "got", "want", and "message" members and, if present, the "detail"
+// member. The differences of the named cases of CompareAll carry the name of
+// their case as "case", and the warnings, see WarnPaths, are marked by the
+// "warning" member. The message is the error's text without colors, and the
+// values that cannot be encoded as JSON are encoded as their text.
+func (el *errorList) MarshalJSON() ([]byte, error) {
+	pr := el.pr
+	pr.nocolor, pr.oneline = true, true
+	diffs := make([]jsonDifference, 0, len(el.List))
+	diffs = appendJSONDifferences(diffs, el, pr, "", false)
+	return json.Marshal(diffs)
+}
+
+// appendJSONDifferences appends the JSON representations of the differences of
+// the given error to diffs.
+func appendJSONDifferences(diffs []jsonDifference, err error, pr printer, name string, warning bool) []jsonDifference {
+	switch e := err.(type) {
+	case *errorList:
+		for _, err := range e.List {
+			diffs = appendJSONDifferences(diffs, err, pr, name, warning)
+		}
+		return diffs
+	case *caseError:
+		if name != "" {
+			return appendJSONDifferences(diffs, e.err, pr, name+"/"+e.name, warning)
+		}
+		return appendJSONDifferences(diffs, e.err, pr, e.name, warning)
+	case *warningError:
+		return appendJSONDifferences(diffs, e.err, pr, name, true)
+	}
+
+	msg := pr.error(err)
+	for _, d := range Differences(err) {
+		jd := jsonDifference{
+			Path:    d.Path,
+			Kind:    d.Kind,
+			Got:     jsonRaw(d.Got),
+			Want:    jsonRaw(d.Want),
+			Case:    name,
+			Warning: warning,
+			Message: msg,
+		}
+		if d.Detail != nil {
+			jd.Detail = jsonRaw(d.Detail)
+		}
+		diffs = append(diffs, jd)
+	}
+	return diffs
+}
+
+// jsonRaw returns the JSON encoding of v, or the encoding of its text if v
+// cannot be encoded. The types are encoded as their names.
+func jsonRaw(v interface{}) json.RawMessage {
+	if t, ok := v.(reflect.Type); ok {
+		v = t.String()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%v", v))
+	}
+	return data
+}
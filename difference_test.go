@@ -0,0 +1,146 @@
+package compare
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDifferences(t *testing.T) {
+	type T struct {
+		Name  string
+		Tags  []string
+		Count int
+		Any   interface{}
+	}
+
+	tests := []struct {
+		got, want interface{}
+		conf      Config
+		diffs     []Difference
+	}{{
+		got: T{Name: "a"}, want: T{Name: "a"},
+		diffs: nil,
+	}, {
+		got:  T{Name: "a", Tags: []string{"x"}, Count: 1, Any: 1},
+		want: T{Name: "b", Tags: []string{"x", "y"}, Count: 2, Any: "1"},
+		diffs: []Difference{
+			{Path: ".Name", Kind: ValueDiff, Got: "a", Want: "b", Detail: StringDifference{0, 1}},
+			{Path: ".Tags", Kind: LenDiff, Got: 1, Want: 2, Detail: LenDifference{reflect.Slice, 1, 2}},
+			{Path: ".Count", Kind: ValueDiff, Got: 1, Want: 2},
+			{Path: ".Any", Kind: TypeDiff, Got: rtof(1), Want: rtof("")},
+		},
+	}, {
+		got: "abc", want: "abx", diffs: []Difference{
+			{Path: "", Kind: ValueDiff, Got: "abc", Want: "abx", Detail: StringDifference{2, 3}},
+		},
+	}, {
+		got: "ab", want: "abc", diffs: []Difference{
+			{Path: "", Kind: ValueDiff, Got: "ab", Want: "abc", Detail: StringDifference{2, 2}},
+		},
+	}, {
+		got: map[int]bool{1: true}, want: map[int]bool{}, diffs: []Difference{
+			{Path: "", Kind: LenDiff, Got: 1, Want: 0, Detail: LenDifference{reflect.Map, 1, 0}},
+		},
+	}, {
+		got: []int{}, want: []int(nil),
+		diffs: []Difference{
+			{Path: "", Kind: NilDiff, Got: []int{}, Want: []int(nil)},
+		},
+	}, {
+		got: map[string]int{"a": 1}, want: map[string]int{"b": 1},
+		diffs: []Difference{
+			{Path: "[b]", Kind: ValidityDiff, Got: nil, Want: 1},
+		},
+	}, {
+		got: []int{1, 2, 3}, want: []int{4, 5, 6},
+		conf: Config{MaxDiffsPerCollection: 1, AggregateCollectionErrors: true},
+		diffs: []Difference{
+			{Path: "[0]", Kind: ValueDiff, Got: 1, Want: 4},
+			{Path: "", Kind: MoreDiff, Got: 2, Want: nil},
+		},
+	}}
+
+	for _, tt := range tests {
+		diffs := Differences(tt.conf.Compare(tt.got, tt.want))
+		if !reflect.DeepEqual(diffs, tt.diffs) {
+			t.Errorf("Differences(Compare(%v, %v)) got=%#v, want=%#v", tt.got, tt.want, diffs, tt.diffs)
+		}
+	}
+}
+
+func TestConfigDiff(t *testing.T) {
+	type T struct {
+		Name  string
+		Tags  []string
+		Count int
+	}
+
+	d, err := DefaultConfig.Diff(T{Name: "a", Tags: []string{"x"}}, T{Name: "a", Tags: []string{"x"}})
+	if err != nil || !d.Equal() {
+		t.Errorf("Diff() of equal values got=(%+v, %v), want no mismatches", d, err)
+	}
+
+	d, err = DefaultConfig.Diff(T{Name: "a", Tags: []string{"x"}, Count: 1}, T{Name: "b", Tags: []string{"y"}, Count: 2})
+	if err == nil || d.Equal() {
+		t.Fatalf("Diff() of different values got=(%+v, %v), want mismatches", d, err)
+	}
+	if got, want := d.Paths(), []string{".Name", ".Tags[0]", ".Count"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff().Paths() got=%v, want=%v", got, want)
+	}
+	if !reflect.DeepEqual(d.Mismatches, Differences(err)) {
+		t.Errorf("Diff().Mismatches got=%+v, want=%+v", d.Mismatches, Differences(err))
+	}
+
+	strs := d.Filter(func(m Mismatch) bool {
+		_, ok := m.Got.(string)
+		return ok
+	})
+	if got, want := strs.Paths(), []string{".Name", ".Tags[0]"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff().Filter().Paths() got=%v, want=%v", got, want)
+	}
+	if len(d.Mismatches) != 3 {
+		t.Errorf("Filter modified the Diff: %+v", d)
+	}
+}
+
+func TestErrorListMarshalJSON(t *testing.T) {
+	type T struct {
+		Name  string
+		Tags  []string
+		Score float64
+		Any   interface{}
+		Note  string `cmp:"warn"`
+	}
+
+	conf := Config{ObserveFieldTag: "cmp"}
+	err := conf.Compare(
+		T{Name: "a", Tags: []string{"x"}, Score: math.NaN(), Any: 1, Note: "n"},
+		T{Name: "b", Tags: []string{"x", "y"}, Score: 1, Any: "1", Note: "m"},
+	)
+	data, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	want := `[` +
+		`{"path":".Name","kind":"value","got":"a","want":"b","detail":{"Start":0,"End":1},"message":"- (compare.T).Name: Value mismatch; got=\"a\", want=\"b\"; differs at byte 0 (rune 0)"},` +
+		`{"path":".Tags","kind":"length","got":1,"want":2,"detail":{"Kind":23,"Got":1,"Want":2},"message":"- (compare.T).Tags: Length of slice mismatch; got=1, want=2"},` +
+		`{"path":".Score","kind":"value","got":"NaN","want":1,"message":"- (compare.T).Score: Value mismatch; got=NaN, want=1"},` +
+		`{"path":".Any","kind":"type","got":"int","want":"string","message":"- (compare.T).Any: Type mismatch; got=int, want=string"},` +
+		`{"path":".Note","kind":"value","got":"n","want":"m","detail":{"Start":0,"End":1},"warning":true,"message":"- (compare.T).Note: Value mismatch; got=\"n\", want=\"m\"; differs at byte 0 (rune 0)"}` +
+		`]`
+	if string(data) != want {
+		t.Errorf("MarshalJSON got:\n%s\nwant:\n%s", data, want)
+	}
+
+	err = CompareAll([]Case{{Name: "ints", Got: 1, Want: 2}, {Name: "ok", Got: 1, Want: 1}})
+	data, jerr = json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	want = `[{"path":"","kind":"value","got":1,"want":2,"case":"ints","message":"- (int): Value mismatch; got=1, want=2"}]`
+	if string(data) != want {
+		t.Errorf("MarshalJSON of cases got:\n%s\nwant:\n%s", data, want)
+	}
+}
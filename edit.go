@@ -0,0 +1,99 @@
+package compare
+
+// EditKind identifies the kind of an Edit.
+type EditKind string
+
+const (
+	// The element is present in both slices.
+	KeepEdit EditKind = "keep"
+	// The element of the second slice is missing from the first one.
+	InsertEdit EditKind = "insert"
+	// The element of the first slice is missing from the second one.
+	DeleteEdit EditKind = "delete"
+)
+
+// Edit is a single step of an edit script that transforms one slice into
+// another, see DiffSlices.
+type Edit struct {
+	// The kind of the edit.
+	Kind EditKind
+	// The index of the element in the first slice, or -1 for InsertEdits.
+	A int
+	// The index of the element in the second slice, or -1 for DeleteEdits.
+	B int
+}
+
+// DiffSlices returns the shortest edit script that transforms the slice a into
+// the slice b, computed using the Myers diff algorithm. The equal function
+// reports whether the ith element of a and the jth element of b are equal.
+// The script has one Edit per element of the two slices, the elements that
+// are present in both slices are represented by a single KeepEdit, and it is
+// ordered such that the indexes of each slice are increasing. Of the scripts
+// of the same length the one whose deletions precede its insertions is chosen.
+func DiffSlices[T any](a, b []T, equal func(i, j int) bool) []Edit {
+	return diffEdits(len(a), len(b), equal)
+}
+
+// diffEdits returns the shortest edit script for two sequences of the lengths
+// n and m, see DiffSlices.
+func diffEdits(n, m int, equal func(i, j int) bool) []Edit {
+	max := n + m
+	v := make([]int, 2*max+2) // v[max+k]: the furthest x reached on diagonal k
+	var trace [][]int         // trace[d]: the diagonals [-d, d] before the step d
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[max-d:max+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1] // move down, i.e. insert
+			} else {
+				x = v[max+k-1] + 1 // move right, i.e. delete
+			}
+			y := x - k
+			for x < n && y < m && equal(x, y) {
+				x, y = x+1, y+1
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack reconstructs the edit script from the trace of the Myers algorithm.
+func backtrack(trace [][]int, n, m int) []Edit {
+	edits := make([]Edit, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prevX, prevY := 0, 0
+		if d > 0 {
+			v, k := trace[d], x-y
+			prevK := k - 1
+			if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+				prevK = k + 1
+			}
+			prevX = v[d+prevK]
+			prevY = prevX - prevK
+		}
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			edits = append(edits, Edit{KeepEdit, x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, Edit{InsertEdit, -1, y - 1})
+			} else {
+				edits = append(edits, Edit{DeleteEdit, x - 1, -1})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
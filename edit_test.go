@@ -0,0 +1,101 @@
+package compare
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDiffSlices(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits []Edit
+	}{{
+		a: "", b: "", edits: []Edit{},
+	}, {
+		a: "abc", b: "abc",
+		edits: []Edit{{KeepEdit, 0, 0}, {KeepEdit, 1, 1}, {KeepEdit, 2, 2}},
+	}, {
+		a: "", b: "ab",
+		edits: []Edit{{InsertEdit, -1, 0}, {InsertEdit, -1, 1}},
+	}, {
+		a: "ab", b: "",
+		edits: []Edit{{DeleteEdit, 0, -1}, {DeleteEdit, 1, -1}},
+	}, {
+		a: "abc", b: "axc",
+		edits: []Edit{{KeepEdit, 0, 0}, {DeleteEdit, 1, -1}, {InsertEdit, -1, 1}, {KeepEdit, 2, 2}},
+	}, {
+		a: "abcd", b: "acbd",
+		edits: []Edit{{KeepEdit, 0, 0}, {DeleteEdit, 1, -1}, {KeepEdit, 2, 1}, {InsertEdit, -1, 2}, {KeepEdit, 3, 3}},
+	}}
+
+	for _, tt := range tests {
+		a, b := []byte(tt.a), []byte(tt.b)
+		edits := DiffSlices(a, b, func(i, j int) bool { return a[i] == b[j] })
+		if !reflect.DeepEqual(edits, tt.edits) {
+			t.Errorf("DiffSlices(%q, %q) got=%v, want=%v", tt.a, tt.b, edits, tt.edits)
+		}
+	}
+
+	// the scripts are valid and of the length of the shortest script
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		a, b := randomLines(rnd), randomLines(rnd)
+		edits := DiffSlices(a, b, func(i, j int) bool { return a[i] == b[j] })
+
+		var i, j, keep int
+		for _, e := range edits {
+			switch e.Kind {
+			case KeepEdit:
+				if e.A != i || e.B != j || a[i] != b[j] {
+					t.Fatalf("DiffSlices(%q, %q) invalid keep %v", a, b, e)
+				}
+				i, j, keep = i+1, j+1, keep+1
+			case DeleteEdit:
+				if e.A != i {
+					t.Fatalf("DiffSlices(%q, %q) invalid delete %v", a, b, e)
+				}
+				i++
+			case InsertEdit:
+				if e.B != j {
+					t.Fatalf("DiffSlices(%q, %q) invalid insert %v", a, b, e)
+				}
+				j++
+			}
+		}
+		if i != len(a) || j != len(b) {
+			t.Fatalf("DiffSlices(%q, %q) incomplete script %v", a, b, edits)
+		}
+		if lcs := lcsLen(a, b); keep != lcs {
+			t.Fatalf("DiffSlices(%q, %q) kept %d elements, want %d", a, b, keep, lcs)
+		}
+	}
+}
+
+func randomLines(rnd *rand.Rand) []string {
+	lines := make([]string, rnd.Intn(12))
+	for i := range lines {
+		lines[i] = string(rune('a' + rnd.Intn(4)))
+	}
+	return lines
+}
+
+// lcsLen returns the length of the longest common subsequence of a and b.
+func lcsLen(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else if dp[i+1][j] > dp[i][j+1] {
+				dp[i][j] = dp[i+1][j]
+			} else {
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+	return dp[0][0]
+}
@@ -0,0 +1,33 @@
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// CompareEncoded is a wrapper around DefaultConfig.CompareEncoded.
+func CompareEncoded(v interface{}, enc func(v interface{}) ([]byte, error), dec func(data []byte, v interface{}) error) error {
+	return DefaultConfig.CompareEncoded(v, enc, dec)
+}
+
+// CompareEncoded checks that the given value survives a round trip through
+// an encoding. It encodes v using enc, decodes the result using dec into a
+// new value of v's type, and compares the decoded value, as got, to v, as
+// want. Any differences therefore point to the parts of the value that were
+// lost or altered by the round trip. The signatures of enc and dec match
+// those of, e.g., json.Marshal and json.Unmarshal.
+func (conf Config) CompareEncoded(v interface{}, enc func(v interface{}) ([]byte, error), dec func(data []byte, v interface{}) error) error {
+	if v == nil {
+		return errors.New("compare: cannot round trip a nil value")
+	}
+	data, err := enc(v)
+	if err != nil {
+		return fmt.Errorf("compare: failed to encode value: %w", err)
+	}
+	out := reflect.New(reflect.TypeOf(v))
+	if err := dec(data, out.Interface()); err != nil {
+		return fmt.Errorf("compare: failed to decode value: %w", err)
+	}
+	return conf.Compare(out.Elem().Interface(), v)
+}
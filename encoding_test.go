@@ -0,0 +1,63 @@
+package compare
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCompareEncoded(t *testing.T) {
+	type T struct {
+		ID     int
+		Name   string `json:"-"`
+		Labels map[string]string
+		Count  *int
+	}
+	zero := 0
+
+	gobEnc := func(v interface{}) ([]byte, error) {
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(v)
+		return buf.Bytes(), err
+	}
+	gobDec := func(data []byte, v interface{}) error {
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+	}
+
+	tests := []struct {
+		v    interface{}
+		enc  func(v interface{}) ([]byte, error)
+		dec  func(data []byte, v interface{}) error
+		want string
+	}{{
+		v: T{ID: 1, Labels: map[string]string{"a": "b"}}, enc: json.Marshal, dec: json.Unmarshal,
+		want: "",
+	}, {
+		v: T{ID: 1, Name: "x"}, enc: json.Marshal, dec: json.Unmarshal,
+		want: "- (compare.T).Name: Value mismatch; got=\"\", want=\"x\"; differs at byte 0 (rune 0)\n",
+	}, {
+		v: &T{ID: 1, Name: "x"}, enc: gobEnc, dec: gobDec,
+		want: "",
+	}, {
+		v: T{ID: 1, Count: &zero}, enc: gobEnc, dec: gobDec,
+		want: "- (compare.T).Count: Validity mismatch; got=INVALID, want=VALID\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(CompareEncoded(tt.v, tt.enc, tt.dec)); got != tt.want {
+			t.Errorf("#%d: CompareEncoded got:\n%s\nwant:\n%s", i, got, tt.want)
+		}
+	}
+
+	failed := errors.New("failed")
+	enc := func(v interface{}) ([]byte, error) { return nil, failed }
+	if err := CompareEncoded(1, enc, json.Unmarshal); !errors.Is(err, failed) {
+		t.Errorf("CompareEncoded got=%v, want an encoding error", err)
+	}
+	dec := func(data []byte, v interface{}) error { return failed }
+	if err := CompareEncoded(1, json.Marshal, dec); !errors.Is(err, failed) {
+		t.Errorf("CompareEncoded got=%v, want a decoding error", err)
+	}
+}
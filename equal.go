@@ -0,0 +1,315 @@
+package compare
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// EqualNoReport is a wrapper around DefaultConfig.EqualNoReport.
+func EqualNoReport(got, want interface{}) bool {
+	return DefaultConfig.EqualNoReport(got, want)
+}
+
+// EqualNoReport reports whether the two given values are equal according to
+// the same rules as those used by Compare, however, unlike Compare, it does
+// not produce a report of the differences. It is intended for hot paths
+// where the values are expected to be equal most of the time and it does
+// not allocate any memory when the values are equal, unless the values
+// contain maps or channels, or unless they are deeply nested graphs of
+// pointers.
+//
+// The fast path is used only if none of the options that affect the result
+// of the comparison, except for Strict, are set, otherwise EqualNoReport
+// falls back to Compare.
+func (conf Config) EqualNoReport(got, want interface{}) bool {
+	if !conf.fastEqual() {
+		return conf.Compare(got, want) == nil
+	}
+
+	if m, ok := want.(Matcher); ok && !conf.Strict {
+		return m.Match(got)
+	}
+	eq := equality{strict: conf.Strict}
+	if !eq.equal(reflect.ValueOf(got), reflect.ValueOf(want)) {
+		return false
+	}
+	if eq.mispaired {
+		// the values may have cycles of different structures,
+		// which only the comparison itself can tell apart from
+		// the values that are merely shared differently
+		return conf.Compare(got, want) == nil
+	}
+	return true
+}
+
+// fastEqual reports whether the Config can be handled by the EqualNoReport
+// fast path. The options that affect only the reporting of the errors are
+// not relevant to EqualNoReport.
+func (conf Config) fastEqual() bool {
+	if conf.Strict {
+		return true
+	}
+	return !conf.IgnoreArrayOrder &&
+		!conf.IgnoreChanOrder &&
+		!conf.CompareChanIdentity &&
+		len(conf.ObserveFieldTag) == 0 &&
+		len(conf.IgnoreFieldNames) == 0 &&
+		conf.FieldFilter == nil &&
+		!conf.IgnoreUnexported &&
+		len(conf.IgnoreUnexportedIn) == 0 &&
+		!conf.PublicView &&
+		!conf.AuditSkippedFields &&
+		len(conf.Comparers) == 0 &&
+		len(conf.Projections) == 0 &&
+		len(conf.SortSlices) == 0 &&
+		!conf.UseEqualMethod &&
+		len(conf.FuncArgs) == 0 &&
+		len(conf.IgnoredMapKeys) == 0 &&
+		len(conf.IgnoredMapKeysAt) == 0 &&
+		len(conf.IgnorePaths) == 0 &&
+		len(conf.PathRules) == 0 &&
+		!conf.CompareTimeLocation &&
+		!conf.CompareTimeMonotonic &&
+		conf.TimeTolerance <= 0 &&
+		!conf.IgnoreTimeZone &&
+		!conf.LooseNumericTypes &&
+		conf.FloatTolerance <= 0 &&
+		conf.FloatRelTolerance <= 0 &&
+		!conf.EquateNaNs &&
+		!conf.IgnorePointerDepth &&
+		!conf.AutoDeref &&
+		!conf.AnonymousWantStructs &&
+		!conf.IterateContainers &&
+		conf.SampleMinLen <= 0 &&
+		conf.MaxDepth <= 0 &&
+		!conf.ShapeOnly &&
+		!conf.CompareErrorChains
+}
+
+// visitDepth is the depth of the comparison at which EqualNoReport starts
+// to record visits. A cyclic graph of values will be, without visits tracking,
+// recursed into indefinitely, therefore any cycle will sooner or later pass
+// the visitDepth and the visits recorded beyond it will terminate it.
+const visitDepth = 32
+
+// equality holds the state of the EqualNoReport fast path.
+type equality struct {
+	strict bool
+	depth  int
+	// The first few visits are recorded in the array, the rest in the map.
+	visits  [8]visit
+	nvisits int
+	more    map[visit]struct{}
+	// The got and want addresses of the visits as they were paired up, the
+	// first few are recorded in the array, the rest in the maps.
+	pairs  [8]visit
+	npairs int
+	gotTo  map[ancestor]unsafe.Pointer
+	wantTo map[ancestor]unsafe.Pointer
+	// set if an address was paired up with more than one address
+	mispaired bool
+}
+
+// pair records the pairing of the got and want addresses of the visit v, which
+// is expected to not be normalized, and checks whether either of the addresses
+// was already paired up with a different address, in which case the graphs of
+// the values may have cycles of different structures, see enterCycle.
+func (eq *equality) pair(v visit) {
+	if eq.mispaired {
+		return
+	}
+	for i := 0; i < eq.npairs; i++ {
+		if e := eq.pairs[i]; e.typ == v.typ && (e.got == v.got) != (e.want == v.want) {
+			eq.mispaired = true
+			return
+		}
+	}
+	g, w := ancestor{v.got, v.typ}, ancestor{v.want, v.typ}
+	if x, ok := eq.gotTo[g]; ok && x != v.want {
+		eq.mispaired = true
+		return
+	}
+	if x, ok := eq.wantTo[w]; ok && x != v.got {
+		eq.mispaired = true
+		return
+	}
+
+	if eq.npairs < len(eq.pairs) {
+		eq.pairs[eq.npairs] = v
+		eq.npairs++
+		return
+	}
+	if eq.gotTo == nil {
+		eq.gotTo, eq.wantTo = make(map[ancestor]unsafe.Pointer), make(map[ancestor]unsafe.Pointer)
+	}
+	eq.gotTo[g], eq.wantTo[w] = v.want, v.got
+}
+
+// visited reports whether the visit v has already been recorded and if
+// it hasn't it records it.
+func (eq *equality) visited(v visit) bool {
+	for i := 0; i < eq.nvisits; i++ {
+		if eq.visits[i] == v {
+			return true
+		}
+	}
+	if _, ok := eq.more[v]; ok {
+		return true
+	}
+
+	if eq.nvisits < len(eq.visits) {
+		eq.visits[eq.nvisits] = v
+		eq.nvisits++
+		return false
+	}
+	if eq.more == nil {
+		eq.more = make(map[visit]struct{})
+	}
+	eq.more[v] = struct{}{}
+	return false
+}
+
+// equal mirrors the behaviour of Config.compare without the reporting.
+func (eq *equality) equal(got, want reflect.Value) bool {
+	if m, ok := matcherOf(want); ok && !eq.strict {
+		return m.Match(matchValue(got))
+	}
+	if !got.IsValid() || !want.IsValid() {
+		return got.IsValid() == want.IsValid()
+	}
+	if got.Type() != want.Type() {
+		return false
+	}
+
+	eq.depth++
+	defer func() { eq.depth-- }()
+
+	if eq.depth > visitDepth && got.CanAddr() && want.CanAddr() && (Config{}).hard(got.Kind()) {
+		gotAddr := unsafe.Pointer(got.UnsafeAddr())
+		wantAddr := unsafe.Pointer(want.UnsafeAddr())
+		if !eq.strict {
+			eq.pair(visit{gotAddr, wantAddr, got.Type()})
+		}
+		if uintptr(gotAddr) > uintptr(wantAddr) {
+			gotAddr, wantAddr = wantAddr, gotAddr
+		}
+		if eq.visited(visit{gotAddr, wantAddr, got.Type()}) {
+			return true
+		}
+	}
+
+	switch got.Kind() {
+	case reflect.Array:
+		return eq.equalElems(got, want)
+	case reflect.Slice:
+		if got.Pointer() == want.Pointer() && got.Len() == want.Len() {
+			return true
+		}
+		if got.IsNil() != want.IsNil() {
+			return false
+		}
+		return eq.equalElems(got, want)
+	case reflect.Interface:
+		if got.IsNil() != want.IsNil() {
+			return false
+		}
+		return eq.equal(got.Elem(), want.Elem())
+	case reflect.Ptr:
+		if got.Pointer() == want.Pointer() {
+			return true
+		}
+		return eq.equal(got.Elem(), want.Elem())
+	case reflect.Struct:
+		if !eq.strict && structIsTime(got) && got.CanInterface() {
+			return timeOf(got).Equal(timeOf(want))
+		}
+		for i, n := 0, want.NumField(); i < n; i++ {
+			if !eq.strict && isLockType(got.Field(i).Type()) {
+				continue
+			}
+			if !eq.equal(got.Field(i), want.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if got.Pointer() == want.Pointer() {
+			return true
+		}
+		if got.IsNil() != want.IsNil() || got.Len() != want.Len() {
+			return false
+		}
+		for it := want.MapRange(); it.Next(); {
+			valGot := got.MapIndex(it.Key())
+			if !valGot.IsValid() || !eq.equal(valGot, it.Value()) {
+				return false
+			}
+		}
+		return true
+	case reflect.Func:
+		return got.IsNil() && want.IsNil()
+	case reflect.String:
+		return got.String() == want.String()
+	case reflect.Chan:
+		if eq.strict {
+			return got.Pointer() == want.Pointer()
+		}
+		if got.Len() != want.Len() {
+			return false
+		}
+		// All of the elements are drained, just like
+		// in Config.compareChan, even after a mismatch is found.
+		equal := true
+		for i, n := 0, want.Len(); i < n; i++ {
+			ithGot, _ := got.Recv()
+			ithWant, _ := want.Recv()
+			equal = eq.equal(ithGot, ithWant) && equal
+		}
+		return equal
+	}
+	return primitiveEqual(got, want)
+}
+
+func (eq *equality) equalElems(got, want reflect.Value) bool {
+	if got.Len() != want.Len() {
+		return false
+	}
+	for i, n := 0, want.Len(); i < n; i++ {
+		if !eq.equal(got.Index(i), want.Index(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// primitiveEqual reports whether the two given values of a primitive kind
+// are equal, the values are compared without being boxed into interfaces.
+func primitiveEqual(got, want reflect.Value) bool {
+	switch got.Kind() {
+	case reflect.Bool:
+		return got.Bool() == want.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return got.Int() == want.Int()
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return got.Uint() == want.Uint()
+	case reflect.Float32, reflect.Float64:
+		return got.Float() == want.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return got.Complex() == want.Complex()
+	case reflect.String:
+		return got.String() == want.String()
+	case reflect.UnsafePointer:
+		return got.Pointer() == want.Pointer()
+	}
+	return valueInterface(got) == valueInterface(want)
+}
+
+// timeOf returns the time.Time held by v, if v is addressable then the
+// value is read directly to avoid the allocation of Interface.
+func timeOf(v reflect.Value) time.Time {
+	if v.CanAddr() {
+		return *(*time.Time)(unsafe.Pointer(v.UnsafeAddr()))
+	}
+	return v.Interface().(time.Time)
+}
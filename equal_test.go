@@ -0,0 +1,222 @@
+package compare
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEqualNoReport(t *testing.T) {
+	for _, conf := range []Config{{}, {Strict: true}, {ObserveFieldTag: "cmp"}, {AuditSkippedFields: true}} {
+		tests := append([]CompareTest{
+			{a: chanint(1, 2), b: chanint(1, 2)},
+			{a: chanint(1, 2), b: chanint(1, 3)},
+			{a: Tagged{"abc", "foo", ""}, b: Tagged{"def", "bar", ""}},
+			{a: tm{now1}, b: tm{now2}},
+		}, compareTests...)
+
+		for _, test := range tests {
+			if test.b == (self{}) {
+				test.b = test.a
+			}
+
+			// NOTE: channels are drained by the comparison, compare
+			// only those test cases that don't contain any channels.
+			if _, ok := test.a.(chan int); ok {
+				continue
+			}
+
+			want := conf.Compare(test.a, test.b) == nil
+			if got := conf.EqualNoReport(test.a, test.b); got != want {
+				t.Errorf("%+v: EqualNoReport(%v, %v) got=%t, want=%t", conf, test.a, test.b, got, want)
+			}
+		}
+	}
+}
+
+type benchItem struct {
+	ID      int
+	Name    string
+	Price   float64
+	Tags    []string
+	Created time.Time
+	Parent  *benchItem
+}
+
+func benchItems(n int) []*benchItem {
+	items := make([]*benchItem, n)
+	for i := range items {
+		items[i] = &benchItem{
+			ID:      i,
+			Name:    "item",
+			Price:   float64(i) * 1.5,
+			Tags:    []string{"a", "b", "c"},
+			Created: time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+			Parent:  &benchItem{ID: -i},
+		}
+	}
+	return items
+}
+
+func TestEqualNoReportAllocs(t *testing.T) {
+	got, want := benchItems(10), benchItems(10)
+	ints1, ints2 := make([]int, 100), make([]int, 100)
+
+	tests := []struct {
+		a, b interface{}
+	}{
+		{a: got, b: want},
+		{a: &ints1, b: &ints2},
+		{a: [3]string{"a", "b", "c"}, b: [3]string{"a", "b", "c"}},
+		{a: &loop1, b: &loop2},
+	}
+	for _, tt := range tests {
+		allocs := testing.AllocsPerRun(100, func() {
+			if !EqualNoReport(tt.a, tt.b) {
+				t.Fatalf("EqualNoReport(%v, %v) got=false, want=true", tt.a, tt.b)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("EqualNoReport(%T) allocs got=%v, want=0", tt.a, allocs)
+		}
+	}
+}
+
+func BenchmarkCompare(b *testing.B) {
+	got, want := benchItems(1000), benchItems(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Compare(got, want); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEqualNoReport(b *testing.B) {
+	got, want := benchItems(1000), benchItems(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !EqualNoReport(got, want) {
+			b.Fatal("not equal")
+		}
+	}
+}
+
+// benchGraph returns a slice of n nodes each of which points to its parent
+// and to the maps shared by all of the nodes, i.e. a graph in which the
+// same values are reachable through many paths.
+func benchGraph(n int) []*benchNode {
+	shared := map[string]*benchNode{"root": {ID: -1}}
+	nodes := make([]*benchNode, n)
+	for i := range nodes {
+		nodes[i] = &benchNode{ID: i, Shared: shared}
+		if i > 0 {
+			nodes[i].Parent = nodes[i-1]
+		}
+	}
+	return nodes
+}
+
+type benchNode struct {
+	ID     int
+	Parent *benchNode
+	Shared map[string]*benchNode
+}
+
+func BenchmarkCompareGraph(b *testing.B) {
+	got, want := benchGraph(100), benchGraph(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Compare(got, want); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompareIgnoreArrayOrder(b *testing.B) {
+	got, want := benchItems(100), benchItems(100)
+	for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+		want[i], want[j] = want[j], want[i]
+	}
+	conf := Config{IgnoreArrayOrder: true}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := conf.Compare(got, want); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestComparePrimitiveSlices(t *testing.T) {
+	type T struct {
+		b []byte
+		s []string
+	}
+
+	tests := []struct {
+		a, b   interface{}
+		reason string
+	}{{
+		a: []byte("abc"), b: []byte("abc"), reason: "",
+	}, {
+		a: []byte("abc"), b: []byte("abd"), reason: "- ([]uint8)[2]: Value mismatch; got=99, want=100",
+	}, {
+		a: []string{"a", "b"}, b: []string{"a", "b"}, reason: "",
+	}, {
+		a: []string{"a", "b"}, b: []string{"a", "c"}, reason: `- ([]string)[1]: Value mismatch; got="b", want="c"; differs at byte 0 (rune 0)`,
+	}, {
+		a: T{b: []byte("x"), s: []string{"y"}}, b: T{b: []byte("x"), s: []string{"y"}}, reason: "",
+	}, {
+		a: T{b: []byte("x")}, b: T{b: []byte("z")}, reason: "- (compare.T).b[0]: Value mismatch; got=120, want=122",
+	}}
+
+	for _, tt := range tests {
+		if _, reason := Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+	}
+}
+
+func TestCompareNumericSliceAllocs(t *testing.T) {
+	ints1, ints2 := make([]int64, 1000), make([]int64, 1000)
+	for i := range ints1 {
+		ints1[i], ints2[i] = int64(i), int64(i)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := Compare(ints1, ints2); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// the allocations of the comparison itself, none per element
+	if allocs > 10 {
+		t.Errorf("Compare([]int64) allocs got=%v, want at most 10", allocs)
+	}
+}
+
+func BenchmarkCompareNumericSlice(b *testing.B) {
+	got, want := make([]float64, 10000), make([]float64, 10000)
+	for i := range got {
+		got[i], want[i] = float64(i), float64(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Compare(got, want); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompareBytes(b *testing.B) {
+	got, want := make([]byte, 1<<16), make([]byte, 1<<16)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Compare(got, want); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
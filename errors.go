@@ -1,9 +1,15 @@
 package compare
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -27,6 +33,7 @@ const (
 
 type errorList struct {
 	List []error
+	pr   printer
 }
 
 func (el *errorList) add(err error) {
@@ -40,20 +47,50 @@ func (el *errorList) err() error {
 	return nil
 }
 
-func (el *errorList) Error() (res string) {
-	for _, err := range el.List {
-		res += fmt.Sprintf("%s\n", err)
+func (el *errorList) Error() string {
+	return el.pr.list(el.List)
+}
+
+// Format implements fmt.Formatter. The %+v verb renders the composite values
+// of the errors in full instead of summarizing them.
+func (el *errorList) Format(f fmt.State, verb rune) {
+	pr := el.pr
+	pr.full = verb == 'v' && f.Flag('+')
+	formatError(f, verb, pr.list(el.List))
+}
+
+// formatError writes the rendered error s to f according to the given verb.
+func formatError(f fmt.State, verb rune, s string) {
+	if verb == 'q' {
+		s = strconv.Quote(s)
 	}
-	return strings.TrimRight(res, "\n")
+	io.WriteString(f, s)
 }
 
 type validityError struct {
 	got  reflect.Value
 	want reflect.Value
 	path path
+	// If valid, the key that is missing from one of the two maps, which
+	// is the reason why one of the values is invalid.
+	key reflect.Value
 }
 
 func (err *validityError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *validityError) format(pr printer) string {
+	if err.key.IsValid() {
+		missing, other, v, color := "got", "want", err.want, wantColor
+		if !err.want.IsValid() {
+			missing, other, v, color = "want", "got", err.got, gotColor
+		}
+		key := pr.value("%v", err.key)
+		val := pr.color(color, pr.value("%v", v))
+		return fmt.Sprintf("%s: Key %s missing in %s; %s=%s", err.path.format(pr), key, missing, other, val)
+	}
+
 	got, want := "VALID", "VALID"
 	if !err.got.IsValid() {
 		got = "INVALID"
@@ -61,9 +98,9 @@ func (err *validityError) Error() string {
 	if !err.want.IsValid() {
 		want = "INVALID"
 	}
-	got = gotColor + got + stopColor
-	want = wantColor + want + stopColor
-	return fmt.Sprintf("%s: Validity mismatch; got=%s, want=%s", err.path, got, want)
+	got = pr.color(gotColor, got)
+	want = pr.color(wantColor, want)
+	return fmt.Sprintf("%s: Validity mismatch; got=%s, want=%s", err.path.format(pr), got, want)
 }
 
 type typeError struct {
@@ -73,9 +110,13 @@ type typeError struct {
 }
 
 func (err *typeError) Error() string {
-	got := gotColor + err.got.Type().String() + stopColor
-	want := wantColor + err.want.Type().String() + stopColor
-	return fmt.Sprintf("%s: Type mismatch; got=%s, want=%s", err.path, got, want)
+	return err.format(printer{})
+}
+
+func (err *typeError) format(pr printer) string {
+	got := pr.color(gotColor, pr.text(err.got.Type().String()))
+	want := pr.color(wantColor, pr.text(err.want.Type().String()))
+	return fmt.Sprintf("%s: Type mismatch; got=%s, want=%s", err.path.format(pr), got, want)
 }
 
 type nilError struct {
@@ -85,16 +126,27 @@ type nilError struct {
 }
 
 func (err *nilError) Error() string {
+	return err.format(printer{})
+}
+
+// Format implements fmt.Formatter. The %+v verb renders the non-nil value
+// in full instead of summarizing it.
+func (err *nilError) Format(f fmt.State, verb rune) {
+	pr := printer{full: verb == 'v' && f.Flag('+')}
+	formatError(f, verb, err.format(pr))
+}
+
+func (err *nilError) format(pr printer) string {
 	got, want := "<nil>", "<nil>"
 	if !err.got.IsNil() {
-		got = fmt.Sprintf("%#v", err.got)
+		got = pr.composite(err.got) + pr.addr(err.got)
 	}
 	if !err.want.IsNil() {
-		want = fmt.Sprintf("%#v", err.want)
+		want = pr.composite(err.want) + pr.addr(err.want)
 	}
-	got = gotColor + got + stopColor
-	want = wantColor + want + stopColor
-	return fmt.Sprintf("%s: Nil mismatch; got=%s, want=%s", err.path, got, want)
+	got = pr.color(gotColor, got)
+	want = pr.color(wantColor, want)
+	return fmt.Sprintf("%s: Nil mismatch; got=%s, want=%s", err.path.format(pr), got, want)
 }
 
 type lenError struct {
@@ -104,10 +156,36 @@ type lenError struct {
 }
 
 func (err *lenError) Error() string {
-	got := gotColor + fmt.Sprintf("%d", err.got.Len()) + stopColor
-	want := wantColor + fmt.Sprintf("%d", err.want.Len()) + stopColor
+	return err.format(printer{})
+}
+
+func (err *lenError) format(pr printer) string {
+	got := pr.color(gotColor, fmt.Sprintf("%d", err.got.Len())+pr.addr(err.got))
+	want := pr.color(wantColor, fmt.Sprintf("%d", err.want.Len())+pr.addr(err.want))
 	kind := err.want.Kind()
-	return fmt.Sprintf("%s: Length of %s mismatch; got=%s, want=%s", err.path, kind, got, want)
+	return fmt.Sprintf("%s: Length of %s mismatch; got=%s, want=%s", err.path.format(pr), kind, got, want)
+}
+
+// elemError represents an element of an array or slice that is either missing
+// in got, in which case got is invalid, or unexpected in got, in which case
+// want is invalid, see Config.AlignElements.
+type elemError struct {
+	got  reflect.Value
+	want reflect.Value
+	path path
+}
+
+func (err *elemError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *elemError) format(pr printer) string {
+	if !err.got.IsValid() {
+		want := pr.color(wantColor, pr.value("%v", err.want))
+		return fmt.Sprintf("%s: Element missing in got; want=%s", err.path.format(pr), want)
+	}
+	got := pr.color(gotColor, pr.value("%v", err.got))
+	return fmt.Sprintf("%s: Element unexpected in got; got=%s", err.path.format(pr), got)
 }
 
 type funcError struct {
@@ -117,16 +195,20 @@ type funcError struct {
 }
 
 func (err *funcError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *funcError) format(pr printer) string {
 	got, want := "<nil>", "<nil>"
 	if !err.got.IsNil() {
-		got = err.got.Type().String()
+		got = pr.text(err.got.Type().String())
 	}
 	if !err.want.IsNil() {
-		want = err.want.Type().String()
+		want = pr.text(err.want.Type().String())
 	}
-	got = gotColor + got + stopColor
-	want = wantColor + want + stopColor
-	return fmt.Sprintf("%s: Func mismatch; got=%s, want=%s (Can only match if both are <nil>)", err.path, got, want)
+	got = pr.color(gotColor, got)
+	want = pr.color(wantColor, want)
+	return fmt.Sprintf("%s: Func mismatch; got=%s, want=%s (Can only match if both are <nil>)", err.path.format(pr), got, want)
 }
 
 type valueError struct {
@@ -136,9 +218,163 @@ type valueError struct {
 }
 
 func (err *valueError) Error() string {
-	got := gotColor + fmt.Sprintf("%v", err.got) + stopColor
-	want := wantColor + fmt.Sprintf("%v", err.want) + stopColor
-	return fmt.Sprintf("%s: Value mismatch; got=%s, want=%s", err.path, got, want)
+	return err.format(printer{})
+}
+
+func (err *valueError) format(pr printer) string {
+	got, want := pr.value("%v", err.got), pr.value("%v", err.want)
+	if v, ok := err.got.(reflect.Value); ok {
+		got += pr.addr(v)
+	}
+	if v, ok := err.want.(reflect.Value); ok {
+		want += pr.addr(v)
+	}
+	got, want = pr.color(gotColor, got), pr.color(wantColor, want)
+	return fmt.Sprintf("%s: Value mismatch; got=%s, want=%s", err.path.format(pr), got, want)
+}
+
+// identityError represents two channel or unsafe.Pointer values that are not
+// identical, i.e. that do not refer to the same channel or the same address.
+type identityError struct {
+	got  reflect.Value
+	want reflect.Value
+	path path
+}
+
+func (err *identityError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *identityError) format(pr printer) string {
+	got := pr.color(gotColor, identityString(err.got))
+	want := pr.color(wantColor, identityString(err.want))
+	what := "Channel identity"
+	if err.want.Kind() == reflect.UnsafePointer {
+		what = "Unsafe pointer"
+	}
+	return fmt.Sprintf("%s: %s mismatch; got=%s, want=%s", err.path.format(pr), what, got, want)
+}
+
+// cycleError represents two graphs of values of which only one cycles back at
+// the path, or of which both cycle back but to different ancestors. The got and
+// want paths are those at which the cycles were entered, or nil if there is no
+// cycle on that side.
+type cycleError struct {
+	got  path
+	want path
+	path path
+}
+
+func (err *cycleError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *cycleError) format(pr printer) string {
+	got, want := "<no cycle>", "<no cycle>"
+	if err.got != nil {
+		got = "cycle to " + cycleTarget(pr, err.got)
+	}
+	if err.want != nil {
+		want = "cycle to " + cycleTarget(pr, err.want)
+	}
+	return fmt.Sprintf("%s: Cycle mismatch; got=%s, want=%s", err.path.format(pr),
+		pr.color(gotColor, got), pr.color(wantColor, want))
+}
+
+// cycleTarget returns the path p, at which a cycle was entered, rendered
+// without its root node.
+func cycleTarget(pr printer, p path) string {
+	if pr.pointer {
+		return p.pointer(pr)
+	}
+	if s := p[1:].format(pr); s != "" {
+		return s
+	}
+	return "<root>"
+}
+
+// identityString returns the address held by the channel or unsafe.Pointer
+// value v, or "nil" if v is nil.
+func identityString(v reflect.Value) string {
+	if v.IsNil() {
+		return "nil"
+	}
+	return fmt.Sprintf("%#x", v.Pointer())
+}
+
+// timeError represents two times that represent the same instant but differ
+// in the given component, see Config.CompareTimeLocation.
+type timeError struct {
+	got       time.Time
+	want      time.Time
+	component string
+	path      path
+}
+
+func (err *timeError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *timeError) format(pr printer) string {
+	got, want := timeComponent(err.got, err.component), timeComponent(err.want, err.component)
+	got, want = pr.color(gotColor, pr.text(got)), pr.color(wantColor, pr.text(want))
+	return fmt.Sprintf("%s: Time %s mismatch; got=%s, want=%s", err.path.format(pr), err.component, got, want)
+}
+
+// timeDeltaError represents two times that are further apart than the
+// TimeTolerance, the delta is the duration from the want to the got time. If
+// IgnoreTimeZone is set, the times are those of the wall clock readings.
+type timeDeltaError struct {
+	got   time.Time
+	want  time.Time
+	delta time.Duration
+	path  path
+}
+
+func (err *timeDeltaError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *timeDeltaError) format(pr printer) string {
+	got := pr.color(gotColor, pr.value("%v", err.got))
+	want := pr.color(wantColor, pr.value("%v", err.want))
+	delta := pr.color(yellowColor, err.delta.String())
+	return fmt.Sprintf("%s: Value mismatch; got=%s, want=%s, delta=%s", err.path.format(pr), got, want, delta)
+}
+
+// floatError represents two float values that differ by more than the
+// tolerance.
+type floatError struct {
+	got   reflect.Value
+	want  reflect.Value
+	delta float64
+	path  path
+}
+
+func (err *floatError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *floatError) format(pr printer) string {
+	got := pr.color(gotColor, pr.value("%v", err.got))
+	want := pr.color(wantColor, pr.value("%v", err.want))
+	delta := pr.color(yellowColor, pr.float(err.delta, err.want.Type().Bits()))
+	return fmt.Sprintf("%s: Value mismatch; got=%s, want=%s, delta=%s", err.path.format(pr), got, want, delta)
+}
+
+// skipError represents a struct field that was omitted from comparison, see
+// Config.AuditSkippedFields.
+type skipError struct {
+	rule string
+	path path
+}
+
+func (err *skipError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *skipError) format(pr printer) string {
+	return fmt.Sprintf("%s: Field skipped by %s", err.path.format(pr), pr.color(yellowColor, err.rule))
 }
 
 type zeroError struct {
@@ -148,15 +384,143 @@ type zeroError struct {
 }
 
 func (err *zeroError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *zeroError) format(pr printer) string {
 	var got, want string
 	if err.got == true {
-		got = gotColor + "<zero>" + stopColor
-		want = wantColor + "<non-zero>" + stopColor
+		got = pr.color(gotColor, "<zero>")
+		want = pr.color(wantColor, "<non-zero>")
 	} else {
-		got = gotColor + "<non-zero>" + stopColor
-		want = wantColor + "<zero>" + stopColor
+		got = pr.color(gotColor, "<non-zero>")
+		want = pr.color(wantColor, "<zero>")
+	}
+	return fmt.Sprintf("%s: Zero mismatch (both values must be either zero or non-zero); got=%s, want=%s", err.path.format(pr), got, want)
+}
+
+type chainError struct {
+	got   []error
+	want  []error
+	index int // the index of the link at which the two chains diverge
+	path  path
+}
+
+func (err *chainError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *chainError) format(pr printer) string {
+	got, want := "<nil>", "<nil>"
+	if err.index < len(err.got) {
+		got = pr.text(fmt.Sprintf("%T(%q)", err.got[err.index], err.got[err.index].Error()))
+	}
+	if err.index < len(err.want) {
+		want = pr.text(fmt.Sprintf("%T(%q)", err.want[err.index], err.want[err.index].Error()))
+	}
+	got = pr.color(gotColor, got)
+	want = pr.color(wantColor, want)
+	return fmt.Sprintf("%s: Error chain mismatch at link %d; got=%s, want=%s", err.path.format(pr), err.index, got, want)
+}
+
+type moreError struct {
+	count int // the number of differing elements that were not reported, -1 if unknown
+	kind  reflect.Kind
+	path  path
+}
+
+func (err *moreError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *moreError) format(pr printer) string {
+	if err.count < 0 {
+		return fmt.Sprintf("%s: ...and more differing elements of %s", err.path.format(pr), err.kind)
+	}
+	count := pr.color(yellowColor, fmt.Sprintf("%d", err.count))
+	return fmt.Sprintf("%s: ...and %s more differing elements of %s", err.path.format(pr), count, err.kind)
+}
+
+// depthError represents the values at a path nested deeper than MaxDepth that
+// were not compared.
+type depthError struct {
+	max  int
+	path path
+}
+
+func (err *depthError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *depthError) format(pr printer) string {
+	max := pr.color(yellowColor, fmt.Sprintf("%d", err.max))
+	return fmt.Sprintf("%s: Maximum depth of %s exceeded; the values were not compared", err.path.format(pr), max)
+}
+
+// limitError summarizes the differences that were not reported due to the
+// MaxErrors limit.
+type limitError struct {
+	count int // the number of the differences that were not reported, -1 if unknown
+	limit int
+	path  path
+}
+
+func (err *limitError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *limitError) format(pr printer) string {
+	if err.count < 0 {
+		limit := pr.color(yellowColor, fmt.Sprintf("%d", err.limit))
+		return fmt.Sprintf("%s: ...the comparison was stopped after %s differences", err.path.format(pr), limit)
+	}
+	count := pr.color(yellowColor, fmt.Sprintf("%d", err.count))
+	return fmt.Sprintf("%s: ...and %s more differences", err.path.format(pr), count)
+}
+
+type collectionError struct {
+	errs []error // the errors found inside the collection
+	kind reflect.Kind
+	path path
+}
+
+func (err *collectionError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *collectionError) format(pr printer) string {
+	count := pr.color(yellowColor, fmt.Sprintf("%d", len(err.errs)))
+	res := fmt.Sprintf("%s: %s mismatches in %s:", err.path.format(pr), count, err.kind)
+	if pr.oneline {
+		return res + " " + pr.list(err.errs)
+	}
+	lines := make([]string, len(err.errs))
+	for i, e := range err.errs {
+		lines[i] = pr.error(e)
+	}
+	if pr.golden {
+		sort.Strings(lines)
 	}
-	return fmt.Sprintf("%s: Zero mismatch (both values must be either zero or non-zero); got=%s, want=%s", err.path, got, want)
+	for _, line := range lines {
+		res += "\n\t" + line
+	}
+	return res
+}
+
+// fieldsError summarizes the number of differing fields of a struct, see Verbose.
+type fieldsError struct {
+	differ int // the number of differing fields
+	total  int // the number of compared fields
+	path   path
+}
+
+func (err *fieldsError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *fieldsError) format(pr printer) string {
+	count := pr.color(yellowColor, fmt.Sprintf("%d/%d", err.differ, err.total))
+	return fmt.Sprintf("%s: %s fields differ", err.path.format(pr), count)
 }
 
 type stringError struct {
@@ -168,40 +532,214 @@ type stringError struct {
 const maxlen = 30 // max string length displayable in an error message
 
 func newStringError(got, want string, p path) *stringError {
-	err := &stringError{
-		got:  gotColor + `"` + got + `"` + stopColor,
-		want: wantColor + `"` + want + `"` + stopColor,
-		path: p,
-	}
-	if d := sdiff(got, want); d != nil {
-		start, end := got[:d.start], got[d.end:]
-		delta := got[d.start:d.end]
-
-		err.got = gotColor + `"` +
-			start + stopColor + diffGotColor +
-			delta + diffGotStopColor + gotColor +
-			end + `"` + stopColor
-
-		if len(want) > d.start {
-			start = want[:d.start]
-			if len(want) > d.end {
-				end = want[d.end:]
-				delta = want[d.start:d.end]
+	return &stringError{got: got, want: want, path: p}
+}
+
+func (err *stringError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *stringError) format(pr printer) string {
+	d := sdiff(err.got, err.want)
+	oneline := !strings.Contains(err.got, "\n") && !strings.Contains(err.want, "\n")
+
+	// the bounds of the rendered parts of the strings
+	glo, ghi, wlo, whi := 0, len(err.got), 0, len(err.want)
+	if d != nil && oneline && pr.strctx > 0 {
+		glo, ghi = swindow(err.got, d.start, d.end, pr.strctx)
+		wlo, whi = swindow(err.want, d.start, min(d.end, len(err.want)), pr.strctx)
+	}
+
+	gotText, wantText := pr.text(err.got[glo:ghi]), pr.text(err.want[wlo:whi])
+	highlight := pr.colored() && !pr.nohighlight
+	if !highlight && stripInvisible(err.got) == stripInvisible(err.want) {
+		// the strings differ only by invisible characters, and
+		// without the highlight their difference would not be seen
+		gotText = pr.text(escapeInvisible(err.got[glo:ghi]))
+		wantText = pr.text(escapeInvisible(err.want[wlo:whi]))
+	}
+	got := pr.color(gotColor, `"`+pr.ellipsis(glo > 0)+gotText+pr.ellipsis(ghi < len(err.got))+`"`)
+	want := pr.color(wantColor, `"`+pr.ellipsis(wlo > 0)+wantText+pr.ellipsis(whi < len(err.want))+`"`)
+
+	if d != nil && highlight {
+		start, end := err.got[glo:d.start], err.got[d.end:ghi]
+		delta := err.got[d.start:d.end]
+
+		got = gotColor + `"` + pr.ellipsis(glo > 0) +
+			pr.text(start) + stopColor + diffGotColor +
+			pr.text(escapeInvisible(delta)) + diffGotStopColor + gotColor +
+			pr.text(end) + pr.ellipsis(ghi < len(err.got)) + `"` + stopColor
+
+		if len(err.want) > d.start {
+			start = err.want[wlo:d.start]
+			if len(err.want) > d.end {
+				end = err.want[d.end:whi]
+				delta = err.want[d.start:d.end]
 			} else {
 				end = ""
-				delta = want[d.start:]
+				delta = err.want[d.start:]
 			}
-			err.want = wantColor + `"` +
-				start + stopColor + diffWantColor +
-				delta + diffWantStopColor + wantColor +
-				end + `"` + stopColor
+			want = wantColor + `"` + pr.ellipsis(wlo > 0) +
+				pr.text(start) + stopColor + diffWantColor +
+				pr.text(escapeInvisible(delta)) + diffWantStopColor + wantColor +
+				pr.text(end) + pr.ellipsis(whi < len(err.want)) + `"` + stopColor
 		}
 	}
-	return err
+	res := fmt.Sprintf("%s: Value mismatch; got=%s, want=%s", err.path.format(pr), got, want)
+	if d != nil && oneline {
+		// the offset locates the difference also when the highlight
+		// is not shown, e.g. in logs stripped of their colors
+		res += fmt.Sprintf("; differs at byte %d (rune %d)", d.start, utf8.RuneCountInString(err.got[:d.start]))
+	}
+	return res
 }
 
-func (err *stringError) Error() string {
-	return fmt.Sprintf("%s: Value mismatch; got=%s, want=%s", err.path, err.got, err.want)
+// bytesError represents two byte slices that differ, see HexDump.
+type bytesError struct {
+	got  []byte
+	want []byte
+	path path
+}
+
+func (err *bytesError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *bytesError) format(pr printer) string {
+	offset := bdiff(err.got, err.want)
+	res := fmt.Sprintf("%s: Value mismatch", err.path.format(pr))
+	if !pr.oneline {
+		res += fmt.Sprintf(" (%s %s)", pr.color(gotColor, "-got"), pr.color(wantColor, "+want"))
+	}
+	res += fmt.Sprintf("; differs at byte %d", offset)
+	if len(err.got) != len(err.want) {
+		res += fmt.Sprintf("; got len=%s, want len=%s",
+			pr.color(gotColor, fmt.Sprint(len(err.got))),
+			pr.color(wantColor, fmt.Sprint(len(err.want))))
+	}
+	if pr.oneline {
+		return res
+	}
+
+	// the row of the first difference is shown
+	// along with one row on either side of it
+	row := offset - offset%hexRowLen
+	start := max(0, row-hexRowLen)
+	end := min(max(len(err.got), len(err.want)), row+2*hexRowLen)
+	res += ":"
+	for i := start; i < end; i += hexRowLen {
+		g, w := err.got[min(i, len(err.got)):min(i+hexRowLen, len(err.got))],
+			err.want[min(i, len(err.want)):min(i+hexRowLen, len(err.want))]
+		if bytes.Equal(g, w) {
+			res += "\n\t  " + hexRow(pr, err.got, err.want, i, "")
+			continue
+		}
+		if len(g) > 0 {
+			res += "\n\t" + pr.color(gotColor, "-") + " " + hexRow(pr, err.got, err.want, i, diffGotColor)
+		}
+		if len(w) > 0 {
+			res += "\n\t" + pr.color(wantColor, "+") + " " + hexRow(pr, err.want, err.got, i, diffWantColor)
+		}
+	}
+	return res
+}
+
+// linesError represents two multi-line strings that differ, see LineDiff.
+type linesError struct {
+	got  string
+	want string
+	path path
+}
+
+func (err *linesError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *linesError) format(pr printer) string {
+	if pr.oneline {
+		return newStringError(err.got, err.want, err.path).format(pr)
+	}
+	res := fmt.Sprintf("%s: Value mismatch (%s %s):", err.path.format(pr),
+		pr.color(gotColor, "-got"), pr.color(wantColor, "+want"))
+	for _, line := range lineDiff(err.got, err.want, lineContext) {
+		switch line[0] {
+		case '-':
+			line = pr.color(gotColor, "-"+pr.text(line[1:]))
+		case '+':
+			line = pr.color(wantColor, "+"+pr.text(line[1:]))
+		case '@':
+			line = pr.color(yellowColor, line)
+		default:
+			line = pr.text(line)
+		}
+		res += "\n\t" + line
+	}
+	return res
+}
+
+// errorPath returns the path of the given error returned by Compare.
+func errorPath(err error) path {
+	switch err := err.(type) {
+	case *validityError:
+		return err.path
+	case *typeError:
+		return err.path
+	case *nilError:
+		return err.path
+	case *lenError:
+		return err.path
+	case *elemError:
+		return err.path
+	case *linesError:
+		return err.path
+	case *bytesError:
+		return err.path
+	case *funcError:
+		return err.path
+	case *valueError:
+		return err.path
+	case *floatError:
+		return err.path
+	case *timeDeltaError:
+		return err.path
+	case *timeError:
+		return err.path
+	case *skipError:
+		return err.path
+	case *zeroError:
+		return err.path
+	case *chainError:
+		return err.path
+	case *moreError:
+		return err.path
+	case *limitError:
+		return err.path
+	case *depthError:
+		return err.path
+	case *identityError:
+		return err.path
+	case *cycleError:
+		return err.path
+	case *collectionError:
+		return err.path
+	case *stringError:
+		return err.path
+	case *matchError:
+		return err.path
+	case *fieldsError:
+		return err.path
+	case *pathError:
+		return err.path
+	case *equalError:
+		return err.path
+	case *changeError:
+		return err.path
+	case *warningError:
+		return errorPath(err.err)
+	case *sampleError:
+		return err.path
+	}
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -214,15 +752,69 @@ func (p path) add(n pathnode) path {
 	return append(q, n)
 }
 
-func (p path) String() (s string) {
+// depth returns the number of levels of the path below its root, the nodes of
+// the dereferenced pointers are not counted.
+func (p path) depth() (d int) {
 	for _, n := range p {
-		s += n.str(nil)
+		switch n.(type) {
+		case rootnode, derefnode:
+			continue
+		}
+		d++
+	}
+	return d
+}
+
+func (p path) String() string {
+	return p.format(printer{})
+}
+
+func (p path) format(pr printer) (s string) {
+	if pr.pointer {
+		return p.pointer(pr)
+	}
+	for _, n := range p {
+		s += n.str(pr)
 	}
 	return s
 }
 
+// pointer returns the path rendered as a JSON Pointer.
+func (p path) pointer(pr printer) (s string) {
+	for _, n := range p {
+		var token string
+		switch n := n.(type) {
+		case rootnode:
+			continue
+		case arrnode:
+			token = fmt.Sprintf("%d", n.index)
+		case channode:
+			token = fmt.Sprintf("%d", n.index)
+		case mapnode:
+			token = fmt.Sprintf("%v", n.key)
+		case structnode:
+			token = n.field
+		case elemnode:
+			token = n.label
+		case methodnode:
+			token = n.name
+		case callnode:
+			token = n.str(pr)
+		case derefnode:
+			continue
+		}
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		s += "/" + pr.text(token)
+	}
+	if len(s) == 0 {
+		return `- ""`
+	}
+	return "- " + s
+}
+
 type pathnode interface {
-	str(color interface{}) string
+	str(pr printer) string
 }
 
 type rootnode struct {
@@ -231,18 +823,20 @@ type rootnode struct {
 
 var niltyp = reflect.TypeOf(nil)
 
-func (n rootnode) str(color interface{}) string {
+func (n rootnode) str(pr printer) string {
 	if n.typ == niltyp {
-		return fmt.Sprintf("- <%s%s%s>", purpleColor, "nil", stopColor)
+		return fmt.Sprintf("- <%s>", pr.color(purpleColor, "nil"))
 	}
-	return fmt.Sprintf("- (%s)", n.typ)
+	return fmt.Sprintf("- (%s)", pr.text(n.typ.String()))
 }
 
 type arrnode struct {
 	index int
 }
 
-func (n arrnode) str(color interface{}) string {
+func newArrnode(i int) pathnode { return arrnode{i} }
+
+func (n arrnode) str(pr printer) string {
 	return fmt.Sprintf("[%d]", n.index)
 }
 
@@ -250,7 +844,11 @@ type channode struct {
 	index int
 }
 
-func (n channode) str(color interface{}) string {
+// newChannode returns the node of the ith element, counting from 0, received
+// from a channel. Note that the index of a channode is counted from 1.
+func newChannode(i int) pathnode { return channode{i + 1} }
+
+func (n channode) str(pr printer) string {
 	return fmt.Sprintf("[%d]", n.index)
 }
 
@@ -258,14 +856,39 @@ type mapnode struct {
 	key reflect.Value
 }
 
-func (n mapnode) str(color interface{}) string {
-	return fmt.Sprintf("[%v]", n.key)
+func (n mapnode) str(pr printer) string {
+	return fmt.Sprintf("[%s]", pr.value("%v", n.key))
+}
+
+// callnode represents the result of a call of a func value, see FuncArgs.
+type callnode struct {
+	sample int // the index of the sample argument list
+	out    int // the index of the result
+	nout   int // the number of results
+}
+
+func (n callnode) str(pr printer) string {
+	if n.nout > 1 {
+		return fmt.Sprintf("(args[%d])[%d]", n.sample, n.out)
+	}
+	return fmt.Sprintf("(args[%d])", n.sample)
+}
+
+// derefnode indicates that the values of one side were dereferenced n times
+// to match the pointer depth of the other side, see IgnorePointerDepth.
+type derefnode struct {
+	side string // "got" or "want"
+	n    int
+}
+
+func (n derefnode) str(pr printer) string {
+	return "{" + strings.Repeat("*", n.n) + n.side + "}"
 }
 
 type structnode struct {
 	field string
 }
 
-func (n structnode) str(color interface{}) string {
-	return fmt.Sprintf(".%s", n.field)
+func (n structnode) str(pr printer) string {
+	return fmt.Sprintf(".%s", pr.text(n.field))
 }
@@ -0,0 +1,45 @@
+package compare
+
+import (
+	"reflect"
+)
+
+// FieldRule is the rule by which a struct field is compared, see FieldFilter.
+type FieldRule int
+
+const (
+	// The field is compared as usual.
+	CompareField FieldRule = iota
+	// The field is omitted from comparison, like with the "-" tag option.
+	SkipField
+	// The field is omitted from comparison if the field of the want value
+	// is empty, like with the "omitempty" tag option.
+	OmitEmptyField
+	// Only the "zero-ness" of the fields is compared, like with the "+" tag
+	// option.
+	ZeroOnlyField
+)
+
+// FieldFilter is a function that returns the rule by which the struct field f
+// found at the given path, in the same syntax as that used by Difference.Path,
+// is to be compared, see Config.FieldFilter.
+type FieldFilter func(f reflect.StructField, path string) FieldRule
+
+// filterField applies the FieldFilter to the ith field of the want struct at
+// the path p. It reports whether the field is to be compared.
+func (conf Config) filterField(want reflect.Value, i int, cmp *comparison, p path) (ok bool) {
+	f := want.Type().Field(i)
+	switch conf.FieldFilter(f, p.relpath()) {
+	case SkipField:
+		conf.skipField(`FieldFilter`, cmp, p)
+		return false
+	case OmitEmptyField:
+		if conf.isZero(want.Field(i)) {
+			conf.skipField(`FieldFilter`, cmp, p)
+			return false
+		}
+	case ZeroOnlyField:
+		cmp.zero = true
+	}
+	return true
+}
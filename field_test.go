@@ -0,0 +1,67 @@
+package compare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareFieldFilter(t *testing.T) {
+	type Meta struct {
+		ID      int
+		Created time.Time
+	}
+	type Item struct {
+		Meta  Meta
+		Name  string
+		Token string
+		Note  string
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	filter := func(f reflect.StructField, path string) FieldRule {
+		switch {
+		case f.Name == "ID":
+			return SkipField
+		case f.Type == reflect.TypeOf(time.Time{}):
+			return ZeroOnlyField
+		case f.Name == "Note":
+			return OmitEmptyField
+		case strings.HasSuffix(path, ".Token"):
+			return ZeroOnlyField
+		}
+		return CompareField
+	}
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: Config{FieldFilter: filter},
+		a:    Item{Meta{1, now}, "a", "t1", "x"}, b: Item{Meta{2, now.Add(time.Hour)}, "a", "t2", ""},
+		err: "",
+	}, {
+		conf: Config{FieldFilter: filter},
+		a:    Item{Meta{1, time.Time{}}, "a", "", "x"}, b: Item{Meta{2, now}, "b", "t", "y"},
+		err: "- (compare.Item).Meta.Created: Zero mismatch (both values must be either zero or non-zero); got=<zero>, want=<non-zero>\n" +
+			"- (compare.Item).Name: Value mismatch; got=\"a\", want=\"b\"; differs at byte 0 (rune 0)\n" +
+			"- (compare.Item).Note: Value mismatch; got=\"x\", want=\"y\"; differs at byte 0 (rune 0)\n" +
+			"- (compare.Item).Token: Zero mismatch (both values must be either zero or non-zero); got=<zero>, want=<non-zero>\n",
+	}, {
+		conf: Config{FieldFilter: filter, AuditSkippedFields: true},
+		a:    []Item{{Meta: Meta{ID: 1}}}, b: []Item{{Meta: Meta{ID: 2}}},
+		err: "- ([]compare.Item)[0].Meta.ID: Field skipped by FieldFilter\n" +
+			"- ([]compare.Item)[0].Note: Field skipped by FieldFilter\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+		if eq := tt.conf.EqualNoReport(tt.a, tt.b); eq != (tt.err == "") {
+			t.Errorf("#%d: EqualNoReport() got=%t, want=%t", i, eq, tt.err == "")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package compare
+
+import (
+	"net/textproto"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// HeaderOptions specifies how CompareHeaders normalizes the maps before
+// they are compared.
+type HeaderOptions struct {
+	// CanonicalKey returns the canonical form of the given key, keys
+	// that have the same canonical form are considered equal and the
+	// canonical form is the one shown in the paths of the errors. If
+	// CanonicalKey is nil then textproto.CanonicalMIMEHeaderKey is used.
+	// For Windows environment variables strings.ToUpper could be used.
+	CanonicalKey func(key string) string
+	// If TrimValues is set, the leading and trailing white space of
+	// the string values is removed before the values are compared.
+	TrimValues bool
+	// If IgnoreValueOrder is set, the order of the elements of the
+	// values of slice kind is ignored, e.g. the values {"a", "b"} and
+	// {"b", "a"} of the same key are considered equal. The option enables
+	// the Config's IgnoreArrayOrder for the comparison of the maps.
+	IgnoreValueOrder bool
+}
+
+// CompareHeaders is a wrapper around DefaultConfig.CompareHeaders.
+func CompareHeaders(got, want interface{}, opts HeaderOptions) error {
+	return DefaultConfig.CompareHeaders(got, want, opts)
+}
+
+// CompareHeaders compares two string-keyed maps, e.g. http.Header values or
+// maps of environment variables, with their keys matched case-insensitively.
+// The values of the maps may be of any type, however TrimValues applies only
+// to values of kind string or to the elements of values of kind []string.
+//
+// If a single map contains multiple keys with the same canonical form then,
+// for values of slice kind, the values will be concatenated and, for values
+// of any other kind, the value of the key that sorts last will be retained.
+//
+// The given maps are not modified. If either of the arguments is not a map
+// with keys of kind string, the arguments are compared as they are.
+func (conf Config) CompareHeaders(got, want interface{}, opts HeaderOptions) error {
+	if opts.CanonicalKey == nil {
+		opts.CanonicalKey = textproto.CanonicalMIMEHeaderKey
+	}
+
+	gotv, wantv := reflect.ValueOf(got), reflect.ValueOf(want)
+	if !isHeaderMap(gotv) || !isHeaderMap(wantv) {
+		return conf.Compare(got, want)
+	}
+	gotv, wantv = normalizeHeaders(gotv, opts), normalizeHeaders(wantv, opts)
+	if opts.IgnoreValueOrder {
+		conf.IgnoreArrayOrder = true
+	}
+	return conf.Compare(gotv.Interface(), wantv.Interface())
+}
+
+func isHeaderMap(v reflect.Value) bool {
+	return v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String
+}
+
+// normalizeHeaders returns a copy of the given map with its keys canonicalized
+// and, optionally, with its values trimmed.
+func normalizeHeaders(m reflect.Value, opts HeaderOptions) reflect.Value {
+	if m.IsNil() {
+		return m
+	}
+
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	typ := m.Type()
+	out := reflect.MakeMapWithSize(typ, len(keys))
+	for _, key := range keys {
+		k := reflect.ValueOf(opts.CanonicalKey(key.String())).Convert(typ.Key())
+		v := m.MapIndex(key)
+		if opts.TrimValues {
+			v = trimHeaderValue(v)
+		}
+		if prev := out.MapIndex(k); prev.IsValid() && v.Kind() == reflect.Slice {
+			v = reflect.AppendSlice(reflect.AppendSlice(
+				reflect.MakeSlice(v.Type(), 0, prev.Len()+v.Len()), prev), v)
+		}
+		out.SetMapIndex(k, v)
+	}
+	return out
+}
+
+// trimHeaderValue returns a copy of the given value with the leading and
+// trailing white space removed from the string value or from the string
+// elements of the slice value.
+func trimHeaderValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(strings.TrimSpace(v.String())).Convert(v.Type())
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() != reflect.String {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			s.Index(i).Set(reflect.ValueOf(strings.TrimSpace(elem.String())).Convert(elem.Type()))
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package compare
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCompareHeaders(t *testing.T) {
+	tests := []struct {
+		got, want interface{}
+		opts      HeaderOptions
+		err       error
+	}{{
+		got:  http.Header{"content-type": {"text/plain"}},
+		want: http.Header{"Content-Type": {"text/plain"}},
+		err:  nil,
+	}, {
+		got:  map[string]string{"CONTENT-TYPE": " text/plain "},
+		want: map[string]string{"content-type": "text/plain"},
+		opts: HeaderOptions{TrimValues: true},
+		err:  nil,
+	}, {
+		got:  http.Header{"x-a": {"1"}, "X-A": {"2"}},
+		want: http.Header{"X-A": {"2", "1"}},
+		err:  nil,
+	}, {
+		got:  map[string]string{"Path": `C:\bin`},
+		want: map[string]string{"PATH": `C:\bin`},
+		opts: HeaderOptions{CanonicalKey: strings.ToUpper},
+		err:  nil,
+	}, {
+		got:  http.Header{"accept": {" */*"}},
+		want: http.Header{"Accept": {"text/html"}},
+		opts: HeaderOptions{TrimValues: true},
+		err: elist(newStringError("*/*", "text/html", path{
+			rootnode{rtof(http.Header{})},
+			mapnode{rvof("Accept")},
+			arrnode{0},
+		})),
+	}, {
+		got:  http.Header{"accept": {"text/html", "*/*"}, "vary": {"b", "a"}},
+		want: http.Header{"Accept": {"*/*", "text/html"}, "Vary": {"a", "b"}},
+		opts: HeaderOptions{IgnoreValueOrder: true},
+		err:  nil,
+	}, {
+		got:  http.Header{"x-a": {"2"}, "X-A": {"1"}},
+		want: http.Header{"X-A": {"2", "1"}},
+		opts: HeaderOptions{IgnoreValueOrder: true},
+		err:  nil,
+	}, {
+		got:  http.Header{"accept": {"text/html", "*/*"}},
+		want: http.Header{"Accept": {"*/*", "text/plain"}},
+		opts: HeaderOptions{IgnoreValueOrder: true},
+		err: elist(newStringError("text/html", "text/plain", path{
+			rootnode{rtof(http.Header{})},
+			mapnode{rvof("Accept")},
+			arrnode{1},
+		})),
+	}, {
+		got:  http.Header{"accept": {"*/*"}},
+		want: http.Header(nil),
+		err: elist(&nilError{
+			got:  rvof(http.Header{"Accept": {"*/*"}}),
+			want: rvof(http.Header(nil)),
+			path: path{rootnode{rtof(http.Header{})}},
+		}),
+	}}
+
+	for _, tt := range tests {
+		if err := CompareHeaders(tt.got, tt.want, tt.opts); errstr(err) != errstr(tt.err) {
+			t.Errorf("CompareHeaders(%v, %v) = %v\n\n", tt.got, tt.want, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(tt.err))
+		}
+	}
+}
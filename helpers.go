@@ -0,0 +1,287 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TestingT is the subset of the testing.TB interface used by the test helpers
+// of this package.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Case represents a single, named comparison of a got and a want value.
+type Case struct {
+	Name string
+	Got  interface{}
+	Want interface{}
+}
+
+// CompareAll is a wrapper around DefaultConfig.CompareAll.
+func CompareAll(cases []Case) error {
+	return DefaultConfig.CompareAll(cases)
+}
+
+// CompareAll compares the got and want values of each of the given cases and
+// returns an error that aggregates the errors of all of the failed cases. Each
+// line of the returned error is prefixed with the name of the case the line
+// belongs to. If all of the cases pass the result will be nil.
+func (conf Config) CompareAll(cases []Case) error {
+	list := &errorList{pr: conf.printer()}
+	for _, c := range cases {
+		if err := conf.Compare(c.Got, c.Want); err != nil {
+			list.add(&caseError{c.Name, err})
+		}
+	}
+	return list.err()
+}
+
+// CompareAllT is a wrapper around DefaultConfig.CompareAllT.
+func CompareAllT(t TestingT, cases []Case) {
+	t.Helper()
+	DefaultConfig.CompareAllT(t, cases)
+}
+
+// CompareAllT compares the got and want values of each of the given cases and
+// reports the error of each of the failed cases, prefixed with the name of
+// the case, using t.Errorf. The warnings, see WarnPaths, do not fail the cases,
+// they are reported using t.Logf instead, if t has such a method.
+func (conf Config) CompareAllT(t TestingT, cases []Case) {
+	t.Helper()
+	pr := conf.printer()
+	logger, _ := t.(interface {
+		Logf(format string, args ...interface{})
+	})
+	for _, c := range cases {
+		err := conf.Compare(c.Got, c.Want)
+		if f := Failures(err); f != nil {
+			t.Errorf("%s", pr.error(&caseError{c.Name, f}))
+		}
+		if w := Warnings(err); w != nil && logger != nil {
+			logger.Logf("%s", pr.error(&caseError{c.Name, w}))
+		}
+	}
+}
+
+// RequireT is the subset of the testing.TB interface used by Require.
+type RequireT interface {
+	TestingT
+	Fatalf(format string, args ...interface{})
+}
+
+// Assert is a wrapper around DefaultConfig.Assert. The given options, if any,
+// are applied to a copy of the DefaultConfig, see Config.With.
+func Assert(t TestingT, got, want interface{}, opts ...Option) bool {
+	t.Helper()
+	return DefaultConfig.With(opts...).Assert(t, got, want)
+}
+
+// Assert compares the two given values and reports their differences using
+// t.Errorf, it reports whether the values are equal. The warnings, see
+// WarnPaths, do not fail the assertion, they are reported using t.Logf instead,
+// if t has such a method.
+func (conf Config) Assert(t TestingT, got, want interface{}) bool {
+	t.Helper()
+	return conf.report(t, conf.Compare(got, want), t.Errorf)
+}
+
+// Require is a wrapper around DefaultConfig.Require. The given options, if
+// any, are applied to a copy of the DefaultConfig, see Config.With.
+func Require(t RequireT, got, want interface{}, opts ...Option) {
+	t.Helper()
+	DefaultConfig.With(opts...).Require(t, got, want)
+}
+
+// Require is like Assert except that it reports the differences using
+// t.Fatalf, which stops the execution of the test.
+func (conf Config) Require(t RequireT, got, want interface{}) {
+	t.Helper()
+	conf.report(t, conf.Compare(got, want), t.Fatalf)
+}
+
+// DiffString is a wrapper around DefaultConfig.DiffString. The given options,
+// if any, are applied to a copy of the DefaultConfig, see Config.With.
+func DiffString(got, want interface{}, opts ...Option) string {
+	return DefaultConfig.With(opts...).DiffString(got, want)
+}
+
+// DiffString compares the two given values and returns the rendered report of
+// their differences, the same text as that of the error returned by Compare,
+// or an empty string if the values are equal. It is meant for differences that
+// should not fail a test, e.g. those of known-flaky fields that are passed to
+// t.Log, or that are embedded in custom failure messages.
+func (conf Config) DiffString(got, want interface{}) string {
+	if err := conf.Compare(got, want); err != nil {
+		return conf.printer().error(err)
+	}
+	return ""
+}
+
+// report reports the failures of err using fail and its warnings using t.Logf,
+// if t has such a method. It reports whether err had no failures.
+func (conf Config) report(t TestingT, err error, fail func(format string, args ...interface{})) bool {
+	t.Helper()
+	pr := conf.printer()
+	if w := Warnings(err); w != nil {
+		if logger, ok := t.(interface {
+			Logf(format string, args ...interface{})
+		}); ok {
+			logger.Logf("%s", pr.error(w))
+		}
+	}
+	if f := Failures(err); f != nil {
+		fail("%s", pr.error(f))
+		return false
+	}
+	return true
+}
+
+// StripColors returns s with its ANSI escape codes removed. The errors of this
+// package are already rendered without colors when the standard output is not
+// a terminal or when the tests are run by "go test -json", StripColors is meant
+// for the messages that are reported using t.Error or t.Log that were rendered
+// with colors elsewhere, e.g. by a Config whose errors were stored earlier.
+func StripColors(s string) string {
+	if !strings.Contains(s, "\033[") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\033' || i+1 >= len(s) || s[i+1] != '[' {
+			b.WriteByte(s[i])
+			continue
+		}
+		// skip the parameter and intermediate bytes up to,
+		// and including, the final byte of the sequence
+		for i += 2; i < len(s) && (s[i] < 0x40 || s[i] > 0x7e); i++ {
+		}
+	}
+	return b.String()
+}
+
+// caseError wraps the error of a failed Case.
+type caseError struct {
+	name string
+	err  error
+}
+
+func (err *caseError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *caseError) format(pr printer) string {
+	errs := []error{err.err}
+	if el, ok := err.err.(*errorList); ok {
+		errs = el.List
+	}
+
+	prefix := "[" + pr.text(err.name) + "] "
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = prefix + strings.ReplaceAll(pr.error(e), "\n", "\n"+prefix)
+	}
+	return strings.Join(lines, pr.newline())
+}
+
+func (err *caseError) differences() []Difference {
+	return Differences(err.err)
+}
+
+// NotEqual is a wrapper around DefaultConfig.NotEqual.
+func NotEqual(got, want interface{}, paths ...string) error {
+	return DefaultConfig.NotEqual(got, want, paths...)
+}
+
+// NotEqual is the inverse of Compare, it compares the two given values and
+// returns an error if they are equal. If paths are given, in the same syntax
+// as that used by Difference.Path, e.g. ".Authors[0].FirstName", then the
+// values are additionally expected to differ at each of those paths, that is,
+// an error is returned that lists the paths at which, or below which, no
+// difference was found. The paths may include the wildcards of IgnorePaths,
+// e.g. ".Authors[*].FirstName". Paths that cannot be parsed are reported as
+// errors.
+func (conf Config) NotEqual(got, want interface{}, paths ...string) error {
+	errs := &errorList{pr: conf.printer()}
+	typ := reflect.TypeOf(want)
+
+	result := conf.Compare(got, want)
+	if len(Differences(result)) == 0 {
+		errs.add(&equalError{path{rootnode{typ}}})
+		return errs.err()
+	}
+
+	diffs := diffPaths(result)
+	for _, s := range paths {
+		p := path{rootnode{typ}, elemnode{s}}
+		steps, err := parsePath(s)
+		if err != nil {
+			errs.add(&pathError{p, err.Error()})
+			continue
+		}
+		if !hasDiffAt(diffs, steps) {
+			errs.add(&equalError{p})
+		}
+	}
+	return errs.err()
+}
+
+// hasDiffAt reports whether any of the paths of the differences is at, or
+// below, the path of the given steps, which may include wildcards.
+func hasDiffAt(paths []path, steps []pathStep) bool {
+	for _, p := range paths {
+		if len(p) > 0 {
+			if _, ok := p[0].(rootnode); ok {
+				p = p[1:]
+			}
+		}
+		if len(p) >= len(steps) && matchAnyPath([][]pathStep{steps}, p[:len(steps)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffPaths returns the paths of the differences reported by the error, see
+// Differences.
+func diffPaths(err error) (paths []path) {
+	switch err := err.(type) {
+	case nil:
+	case *errorList:
+		for _, e := range err.List {
+			paths = append(paths, diffPaths(e)...)
+		}
+	case *collectionError:
+		for _, e := range err.errs {
+			paths = append(paths, diffPaths(e)...)
+		}
+	case *warningError:
+		return diffPaths(err.err)
+	case *fieldsError:
+		// a summary of other differences
+	default:
+		if p := errorPath(err); p != nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// equalError is returned by NotEqual for values that are equal.
+type equalError struct {
+	path path
+}
+
+func (err *equalError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *equalError) format(pr printer) string {
+	return fmt.Sprintf("%s: Values are equal; want them to differ", err.path.format(pr))
+}
+
+func (err *equalError) differences() []Difference {
+	return []Difference{{err.path.relpath(), EqualDiff, nil, nil, nil}}
+}
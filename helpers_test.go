@@ -0,0 +1,204 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testT records the calls made to the TestingT methods.
+type testT struct {
+	errors []string
+}
+
+func (t *testT) Helper() {}
+
+func (t *testT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCompareAll(t *testing.T) {
+	cases := []Case{
+		{Name: "ints", Got: 1, Want: 1},
+		{Name: "strings", Got: "foo", Want: "bar"},
+		{Name: "slices", Got: []int{1, 2}, Want: []int{3, 4}},
+	}
+
+	want := elist(&caseError{"strings", elist(
+		newStringError("foo", "bar", path{rootnode{rtof("")}}),
+	)}, &caseError{"slices", elist(&valueError{
+		got: 1, want: 3,
+		path: path{rootnode{rtof([]int{})}, arrnode{0}},
+	}, &valueError{
+		got: 2, want: 4,
+		path: path{rootnode{rtof([]int{})}, arrnode{1}},
+	})})
+
+	err := CompareAll(cases)
+	if errstr(err) != errstr(want) {
+		t.Errorf("CompareAll() = %v\n\n", err)
+		t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(want))
+	}
+	if s := errstr(err); !strings.HasPrefix(s, "[strings] - (string): Value mismatch") {
+		t.Errorf("CompareAll() error is not prefixed with the case name: %q", s)
+	}
+
+	if err := CompareAll(cases[:1]); err != nil {
+		t.Errorf("CompareAll() got=%v, want=<nil>", err)
+	}
+
+	tt := new(testT)
+	CompareAllT(tt, cases)
+	if len(tt.errors) != 2 {
+		t.Fatalf("CompareAllT() reported %d errors, want 2", len(tt.errors))
+	}
+	for i, e := range want.List {
+		if tt.errors[i] != e.Error() {
+			t.Errorf("CompareAllT() #%d got %q, want %q", i, tt.errors[i], e.Error())
+		}
+	}
+}
+
+func TestNotEqual(t *testing.T) {
+	type T struct {
+		Name  string
+		Tags  []string
+		Attrs map[string]int
+	}
+
+	tests := []struct {
+		a, b  interface{}
+		paths []string
+		want  string
+	}{{
+		a: T{Name: "a"}, b: T{Name: "b"}, want: "",
+	}, {
+		a: T{Name: "a"}, b: T{Name: "a"},
+		want: "- (compare.T): Values are equal; want them to differ\n",
+	}, {
+		a: T{Name: "a", Tags: []string{"x"}}, b: T{Name: "b", Tags: []string{"y"}},
+		paths: []string{".Name", ".Tags", ".Tags[0]"},
+		want:  "",
+	}, {
+		a: T{Name: "a", Attrs: map[string]int{"k": 1}}, b: T{Name: "b", Attrs: map[string]int{"k": 2}},
+		paths: []string{`.Attrs["k"]`, ".Tags", ".Nam", ".Name["},
+		want: "- (compare.T).Nam: Values are equal; want them to differ\n" +
+			"- (compare.T).Name[: Invalid path; missing ] at offset 5\n" +
+			"- (compare.T).Tags: Values are equal; want them to differ\n",
+	}, {
+		a: T{Tags: []string{"x", "y"}, Attrs: map[string]int{"k": 1}}, b: T{Tags: []string{"x", "z"}, Attrs: map[string]int{"k": 1}},
+		paths: []string{".Tags[*]", ".*", ".Attrs[*]"},
+		want:  "- (compare.T).Attrs[*]: Values are equal; want them to differ\n",
+	}}
+
+	for _, tt := range tests {
+		err := NotEqual(tt.a, tt.b, tt.paths...)
+		if got := Golden(err); got != tt.want {
+			t.Errorf("NotEqual(%v, %v, %q) got=%q, want=%q", tt.a, tt.b, tt.paths, got, tt.want)
+		}
+	}
+}
+
+// testFatalT records the calls made to the RequireT methods.
+type testFatalT struct {
+	testLogT
+	fatals []string
+}
+
+func (t *testFatalT) Fatalf(format string, args ...interface{}) {
+	t.fatals = append(t.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestAssert(t *testing.T) {
+	type Stats struct {
+		Hits, Misses int
+	}
+
+	tests := []struct {
+		got, want interface{}
+		opts      []Option
+		ok        bool
+		errors    []string
+		logs      []string
+	}{{
+		got: 1, want: 1, ok: true,
+	}, {
+		got: 1, want: 2, ok: false,
+		errors: []string{"- (int): Value mismatch; got=1, want=2"},
+	}, {
+		got: []int{3, 1, 2}, want: []int{1, 2, 3}, opts: []Option{IgnoreOrder()}, ok: true,
+	}, {
+		got: Stats{1, 2}, want: Stats{1, 3}, ok: true,
+		opts: []Option{func(c *Config) { c.WarnPaths = []string{".Misses"} }},
+		logs: []string{"[warning] - (compare.Stats).Misses: Value mismatch; got=2, want=3"},
+	}}
+
+	noColor := func(c *Config) { c.NoColor = true }
+	for i, tt := range tests {
+		opts := append(tt.opts, noColor)
+
+		ft := new(testFatalT)
+		if ok := Assert(ft, tt.got, tt.want, opts...); ok != tt.ok {
+			t.Errorf("#%d: Assert() got=%t, want=%t", i, ok, tt.ok)
+		}
+		if e := Compare(ft.errors, tt.errors); e != nil {
+			t.Errorf("#%d: Assert() errors: %v", i, e)
+		}
+		if e := Compare(ft.logs, tt.logs); e != nil {
+			t.Errorf("#%d: Assert() logs: %v", i, e)
+		}
+
+		ft = new(testFatalT)
+		Require(ft, tt.got, tt.want, opts...)
+		if e := Compare(ft.fatals, tt.errors); e != nil {
+			t.Errorf("#%d: Require() fatals: %v", i, e)
+		}
+		if len(ft.errors) != 0 {
+			t.Errorf("#%d: Require() reported %d errors, want 0", i, len(ft.errors))
+		}
+	}
+}
+
+func TestDiffString(t *testing.T) {
+	noColor := func(c *Config) { c.NoColor = true }
+	if got := DiffString([]int{1, 2}, []int{1, 2}, noColor); got != "" {
+		t.Errorf("DiffString() of equal values got=%q, want empty", got)
+	}
+
+	want := "- ([]int)[1]: Value mismatch; got=2, want=3"
+	if got := DiffString([]int{1, 2}, []int{1, 3}, noColor); got != want {
+		t.Errorf("DiffString() got=%q, want=%q", got, want)
+	}
+	if got := DiffString([]int{3, 1}, []int{1, 3}, noColor, IgnoreOrder()); got != "" {
+		t.Errorf("DiffString() with IgnoreOrder got=%q, want empty", got)
+	}
+
+	conf := Config{NoColor: true}
+	if got := conf.DiffString([]int{1, 2}, []int{1, 3}); got != want {
+		t.Errorf("Config.DiffString() got=%q, want=%q", got, want)
+	}
+}
+
+func TestStripColors(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"got=\033[91m1\033[0m, want=\033[96m2\033[0m", "got=1, want=2"},
+		{"\033[41m\033[30mx\033[0m", "x"},
+		{"trailing \033[", "trailing "},
+		{"escape \033 as is", "escape \033 as is"},
+	}
+	for _, tt := range tests {
+		if got := StripColors(tt.s); got != tt.want {
+			t.Errorf("StripColors(%q) got=%q, want=%q", tt.s, got, tt.want)
+		}
+	}
+
+	err := Compare([]int{1}, []int{2})
+	want := "- ([]int)[0]: Value mismatch; got=1, want=2"
+	if got := StripColors(err.Error()); got != want {
+		t.Errorf("StripColors(err.Error()) got=%q, want=%q", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package compare
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// IgnoreMapKeys returns a copy of the Config that excludes the entries with
+// the given keys from the comparison of maps of the given map type, wherever
+// such maps appear in the compared values. The keys that are not of the map's
+// key type are converted to it if they are of the same kind, e.g. an untyped
+// string constant can be used as the key of a map with a named string key type.
+func (conf Config) IgnoreMapKeys(typ reflect.Type, keys ...interface{}) Config {
+	m := make(map[reflect.Type][]interface{}, len(conf.IgnoredMapKeys)+1)
+	for t, k := range conf.IgnoredMapKeys {
+		m[t] = k
+	}
+	m[typ] = append(m[typ][:len(m[typ]):len(m[typ])], keys...)
+	conf.IgnoredMapKeys = m
+	return conf
+}
+
+// IgnoreMapKeysAt returns a copy of the Config that excludes the entries with
+// the given keys from the comparison of the map found at the given path. The
+// path is in the same syntax as that used by Difference.Path, e.g. ".Meta" or
+// ".Items[0].Labels", the path of the root value is empty. As with IgnorePaths
+// the leading dot may be left out and the wildcard steps may be used.
+func (conf Config) IgnoreMapKeysAt(path string, keys ...interface{}) Config {
+	m := make(map[string][]interface{}, len(conf.IgnoredMapKeysAt)+1)
+	for p, k := range conf.IgnoredMapKeysAt {
+		m[p] = k
+	}
+	m[path] = append(m[path][:len(m[path]):len(m[path])], keys...)
+	conf.IgnoredMapKeysAt = m
+	return conf
+}
+
+// parsedKeysAt are the keys of an entry of IgnoredMapKeysAt with the path of
+// the entry parsed.
+type parsedKeysAt struct {
+	steps []pathStep
+	keys  []interface{}
+}
+
+// parseIgnoredMapKeysAt parses the paths of the IgnoredMapKeysAt, the keys
+// whose paths cannot be parsed are ignored.
+func (conf Config) parseIgnoredMapKeysAt() (parsed []parsedKeysAt) {
+	for s, keys := range conf.IgnoredMapKeysAt {
+		for _, steps := range parsePaths([]string{s}) {
+			parsed = append(parsed, parsedKeysAt{steps, keys})
+		}
+	}
+	return parsed
+}
+
+// ignoredKeys returns the keys to be ignored in the comparison of the maps
+// of the given type at the path p.
+func (cmp *comparison) ignoredKeys(conf Config, typ reflect.Type, p path) (keys []interface{}) {
+	keys = conf.IgnoredMapKeys[typ]
+	for _, k := range cmp.keysAt {
+		if matchPath(k.steps, p) {
+			keys = append(keys[:len(keys):len(keys)], k.keys...)
+		}
+	}
+	return keys
+}
+
+// isIgnoredKey reports whether the map key is one of the given keys.
+func isIgnoredKey(key reflect.Value, keys []interface{}) bool {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	for _, k := range keys {
+		kv := reflect.ValueOf(k)
+		if !kv.IsValid() || !key.IsValid() {
+			continue
+		}
+		if kv.Type() != key.Type() {
+			if kv.Kind() != key.Kind() || !kv.Type().ConvertibleTo(key.Type()) {
+				continue
+			}
+			kv = kv.Convert(key.Type())
+		}
+		if kv.Equal(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePaths parses the given paths, the paths that cannot be parsed are
+// ignored. The leading dot of a path that starts with a field name may be
+// left out, e.g. "Publisher.HQ".
+func parsePaths(paths []string) (parsed [][]pathStep) {
+	for _, s := range paths {
+		if len(s) > 0 && s[0] != '.' && s[0] != '[' {
+			s = "." + s
+		}
+		if steps, err := parsePath(s); err == nil {
+			parsed = append(parsed, steps)
+		}
+	}
+	return parsed
+}
+
+// matchAnyPath reports whether any of the parsed paths matches the path p.
+func matchAnyPath(paths [][]pathStep, p path) bool {
+	for _, steps := range paths {
+		if matchPath(steps, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether the parsed steps match the path p. The wildcard
+// steps match any field, or any index or key, respectively.
+func matchPath(steps []pathStep, p path) bool {
+	if len(p) > 0 {
+		if _, ok := p[0].(rootnode); ok {
+			p = p[1:]
+		}
+	}
+	if len(steps) != len(p) {
+		return false
+	}
+	for i, step := range steps {
+		switch n := p[i].(type) {
+		case structnode:
+			if step.index || (step.field != n.field && !step.wildcard) {
+				return false
+			}
+		case arrnode:
+			if !step.index || (step.key != strconv.Itoa(n.index) && !step.wildcard) {
+				return false
+			}
+		case mapnode:
+			if !step.index || (step.key != (printer{}).value("%v", n.key) && !step.wildcard) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// compareMapIgnoreKeys compares the contents of the two map values excluding
+// the entries with the ignored keys. Since the ignored entries may be present
+// in only one of the maps, the lengths of the maps are not compared, instead
+// the keys missing from either of the maps are reported.
+func (conf Config) compareMapIgnoreKeys(got, want reflect.Value, keys []interface{}, cmp *comparison, p path) {
+	diffs := conf.newElemDiffs(cmp)
+	for _, key := range want.MapKeys() {
+		if isIgnoredKey(key, keys) {
+			continue
+		}
+		q := p.add(mapnode{key})
+		valGot := got.MapIndex(key)
+
+		mark := diffs.mark()
+		if !valGot.IsValid() {
+			cmp.errs.add(&validityError{valGot, want.MapIndex(key), q, key})
+		} else {
+			conf.compare(valGot, want.MapIndex(key), cmp, q)
+		}
+		diffs.check(mark)
+	}
+	for _, key := range got.MapKeys() {
+		if want.MapIndex(key).IsValid() || isIgnoredKey(key, keys) {
+			continue
+		}
+		mark := diffs.mark()
+		cmp.errs.add(&validityError{got.MapIndex(key), reflect.Value{}, p.add(mapnode{key}), key})
+		diffs.check(mark)
+	}
+	diffs.done(want.Kind(), p)
+}
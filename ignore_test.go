@@ -0,0 +1,169 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIgnoreMapKeys(t *testing.T) {
+	type Key string
+	type T struct {
+		Meta   map[string]interface{}
+		Labels map[Key]int
+		Items  []map[string]interface{}
+	}
+	mtyp := rtof(map[string]interface{}{})
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  error
+	}{{
+		conf: Config{}.IgnoreMapKeys(mtyp, "updated_at", "trace_id"),
+		a: T{
+			Meta:  map[string]interface{}{"id": 1, "updated_at": "x", "nested": map[string]interface{}{"trace_id": "a"}},
+			Items: []map[string]interface{}{{"id": 2, "trace_id": "b"}},
+		},
+		b: T{
+			Meta:  map[string]interface{}{"id": 1, "updated_at": "y", "nested": map[string]interface{}{}},
+			Items: []map[string]interface{}{{"id": 2}},
+		},
+		err: nil,
+	}, {
+		conf: Config{}.IgnoreMapKeys(mtyp, "updated_at"),
+		a:    map[string]interface{}{"a": 1, "updated_at": 1},
+		b:    map[string]interface{}{"b": 1},
+		err: elist(&validityError{
+			got: reflect.Value{}, want: rvof(1),
+			path: path{rootnode{mtyp}, mapnode{rvof("b")}},
+			key:  rvof("b"),
+		}, &validityError{
+			got: rvof(1), want: reflect.Value{},
+			path: path{rootnode{mtyp}, mapnode{rvof("a")}},
+			key:  rvof("a"),
+		}),
+	}, {
+		// untyped keys are converted to the map's key type
+		conf: Config{}.IgnoreMapKeys(rtof(map[Key]int{}), "x"),
+		a:    T{Labels: map[Key]int{"x": 1, "y": 2}},
+		b:    T{Labels: map[Key]int{"y": 2}},
+		err:  nil,
+	}, {
+		conf: Config{}.IgnoreMapKeysAt(".Meta", "updated_at"),
+		a:    T{Meta: map[string]interface{}{"updated_at": 1}, Items: []map[string]interface{}{{"updated_at": 1}}},
+		b:    T{Meta: map[string]interface{}{"updated_at": 2}, Items: []map[string]interface{}{{"updated_at": 2}}},
+		err: elist(&valueError{
+			got: 1, want: 2,
+			path: path{rootnode{rtof(T{})}, structnode{"Items"}, arrnode{0}, mapnode{rvof("updated_at")}},
+		}),
+	}, {
+		conf: Config{}.IgnoreMapKeysAt(".Items[0]", "updated_at").IgnoreMapKeysAt(".Meta", "updated_at"),
+		a:    T{Meta: map[string]interface{}{"updated_at": 1}, Items: []map[string]interface{}{{"updated_at": 1}}},
+		b:    T{Meta: map[string]interface{}{"updated_at": 2}, Items: []map[string]interface{}{{"updated_at": 2}}},
+		err:  nil,
+	}, {
+		// the leading dot of the path may be left out
+		conf: Config{}.IgnoreMapKeysAt("Items[*]", "updated_at").IgnoreMapKeysAt("Meta", "updated_at"),
+		a:    T{Meta: map[string]interface{}{"updated_at": 1}, Items: []map[string]interface{}{{"updated_at": 1}}},
+		b:    T{Meta: map[string]interface{}{"updated_at": 2}, Items: []map[string]interface{}{{"updated_at": 2}}},
+		err:  nil,
+	}, {
+		conf: Config{}.IgnoreMapKeysAt("", "updated_at"),
+		a:    map[string]interface{}{"id": 1, "updated_at": 1},
+		b:    map[string]interface{}{"id": 1, "updated_at": 2},
+		err:  nil,
+	}}
+
+	for _, tt := range tests {
+		err := tt.conf.Compare(tt.a, tt.b)
+		if errstr(err) != errstr(tt.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", tt.a, tt.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(tt.err))
+		}
+		if eq := tt.conf.EqualNoReport(tt.a, tt.b); eq != (tt.err == nil) {
+			t.Errorf("EqualNoReport(%v, %v) got=%t, want=%t", tt.a, tt.b, eq, tt.err == nil)
+		}
+	}
+
+	// the receiver is not modified
+	conf := Config{}.IgnoreMapKeys(mtyp, "a")
+	_ = conf.IgnoreMapKeys(mtyp, "b")
+	if got := conf.IgnoredMapKeys[mtyp]; len(got) != 1 {
+		t.Errorf("IgnoreMapKeys modified the receiver, got=%v", got)
+	}
+}
+
+func TestCompareIgnorePaths(t *testing.T) {
+	type Author struct {
+		ID         int
+		FirstName  string
+		MiddleName string
+	}
+	type Publisher struct {
+		Name string
+		HQ   string
+	}
+	type Book struct {
+		ID        int
+		Authors   []Author
+		Publisher Publisher
+		Meta      map[string]int
+	}
+	a := Book{
+		ID:        1,
+		Authors:   []Author{{1, "Joe", "A"}, {2, "Jane", "B"}},
+		Publisher: Publisher{"P", "Berlin"},
+		Meta:      map[string]int{"rev": 1, "size": 10},
+	}
+	b := Book{
+		ID:        2,
+		Authors:   []Author{{3, "Joe", "X"}, {4, "Jane", "Y"}},
+		Publisher: Publisher{"P", "Paris"},
+		Meta:      map[string]int{"rev": 2, "size": 10},
+	}
+
+	tests := []struct {
+		conf Config
+		err  string
+	}{{
+		conf: Config{IgnorePaths: []string{"ID", ".Publisher.HQ", "Authors[*].MiddleName", `Meta["rev"]`, ".Authors[*].ID"}},
+		err:  "",
+	}, {
+		conf: Config{IgnorePaths: []string{"ID", "Publisher.HQ", "Authors[1].*", ".Meta[*]"}},
+		err: "- (compare.Book).Authors[0].ID: Value mismatch; got=1, want=3\n" +
+			"- (compare.Book).Authors[0].MiddleName: Value mismatch; got=\"A\", want=\"X\"; differs at byte 0 (rune 0)\n",
+	}, {
+		conf: Config{IgnorePaths: []string{".Publisher", ".Authors", ".Meta", "[oops"}},
+		err:  "- (compare.Book).ID: Value mismatch; got=1, want=2\n",
+	}, {
+		conf: Config{IgnorePaths: []string{".Authors", ".Meta", ".ID"}, AuditSkippedFields: true},
+		err: "- (compare.Book).Authors: Field skipped by IgnorePaths\n" +
+			"- (compare.Book).ID: Field skipped by IgnorePaths\n" +
+			"- (compare.Book).Meta: Field skipped by IgnorePaths\n" +
+			"- (compare.Book).Publisher.HQ: Value mismatch; got=\"Berlin\", want=\"Paris\"; differs at byte 0 (rune 0)\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(a, b)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+		if eq := tt.conf.EqualNoReport(a, b); eq != (tt.err == "") {
+			t.Errorf("#%d: EqualNoReport() got=%t, want=%t", i, eq, tt.err == "")
+		}
+	}
+}
+
+func TestCompareIgnorePathsIgnoreArrayOrder(t *testing.T) {
+	type Elem struct {
+		Name    string
+		A, B, C int
+	}
+	got := []Elem{{"y", 1, 1, 1}, {"x", 0, 0, 0}}
+	want := []Elem{{"x", 1, 1, 1}, {"y", 2, 2, 2}}
+
+	// the elements are matched, and paired, with the ignored fields left out
+	conf := Config{IgnoreArrayOrder: true, IgnorePaths: []string{"[*].A", "[*].B", "[*].C"}}
+	if err := conf.Compare(got, want); err != nil {
+		t.Errorf("Compare() = %v, want <nil>", err)
+	}
+}
@@ -0,0 +1,245 @@
+package compare
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// CompareJSONBody is a wrapper around DefaultConfig.CompareJSONBody. The given
+// options, if any, are applied to a copy of the DefaultConfig, see Config.With.
+func CompareJSONBody(resp *http.Response, want interface{}, opts ...Option) error {
+	return DefaultConfig.With(opts...).CompareJSONBody(resp, want)
+}
+
+// CompareJSONBody checks that the given response has a JSON content type,
+// i.e. "application/json" or a type with the "+json" suffix, decodes the
+// response's body and compares it to the want value. The want value is
+// compared in its JSON form, that is, it is first marshaled and then
+// unmarshaled into an interface{} value just like the body, so struct
+// fields are matched by their JSON names. The Matchers of the want value
+// at the positions of the interface{} and error types are retained as they
+// are. The paths of the returned errors are rendered as JSON Pointers.
+//
+// The numbers are decoded as json.Number values, so that no precision is
+// lost, and they are compared by their numeric value, e.g. 1 and 1.0 are
+// equal, unless the Comparers have a comparer of the json.Number type.
+//
+// The response's body is read in full and replaced by a new reader with
+// the same content so that it can be read again by the caller.
+func (conf Config) CompareJSONBody(resp *http.Response, want interface{}) error {
+	ct := resp.Header.Get("Content-Type")
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil || (mt != "application/json" && !strings.HasSuffix(mt, "+json")) {
+		return fmt.Errorf("compare: response content type %q is not JSON", ct)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("compare: failed to read response body: %w", err)
+	}
+
+	gotv, err := decodeJSON(body)
+	if err != nil {
+		return fmt.Errorf("compare: failed to decode response body: %w", err)
+	}
+	wantv, err := jsonValue(want)
+	if err != nil {
+		return fmt.Errorf("compare: failed to encode want value: %w", err)
+	}
+
+	if _, ok := conf.Comparers[jsonNumberType]; !ok {
+		conf = conf.With(WithComparer(jsonNumberType, jsonNumbersEqual))
+	}
+	conf.JSONPointerPaths = true
+	return conf.Compare(gotv, wantv)
+}
+
+// decodeJSON decodes the JSON data into an interface{} value, the numbers
+// are decoded as json.Number values.
+func decodeJSON(data []byte) (v interface{}, err error) {
+	// the data is validated first for the errors, and their messages, to
+	// be the same as those of json.Unmarshal, e.g. for any trailing data
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// jsonValue returns the given value in its JSON form. The Matchers of the
+// value are retained, see jsonEncoder.
+func jsonValue(v interface{}) (interface{}, error) {
+	if m, ok := v.(Matcher); ok {
+		return m, nil
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	e := &jsonEncoder{matchers: make(map[string]Matcher), visits: make(map[uintptr]bool)}
+	data, err := json.Marshal(e.replace(reflect.ValueOf(v)).Interface())
+	if err != nil {
+		return nil, err
+	}
+	out, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return e.restore(out), nil
+}
+
+// jsonEncoder replaces the Matchers of a value with placeholders which are
+// encoded as unique strings, and after the value is decoded it replaces the
+// strings with the Matchers.
+type jsonEncoder struct {
+	matchers map[string]Matcher // the replaced Matchers keyed by their placeholders
+	visits   map[uintptr]bool   // track pointers being replaced
+}
+
+// jsonMatcher is the placeholder of a Matcher, it is an error so that it can
+// replace Matchers that are the values of the error type.
+type jsonMatcher string
+
+func (m jsonMatcher) Error() string { return string(m) }
+
+var (
+	jsonMatcherType   = reflect.TypeOf(jsonMatcher(""))
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// replace returns a copy of v in which the Matchers at the positions of the
+// interface types implemented by jsonMatcher are replaced by placeholders.
+// The values of the types with their own JSON encoding, and the unexported
+// fields of structs, are retained as they are.
+func (e *jsonEncoder) replace(v reflect.Value) reflect.Value {
+	if !v.IsValid() || isMarshaler(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		if m, ok := matcherOf(v); ok && jsonMatcherType.Implements(v.Type()) {
+			key := jsonMatcher(fmt.Sprintf("\x00compare.Matcher(%d)", len(e.matchers)))
+			e.matchers[string(key)] = m
+			out.Set(reflect.ValueOf(key))
+			return out
+		}
+		out.Set(e.replace(v.Elem()))
+		return out
+	case reflect.Ptr:
+		if v.IsNil() || e.visits[v.Pointer()] {
+			// cycles are left to be reported by json.Marshal
+			return v
+		}
+		e.visits[v.Pointer()] = true
+		defer delete(e.visits, v.Pointer())
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(e.replace(v.Elem()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(e.replace(v.Field(i)))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(e.replace(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(e.replace(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			out.SetMapIndex(it.Key(), e.replace(it.Value()))
+		}
+		return out
+	}
+	return v
+}
+
+// restore replaces the placeholders of the decoded JSON value v with the
+// Matchers they stand for.
+func (e *jsonEncoder) restore(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if m, ok := e.matchers[v]; ok {
+			return m
+		}
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = e.restore(x)
+		}
+	case []interface{}:
+		for i, x := range v {
+			v[i] = e.restore(x)
+		}
+	}
+	return v
+}
+
+// isMarshaler reports whether the values of the type t implement their own
+// JSON encoding.
+func isMarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	for _, t := range []reflect.Type{t, reflect.PointerTo(t)} {
+		if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonNumbersEqual compares two json.Number values by their numeric value.
+// The numbers are parsed with a precision high enough for any practical
+// number to be compared exactly.
+func jsonNumbersEqual(got, want interface{}) bool {
+	g, w := got.(json.Number), want.(json.Number)
+	if g == w {
+		return true
+	}
+	x, _, err1 := big.ParseFloat(string(g), 10, 512, big.ToNearestEven)
+	y, _, err2 := big.ParseFloat(string(w), 10, 512, big.ToNearestEven)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return x.Cmp(y) == 0
+}
@@ -0,0 +1,143 @@
+package compare
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func jsonResponse(contentType, body string) *http.Response {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", contentType)
+	io.WriteString(rec, body)
+	return rec.Result()
+}
+
+func TestCompareJSONBody(t *testing.T) {
+	type Author struct {
+		Name string `json:"name"`
+	}
+	type Book struct {
+		Title   string   `json:"title"`
+		Authors []Author `json:"authors"`
+	}
+
+	tests := []struct {
+		resp *http.Response
+		want interface{}
+		err  error
+	}{{
+		resp: jsonResponse("application/json", `{"title":"x","authors":[{"name":"a"}]}`),
+		want: Book{Title: "x", Authors: []Author{{Name: "a"}}},
+		err:  nil,
+	}, {
+		resp: jsonResponse("application/problem+json; charset=utf-8", `{"status":404}`),
+		want: map[string]int{"status": 404},
+		err:  nil,
+	}, {
+		resp: jsonResponse("text/plain", `{}`),
+		want: map[string]int{},
+		err:  errors.New(`compare: response content type "text/plain" is not JSON`),
+	}, {
+		resp: jsonResponse("application/json", `{"title":`),
+		want: map[string]int{},
+		err:  errors.New(`compare: failed to decode response body: unexpected end of JSON input`),
+	}, {
+		resp: jsonResponse("application/json", `{"title":"x","authors":[{"name":"b"}]}`),
+		want: Book{Title: "x", Authors: []Author{{Name: "a"}}},
+		err: pointerList(newStringError("b", "a", path{
+			rootnode{rtof(Book{})},
+			mapnode{rvof("authors")},
+			arrnode{0},
+			mapnode{rvof("name")},
+		})),
+	}, {
+		resp: jsonResponse("application/json", `{"a/b~c":1}`),
+		want: map[string]int{"a/b~c": 2},
+		err: pointerList(&valueError{
+			got: json.Number("1"), want: json.Number("2"),
+			path: path{rootnode{rtof(map[string]int{})}, mapnode{rvof("a/b~c")}},
+		}),
+	}}
+
+	for _, tt := range tests {
+		if err := CompareJSONBody(tt.resp, tt.want); errstr(err) != errstr(tt.err) {
+			t.Errorf("CompareJSONBody(%v) = %v\n\n", tt.want, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(tt.err))
+		}
+	}
+
+	// the body can be read again
+	resp := jsonResponse("application/json", `{}`)
+	CompareJSONBody(resp, map[string]int{})
+	if body, _ := io.ReadAll(resp.Body); string(body) != `{}` {
+		t.Errorf("body got=%q, want=%q", body, `{}`)
+	}
+}
+
+func TestCompareJSONBodyMatchers(t *testing.T) {
+	type Result struct {
+		ID    int64       `json:"id"`
+		Error error       `json:"error"`
+		Data  interface{} `json:"data"`
+	}
+
+	body := `{"id":9007199254740993,"error":"not found: x","data":{"items":[{"err":"bad input"}],"n":1.0}}`
+	want := Result{
+		ID:    9007199254740993,
+		Error: hasPrefix("not found"),
+		Data: map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"err": hasPrefix("bad")}},
+			"n":     1,
+		},
+	}
+	if err := CompareJSONBody(jsonResponse("application/json", body), want); err != nil {
+		t.Errorf("CompareJSONBody() = %v, want <nil>", err)
+	}
+
+	// the numbers are not rounded to float64
+	want.ID = 9007199254740992
+	if err := CompareJSONBody(jsonResponse("application/json", body), want); err == nil {
+		t.Errorf("CompareJSONBody() = <nil>, want an error for the id")
+	}
+	want.ID = 9007199254740993
+
+	// and the matchers are not encoded as JSON
+	body = `{"id":9007199254740993,"error":"x","data":{"items":[{"err":"bad input"}],"n":1}}`
+	err := CompareJSONBody(jsonResponse("application/json", body), want)
+	if diffs := Differences(err); len(diffs) != 1 || diffs[0].Kind != MatchDiff || diffs[0].Got != "x" {
+		t.Errorf("CompareJSONBody() = %v, want a single mismatch of /error", err)
+	}
+
+	// the options are applied
+	body = `{"id":9007199254740993,"error":"not found","data":{"items":[{"err":"bad"}],"n":2}}`
+	anyNumber := WithComparer(reflect.TypeOf(json.Number("")), func(got, want interface{}) bool { return true })
+	if err := CompareJSONBody(jsonResponse("application/json", body), want, anyNumber); err != nil {
+		t.Errorf("CompareJSONBody() = %v, want <nil>", err)
+	}
+}
+
+// hasPrefix is a Matcher of the strings with the given prefix, it is an error
+// so that it can be used as the value of fields of type error.
+type hasPrefix string
+
+func (m hasPrefix) Match(got interface{}) bool {
+	s, ok := got.(string)
+	return ok && strings.HasPrefix(s, string(m))
+}
+
+func (m hasPrefix) Error() string {
+	return fmt.Sprintf("hasPrefix(%q)", string(m))
+}
+
+func pointerList(errs ...error) *errorList {
+	list := elist(errs...)
+	list.pr.pointer = true
+	return list
+}
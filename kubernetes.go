@@ -0,0 +1,134 @@
+package compare
+
+import (
+	"strings"
+)
+
+// ManifestIgnorePaths holds the paths of the fields of a Kubernetes manifest
+// that are populated by the server and are therefore, by default, omitted by
+// CompareManifests from comparison.
+var ManifestIgnorePaths = []string{
+	"metadata.managedFields",
+	"metadata.resourceVersion",
+	"metadata.creationTimestamp",
+}
+
+// CompareManifests is a wrapper around DefaultConfig.CompareManifests.
+func CompareManifests(got, want map[string]interface{}, ignore ...string) error {
+	return DefaultConfig.CompareManifests(got, want, ignore...)
+}
+
+// CompareManifests compares two unstructured Kubernetes objects, e.g. a live
+// object and its desired manifest, omitting from the comparison the fields
+// listed in ManifestIgnorePaths as well as the fields at the given ignore paths,
+// which is useful for fields that are defaulted by the server.
+//
+// A path is a dot-separated list of object keys, e.g. "spec.replicas". The
+// "[*]" suffix on a key makes the rest of the path apply to every element of
+// the list stored under that key, e.g. "spec.containers[*].imagePullPolicy".
+// A dot that is part of a key must be escaped with a backslash, e.g.
+// `metadata.annotations.kubectl\.kubernetes\.io/last-applied-configuration`.
+//
+// The given objects are not modified.
+func (conf Config) CompareManifests(got, want map[string]interface{}, ignore ...string) error {
+	paths := make([][]string, 0, len(ManifestIgnorePaths)+len(ignore))
+	for _, s := range ManifestIgnorePaths {
+		paths = append(paths, splitManifestPath(s))
+	}
+	for _, s := range ignore {
+		paths = append(paths, splitManifestPath(s))
+	}
+
+	var gotv, wantv interface{}
+	if got != nil {
+		gotv = copyManifest(got)
+	}
+	if want != nil {
+		wantv = copyManifest(want)
+	}
+	for _, p := range paths {
+		deleteManifestPath(gotv, p)
+		deleteManifestPath(wantv, p)
+	}
+
+	if got == nil || want == nil {
+		// retain the nil-ness of the map arguments
+		return conf.Compare(got, want)
+	}
+	return conf.Compare(gotv, wantv)
+}
+
+// splitManifestPath splits the given path into its keys, a "[*]" suffix of
+// a key is split off into a key of its own.
+func splitManifestPath(s string) (keys []string) {
+	var key strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '.':
+			key.WriteByte('.')
+			i++
+		case c == '.':
+			keys = appendManifestKey(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(c)
+		}
+	}
+	return appendManifestKey(keys, key.String())
+}
+
+func appendManifestKey(keys []string, key string) []string {
+	if strings.HasSuffix(key, "[*]") {
+		return append(keys, strings.TrimSuffix(key, "[*]"), "[*]")
+	}
+	return append(keys, key)
+}
+
+// copyManifest returns a copy of the given value in which all of the objects
+// and lists are copied, the rest of the values are shared with the original.
+func copyManifest(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = copyManifest(val)
+		}
+		return m
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = copyManifest(val)
+		}
+		return s
+	}
+	return v
+}
+
+// deleteManifestPath deletes the field at the given path from the value.
+func deleteManifestPath(v interface{}, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(keys) == 1 {
+			delete(v, keys[0])
+			return
+		}
+		deleteManifestPath(v[keys[0]], keys[1:])
+	case []interface{}:
+		if keys[0] != "[*]" {
+			return
+		}
+		for _, elem := range v {
+			deleteManifestPath(elem, keys[1:])
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func manifest(replicas interface{}, policy string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":              "app",
+			"resourceVersion":   "12345",
+			"creationTimestamp": "2024-01-01T00:00:00Z",
+			"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "imagePullPolicy": policy},
+					},
+				},
+			},
+		},
+	}
+}
+
+func desired(replicas interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":        "app",
+			"annotations": map[string]interface{}{},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestCompareManifests(t *testing.T) {
+	ignore := []string{
+		"spec.template.spec.containers[*].imagePullPolicy",
+		`metadata.annotations.kubectl\.kubernetes\.io/last-applied-configuration`,
+	}
+
+	tests := []struct {
+		got, want map[string]interface{}
+		ignore    []string
+		err       error
+	}{{
+		got: manifest(3, "Always"), want: desired(3), ignore: ignore,
+		err: nil,
+	}, {
+		got: manifest(3, "Always"), want: manifest(3, "IfNotPresent"),
+		err: elist(newStringError("Always", "IfNotPresent", path{
+			rootnode{rtof(map[string]interface{}{})},
+			mapnode{rvof("spec")},
+			mapnode{rvof("template")},
+			mapnode{rvof("spec")},
+			mapnode{rvof("containers")},
+			arrnode{0},
+			mapnode{rvof("imagePullPolicy")},
+		})),
+	}, {
+		got: manifest(2, "Always"), want: desired(3), ignore: ignore,
+		err: elist(&valueError{
+			got: 2, want: 3,
+			path: path{
+				rootnode{rtof(map[string]interface{}{})},
+				mapnode{rvof("spec")},
+				mapnode{rvof("replicas")},
+			},
+		}),
+	}}
+
+	for _, tt := range tests {
+		if err := CompareManifests(tt.got, tt.want, tt.ignore...); errstr(err) != errstr(tt.err) {
+			t.Errorf("CompareManifests(%v, %v) = %v\n\n", tt.got, tt.want, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(tt.err))
+		}
+	}
+
+	// the arguments must not be modified
+	got := manifest(3, "Always")
+	if _ = CompareManifests(got, desired(3), ignore...); !reflect.DeepEqual(got, manifest(3, "Always")) {
+		t.Errorf("CompareManifests modified its argument: %v", got)
+	}
+}
@@ -0,0 +1,20 @@
+package compare
+
+import (
+	"reflect"
+	"sync"
+)
+
+// lockTypes are the types of the sync package whose struct fields are omitted
+// from comparison, see AuditSkippedFields.
+var lockTypes = map[reflect.Type]bool{
+	reflect.TypeOf(sync.Mutex{}):     true,
+	reflect.TypeOf(sync.RWMutex{}):   true,
+	reflect.TypeOf(sync.WaitGroup{}): true,
+	reflect.TypeOf(sync.Once{}):      true,
+}
+
+// isLockType reports whether typ is one of the lock types of the sync package.
+func isLockType(typ reflect.Type) bool {
+	return lockTypes[typ]
+}
@@ -0,0 +1,54 @@
+package compare
+
+import (
+	"sync"
+	"testing"
+)
+
+type lockedCounter struct {
+	sync.Mutex
+	mu   sync.RWMutex
+	wg   sync.WaitGroup
+	once sync.Once
+	N    int
+}
+
+func TestCompareLocks(t *testing.T) {
+	a, b := &lockedCounter{N: 1}, &lockedCounter{N: 1}
+	a.Lock()
+	a.mu.RLock()
+	a.wg.Add(1)
+	a.once.Do(func() {})
+
+	if err := Compare(a, b); err != nil {
+		t.Errorf("Compare() got=%v, want=<nil>", err)
+	}
+	if !EqualNoReport(a, b) {
+		t.Errorf("EqualNoReport() got=false, want=true")
+	}
+	if err := (Config{Strict: true}).Compare(a, b); err == nil {
+		t.Errorf("Compare() with Strict got=<nil>, want an error")
+	}
+	if (Config{Strict: true}).EqualNoReport(a, b) {
+		t.Errorf("EqualNoReport() with Strict got=true, want=false")
+	}
+
+	want := "- (*compare.lockedCounter).Mutex: Field skipped by the lock type sync.Mutex\n" +
+		"- (*compare.lockedCounter).mu: Field skipped by the lock type sync.RWMutex\n" +
+		"- (*compare.lockedCounter).once: Field skipped by the lock type sync.Once\n" +
+		"- (*compare.lockedCounter).wg: Field skipped by the lock type sync.WaitGroup\n"
+	if got := Golden((Config{AuditSkippedFields: true}).Compare(a, b)); got != want {
+		t.Errorf("Compare() with AuditSkippedFields got:\n%s\nwant:\n%s", got, want)
+	}
+	for i, conf := range []Config{{AuditSkippedFields: true}, {AuditSkippedFields: true, SkippedFieldsAsWarnings: true}} {
+		if got, want := conf.EqualNoReport(a, b), conf.Compare(a, b) == nil; got != want {
+			t.Errorf("#%d: EqualNoReport() with AuditSkippedFields got=%t, want=%t", i, got, want)
+		}
+	}
+
+	b.N = 2
+	want = "- (*compare.lockedCounter).N: Value mismatch; got=1, want=2\n"
+	if got := Golden(Compare(a, b)); got != want {
+		t.Errorf("Compare() got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Matcher is implemented by the values that, when used in the want value,
+// match the corresponding got values by their own rules instead of being
+// compared to them. Matchers are recognized as the want value itself and
+// anywhere in the want value where the type of the position is an interface
+// type, e.g. as the value of a field of type error. The matchers are ignored
+// in Strict mode.
+type Matcher interface {
+	// Match reports whether the got value matches. The got value is nil
+	// if the corresponding value is invalid, e.g. a nil interface.
+	Match(got interface{}) bool
+}
+
+var matcherType = reflect.TypeOf((*Matcher)(nil)).Elem()
+
+// ErrorContains returns a matcher that matches non-nil errors whose message
+// contains the given substring. The matcher is itself an error so that it
+// can be used as the value of fields of type error.
+func ErrorContains(substr string) error {
+	return errorContains{substr}
+}
+
+type errorContains struct {
+	substr string
+}
+
+func (m errorContains) Match(got interface{}) bool {
+	err, ok := got.(error)
+	return ok && err != nil && strings.Contains(err.Error(), m.substr)
+}
+
+func (m errorContains) Error() string {
+	return fmt.Sprintf("ErrorContains(%q)", m.substr)
+}
+
+// ErrorIs returns a matcher that matches errors which have the target error
+// in their chains, as reported by errors.Is. The matcher is itself an error
+// so that it can be used as the value of fields of type error.
+func ErrorIs(target error) error {
+	return errorIs{target}
+}
+
+type errorIs struct {
+	target error
+}
+
+func (m errorIs) Match(got interface{}) bool {
+	err, _ := got.(error)
+	return errors.Is(err, m.target)
+}
+
+func (m errorIs) Error() string {
+	if m.target == nil {
+		return "ErrorIs(<nil>)"
+	}
+	return fmt.Sprintf("ErrorIs(%q)", m.target.Error())
+}
+
+// matcherOf returns the Matcher held by v, if v is a non-nil interface value
+// holding a Matcher. Only the values of interface types are checked since
+// those are the only positions, apart from the root, where a Matcher can be
+// used in place of a value of a different type, and since checking every value
+// would slow down the comparison considerably.
+func matcherOf(v reflect.Value) (Matcher, bool) {
+	if !v.IsValid() || v.Kind() != reflect.Interface || v.IsNil() {
+		return nil, false
+	}
+	if v = v.Elem(); !v.Type().Implements(matcherType) || !v.CanInterface() {
+		return nil, false
+	}
+	m, ok := v.Interface().(Matcher)
+	return m, ok
+}
+
+// matchValue returns the value to be passed to a Matcher's Match method.
+func matchValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if x, ok := interfaceOf(v); ok {
+		return x
+	}
+	return printer{}.value("%v", v)
+}
+
+// compareMatch matches the got value using the given Matcher.
+func (conf Config) compareMatch(m Matcher, got reflect.Value, cmp *comparison, p path) {
+	if x := matchValue(got); !m.Match(x) {
+		cmp.errs.add(&matchError{x, m, p})
+	}
+}
+
+type matchError struct {
+	got  interface{}
+	want Matcher
+	path path
+}
+
+func (err *matchError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *matchError) format(pr printer) string {
+	got := pr.color(gotColor, pr.value("%v", err.got))
+	want := pr.color(wantColor, pr.value("%v", err.want))
+	return fmt.Sprintf("%s: Match failure; got=%s, want=%s", err.path.format(pr), got, want)
+}
+
+func (err *matchError) differences() []Difference {
+	return []Difference{{err.path.relpath(), MatchDiff, err.got, err.want, nil}}
+}
@@ -0,0 +1,67 @@
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestCompareMatchers(t *testing.T) {
+	type Result struct {
+		N   int
+		Err error
+	}
+	refused := errors.New("dial tcp: connection refused")
+
+	tests := []CompareTest{
+		{a: Result{Err: refused}, b: Result{Err: ErrorContains("connection refused")}, err: nil},
+		{a: Result{Err: fmt.Errorf("read: %w", io.EOF)}, b: Result{Err: ErrorIs(io.EOF)}, err: nil},
+		{a: []error{io.EOF}, b: []error{ErrorIs(io.EOF)}, err: nil},
+		{a: refused, b: ErrorContains("refused"), err: nil},
+		{a: Result{}, b: Result{Err: ErrorIs(nil)}, err: nil},
+		{
+			a: Result{Err: refused}, b: Result{Err: ErrorContains("timeout")},
+			err: elist(&matchError{
+				got: refused, want: errorContains{"timeout"},
+				path: path{rootnode{rtof(Result{})}, structnode{field: "Err"}},
+			}),
+		}, {
+			a: Result{}, b: Result{Err: ErrorContains("timeout")},
+			err: elist(&matchError{
+				got: nil, want: errorContains{"timeout"},
+				path: path{rootnode{rtof(Result{})}, structnode{field: "Err"}},
+			}),
+		}, {
+			a: Result{Err: io.ErrUnexpectedEOF}, b: Result{Err: ErrorIs(io.EOF)},
+			err: elist(&matchError{
+				got: io.ErrUnexpectedEOF, want: errorIs{io.EOF},
+				path: path{rootnode{rtof(Result{})}, structnode{field: "Err"}},
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		err := Compare(test.a, test.b)
+		if errstr(err) != errstr(test.err) {
+			t.Errorf("Compare(%v, %v) = %v\n\n", test.a, test.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(test.err))
+		}
+		if got, want := EqualNoReport(test.a, test.b), test.err == nil; got != want {
+			t.Errorf("EqualNoReport(%v, %v) got=%t, want=%t", test.a, test.b, got, want)
+		}
+	}
+
+	// the message of a match failure
+	err := Compare(Result{Err: refused}, Result{Err: ErrorContains("timeout")})
+	want := `- (compare.Result).Err: Match failure; got=dial tcp: connection refused, want=ErrorContains("timeout")` + "\n"
+	if got := Golden(err); got != want {
+		t.Errorf("Golden() got=%q, want=%q", got, want)
+	}
+
+	// the matchers are ignored in Strict mode
+	conf := Config{Strict: true}
+	if err := conf.Compare(Result{Err: refused}, Result{Err: ErrorContains("refused")}); err == nil {
+		t.Errorf("Strict Compare() got=<nil>, want an error")
+	}
+}
@@ -0,0 +1,72 @@
+package compare
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// isNumber reports whether k is one of the integer or float kinds.
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// numbersEqual reports whether the two values of an integer or float kind
+// represent the same number. No conversion that could overflow or lose
+// precision is performed.
+func numbersEqual(got, want reflect.Value) bool {
+	gk, wk := numberClass(got.Kind()), numberClass(want.Kind())
+	if gk > wk {
+		got, want, gk, wk = want, got, wk, gk
+	}
+
+	switch {
+	case gk == intClass && wk == intClass:
+		return got.Int() == want.Int()
+	case gk == uintClass && wk == uintClass:
+		return got.Uint() == want.Uint()
+	case gk == floatClass && wk == floatClass:
+		// float32 to float64 conversion is exact
+		return got.Float() == want.Float()
+	case gk == intClass && wk == uintClass:
+		return got.Int() >= 0 && uint64(got.Int()) == want.Uint()
+	case gk == intClass && wk == floatClass:
+		return floatEqualsInt(want.Float(), new(big.Int).SetInt64(got.Int()))
+	case gk == uintClass && wk == floatClass:
+		return floatEqualsInt(want.Float(), new(big.Int).SetUint64(got.Uint()))
+	}
+	return false
+}
+
+// floatEqualsInt reports whether the float f is exactly equal to the integer i.
+func floatEqualsInt(f float64, i *big.Int) bool {
+	if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+		return false
+	}
+	bf := new(big.Float).SetFloat64(f)
+	return bf.Cmp(new(big.Float).SetInt(i)) == 0
+}
+
+type numClass int
+
+const (
+	intClass numClass = iota
+	uintClass
+	floatClass
+)
+
+func numberClass(k reflect.Kind) numClass {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uintClass
+	case reflect.Float32, reflect.Float64:
+		return floatClass
+	}
+	return intClass
+}
@@ -0,0 +1,54 @@
+package compare
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareLooseNumericTypes(t *testing.T) {
+	tests := []struct {
+		a, b  interface{}
+		equal bool
+	}{
+		{a: int(1), b: float64(1), equal: true},
+		{a: int8(-1), b: int64(-1), equal: true},
+		{a: uint8(255), b: int(255), equal: true},
+		{a: float32(0.5), b: float64(0.5), equal: true},
+		{a: float32(0.1), b: float64(0.1), equal: false},
+		{a: int(1), b: float64(1.5), equal: false},
+		{a: int(-1), b: uint64(math.MaxUint64), equal: false},
+		{a: int64(math.MaxInt64), b: uint64(math.MaxInt64), equal: true},
+		{a: int64(math.MaxInt64), b: uint64(math.MaxInt64 + 1), equal: false},
+		{a: uint64(math.MaxUint64), b: float64(math.MaxUint64), equal: false},
+		{a: uint64(1 << 63), b: float64(1 << 63), equal: true},
+		{a: int64(math.MinInt64), b: float64(math.MinInt64), equal: true},
+		{a: int64(1<<53 + 1), b: float64(1 << 53), equal: false},
+		{a: int64(1 << 53), b: float64(1 << 53), equal: true},
+		{a: uint64(1<<63 + 1), b: float64(1 << 63), equal: false},
+		{a: int(0), b: math.NaN(), equal: false},
+		{a: int(0), b: math.Inf(1), equal: false},
+		{a: int(0), b: math.Copysign(0, -1), equal: true},
+		{a: []interface{}{1, 2.0}, b: []interface{}{1.0, uint(2)}, equal: true},
+		{a: map[string]interface{}{"n": int64(3)}, b: map[string]interface{}{"n": float64(3)}, equal: true},
+		{a: int(1), b: "1", equal: false},
+	}
+
+	conf := Config{LooseNumericTypes: true}
+	for _, tt := range tests {
+		err := conf.Compare(tt.a, tt.b)
+		if (err == nil) != tt.equal {
+			t.Errorf("Compare(%T(%v), %T(%v)) = %v, want equal=%t", tt.a, tt.a, tt.b, tt.b, err, tt.equal)
+		}
+		if got := conf.EqualNoReport(tt.a, tt.b); got != tt.equal {
+			t.Errorf("EqualNoReport(%T(%v), %T(%v)) got=%t, want=%t", tt.a, tt.a, tt.b, tt.b, got, tt.equal)
+		}
+	}
+
+	want := elist(&valueError{
+		got: int64(1<<53 + 1), want: float64(1 << 53),
+		path: path{rootnode{rtof(float64(0))}},
+	})
+	if err := conf.Compare(int64(1<<53+1), float64(1<<53)); errstr(err) != errstr(want) {
+		t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(want))
+	}
+}
@@ -0,0 +1,70 @@
+package compare
+
+import (
+	"reflect"
+)
+
+// Option modifies a Config, it allows for the configuration of a single
+// comparison without having to construct a Config, e.g.
+//
+//	err := compare.Compare(got, want, compare.IgnoreOrder(), compare.WithTag("cmp"))
+type Option func(conf *Config)
+
+// With returns a copy of the Config with the given options applied to it in
+// the given order. The options do not modify the maps and slices of the
+// original Config.
+func (conf Config) With(opts ...Option) Config {
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	return conf
+}
+
+// IgnoreOrder returns an Option that sets IgnoreArrayOrder and IgnoreChanOrder.
+func IgnoreOrder() Option {
+	return func(conf *Config) {
+		conf.IgnoreArrayOrder = true
+		conf.IgnoreChanOrder = true
+	}
+}
+
+// WithTag returns an Option that sets ObserveFieldTag to the given tag.
+func WithTag(tag string) Option {
+	return func(conf *Config) {
+		conf.ObserveFieldTag = tag
+	}
+}
+
+// IgnoreFields returns an Option that adds the given names to IgnoreFieldNames.
+func IgnoreFields(names ...string) Option {
+	return func(conf *Config) {
+		n := len(conf.IgnoreFieldNames)
+		conf.IgnoreFieldNames = append(conf.IgnoreFieldNames[:n:n], names...)
+	}
+}
+
+// WithComparer returns an Option that adds the given function to the Comparers
+// as the comparer of the values of the given type.
+func WithComparer(typ reflect.Type, fn func(got, want interface{}) bool) Option {
+	return func(conf *Config) {
+		m := make(map[reflect.Type]func(got, want interface{}) bool, len(conf.Comparers)+1)
+		for t, f := range conf.Comparers {
+			m[t] = f
+		}
+		m[typ] = fn
+		conf.Comparers = m
+	}
+}
+
+// WithFormatter returns an Option that adds the given function to the Formatters
+// as the formatter of the values of the given type.
+func WithFormatter(typ reflect.Type, fn func(v interface{}) string) Option {
+	return func(conf *Config) {
+		m := make(map[reflect.Type]func(v interface{}) string, len(conf.Formatters)+1)
+		for t, f := range conf.Formatters {
+			m[t] = f
+		}
+		m[typ] = fn
+		conf.Formatters = m
+	}
+}
@@ -0,0 +1,69 @@
+package compare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareOptions(t *testing.T) {
+	type T struct {
+		ID   int
+		Name string `cmp:"-"`
+		Tags []string
+	}
+	fold := func(got, want interface{}) bool {
+		return strings.EqualFold(got.(string), want.(string))
+	}
+
+	tests := []struct {
+		a, b interface{}
+		opts []Option
+		want string
+	}{{
+		a: T{ID: 1, Tags: []string{"a", "b"}}, b: T{ID: 1, Tags: []string{"b", "a"}},
+		want: "- (compare.T).Tags[0]: Value mismatch; got=\"a\", want=\"b\"; differs at byte 0 (rune 0)\n" +
+			"- (compare.T).Tags[1]: Value mismatch; got=\"b\", want=\"a\"; differs at byte 0 (rune 0)\n",
+	}, {
+		a: T{ID: 1, Tags: []string{"a", "b"}}, b: T{ID: 1, Tags: []string{"b", "a"}},
+		opts: []Option{IgnoreOrder()},
+		want: "",
+	}, {
+		a: T{ID: 1, Name: "a"}, b: T{ID: 1, Name: "b"},
+		opts: []Option{WithTag("cmp")},
+		want: "",
+	}, {
+		a: T{ID: 1, Name: "a"}, b: T{ID: 2, Name: "b"},
+		opts: []Option{IgnoreFields("ID"), IgnoreFields("Name")},
+		want: "",
+	}, {
+		a: T{Tags: []string{"A"}}, b: T{Tags: []string{"a"}},
+		opts: []Option{WithComparer(reflect.TypeOf(""), fold)},
+		want: "",
+	}, {
+		a: 1, b: 2,
+		opts: []Option{WithFormatter(reflect.TypeOf(0), func(v interface{}) string { return "#" })},
+		want: "- (int): Value mismatch; got=#, want=#\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(Compare(tt.a, tt.b, tt.opts...)); got != tt.want {
+			t.Errorf("#%d: Compare got:\n%s\nwant:\n%s", i, got, tt.want)
+		}
+	}
+
+	if !reflect.DeepEqual(DefaultConfig, Config{}) {
+		t.Errorf("the options modified the DefaultConfig: %+v", DefaultConfig)
+	}
+}
+
+func TestConfigWith(t *testing.T) {
+	conf := Config{IgnoreFieldNames: make([]string, 1, 2)}
+	got := conf.With(IgnoreFields("A"), WithComparer(reflect.TypeOf(0), nil))
+	if !reflect.DeepEqual(got.IgnoreFieldNames, []string{"", "A"}) || len(got.Comparers) != 1 {
+		t.Errorf("With got=%+v", got)
+	}
+	if len(conf.IgnoreFieldNames[:2]) != 2 || conf.IgnoreFieldNames[:2][1] != "" || conf.Comparers != nil {
+		t.Errorf("With modified the original Config: %+v", conf)
+	}
+}
@@ -0,0 +1,318 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Plan is a wrapper around DefaultConfig.Plan.
+func Plan(got, want interface{}) string {
+	return DefaultConfig.Plan(got, want)
+}
+
+// Plan renders the structure of the want value annotated with markers that
+// describe how the got value differs from it, similar to the output of the
+// "terraform plan" command. Each line of the output is prefixed with one of
+// the following markers:
+//
+//	"+" the value is present in want but is missing in got
+//	"-" the value is present in got but is missing in want
+//	"~" the value is present in both but is different, changed leaf values
+//	    are rendered as "got -> want"
+//	" " the value is the same in both
+//
+// The values are compared using the same rules as those used by Compare and
+// the struct fields that are omitted from comparison are omitted from the plan.
+func (conf Config) Plan(got, want interface{}) string {
+	if conf.Strict {
+		conf = conf.strict()
+	}
+
+	pl := &plan{conf: conf, pr: conf.printer(), visits: make(map[visit]bool), cmp: newComparison()}
+	defer pl.cmp.release()
+	pl.cmp.keysAt = conf.parseIgnoredMapKeysAt()
+	pl.render(0, "", reflect.ValueOf(got), reflect.ValueOf(want), path{rootnode{reflect.TypeOf(want)}})
+	return strings.TrimRight(pl.buf.String(), "\n")
+}
+
+// plan holds the state of the Plan method.
+type plan struct {
+	conf   Config
+	pr     printer
+	buf    strings.Builder
+	visits map[visit]bool // track pointers already rendered
+	cmp    *comparison    // used for the selection of the struct fields and by equals
+}
+
+func (pl *plan) line(depth int, marker, label, value string) {
+	pl.buf.WriteString(strings.Repeat("    ", depth))
+	pl.buf.WriteString(marker)
+	pl.buf.WriteString(" ")
+	if len(label) > 0 {
+		pl.buf.WriteString(pl.pr.text(label))
+		if len(value) > 0 {
+			pl.buf.WriteString(" = ")
+		}
+	}
+	pl.buf.WriteString(pl.pr.text(value))
+	pl.buf.WriteString("\n")
+}
+
+// render writes the plan lines for the got and want values at the path p
+// under the given label.
+func (pl *plan) render(depth int, label string, got, want reflect.Value, p path) {
+	switch {
+	case !got.IsValid() && !want.IsValid():
+		pl.line(depth, " ", label, "<nil>")
+		return
+	case !got.IsValid():
+		pl.renderOne(depth, "+", label, want, p)
+		return
+	case !want.IsValid():
+		pl.renderOne(depth, "-", label, got, p)
+		return
+	}
+
+	equal := pl.conf.equals(got, want, pl.cmp, p)
+	if got.Type() != want.Type() {
+		if g, w, q, ok := pl.derefPointers(got, want, p); ok {
+			pl.render(depth, label, g, w, q)
+			return
+		}
+		if !pl.conf.AnonymousWantStructs || !isAnonymousWant(got.Type(), want.Type()) {
+			pl.leaf(depth, label, got, want, equal)
+			return
+		}
+	}
+
+	switch want.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if got.IsNil() || want.IsNil() {
+			break
+		}
+		if want.Kind() == reflect.Ptr {
+			v := visit{got.UnsafePointer(), want.UnsafePointer(), want.Type()}
+			if pl.visits[v] {
+				pl.line(depth, " ", label, "<cycle>")
+				return
+			}
+			pl.visits[v] = true
+			defer delete(pl.visits, v)
+		}
+		pl.render(depth, label, got.Elem(), want.Elem(), p)
+		return
+	case reflect.Struct:
+		if structIsTime(want) {
+			break
+		}
+		pl.line(depth, planMarker(equal), label, "{")
+		for i := 0; i < want.NumField(); i++ {
+			f := want.Type().Field(i)
+			q := p.add(structnode{f.Name})
+			if !pl.conf.selectField(want, i, pl.cmp, q) {
+				continue
+			}
+			g, w := planField(got, want, i), want.Field(i)
+			if pl.cmp.zero && g.IsValid() {
+				// only the "zero-ness" of the field is compared
+				pl.leaf(depth+1, f.Name, g, w, pl.conf.isZero(g) == pl.conf.isZero(w))
+			} else {
+				pl.render(depth+1, f.Name, g, w, q)
+			}
+			pl.cmp.zero = false
+		}
+		pl.line(depth, " ", "", "}")
+		return
+	case reflect.Slice, reflect.Array:
+		if want.Kind() == reflect.Slice && (got.IsNil() || want.IsNil()) {
+			break
+		}
+		pl.line(depth, planMarker(equal), label, "[")
+		for i := 0; i < got.Len() || i < want.Len(); i++ {
+			var g, w reflect.Value
+			if i < got.Len() {
+				g = got.Index(i)
+			}
+			if i < want.Len() {
+				w = want.Index(i)
+			}
+			pl.render(depth+1, "", g, w, p.add(arrnode{i}))
+		}
+		pl.line(depth, " ", "", "]")
+		return
+	case reflect.Map:
+		if got.IsNil() || want.IsNil() {
+			break
+		}
+		pl.line(depth, planMarker(equal), label, "{")
+		for _, key := range planMapKeys(got, want) {
+			pl.render(depth+1, planLeaf(key), got.MapIndex(key), want.MapIndex(key), p.add(mapnode{key}))
+		}
+		pl.line(depth, " ", "", "}")
+		return
+	}
+
+	pl.leaf(depth, label, got, want, equal)
+}
+
+// leaf writes the plan line for the got and want values rendered as leaf values.
+func (pl *plan) leaf(depth int, label string, got, want reflect.Value, equal bool) {
+	if equal {
+		pl.line(depth, " ", label, planLeaf(want))
+	} else {
+		pl.line(depth, "~", label, planLeaf(got)+" -> "+planLeaf(want))
+	}
+}
+
+// derefPointers dereferences the got or the want pointer at the path p to the
+// pointer depth of the other value, the way IgnorePointerDepth and AutoDeref
+// align them. The ok return value reports whether the values could be aligned.
+func (pl *plan) derefPointers(got, want reflect.Value, p path) (_, _ reflect.Value, _ path, ok bool) {
+	if !pl.conf.IgnorePointerDepth && !pl.conf.AutoDeref {
+		return got, want, p, false
+	}
+	gt, gn := ptrDepth(got.Type())
+	wt, wn := ptrDepth(want.Type())
+	if gt != wt || gn == wn {
+		return got, want, p, false
+	}
+	if !pl.conf.IgnorePointerDepth && gn-wn != 1 && wn-gn != 1 {
+		return got, want, p, false
+	}
+
+	node := derefnode{side: "got", n: gn - wn}
+	if gn < wn {
+		node = derefnode{side: "want", n: wn - gn}
+	}
+	for ; gn > wn; gn-- {
+		if got.IsNil() {
+			return got, want, p, false
+		}
+		got = got.Elem()
+	}
+	for ; wn > gn; wn-- {
+		if want.IsNil() {
+			return got, want, p, false
+		}
+		want = want.Elem()
+	}
+	return got, want, p.add(node), true
+}
+
+// renderOne writes the plan lines for a value at the path p that is present
+// on one side only.
+func (pl *plan) renderOne(depth int, marker, label string, v reflect.Value, p path) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			break
+		}
+		if v.Kind() == reflect.Ptr {
+			key := visit{v.UnsafePointer(), v.UnsafePointer(), v.Type()}
+			if pl.visits[key] {
+				pl.line(depth, marker, label, "<cycle>")
+				return
+			}
+			pl.visits[key] = true
+			defer delete(pl.visits, key)
+		}
+		pl.renderOne(depth, marker, label, v.Elem(), p)
+		return
+	case reflect.Struct:
+		if structIsTime(v) {
+			break
+		}
+		pl.line(depth, marker, label, "{")
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			q := p.add(structnode{f.Name})
+			if pl.conf.selectField(v, i, pl.cmp, q) {
+				pl.renderOne(depth+1, marker, f.Name, v.Field(i), q)
+			}
+			pl.cmp.zero = false
+		}
+		pl.line(depth, marker, "", "}")
+		return
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			break
+		}
+		pl.line(depth, marker, label, "[")
+		for i := 0; i < v.Len(); i++ {
+			pl.renderOne(depth+1, marker, "", v.Index(i), p.add(arrnode{i}))
+		}
+		pl.line(depth, marker, "", "]")
+		return
+	case reflect.Map:
+		if v.IsNil() {
+			break
+		}
+		pl.line(depth, marker, label, "{")
+		for _, key := range planMapKeys(v) {
+			pl.renderOne(depth+1, marker, planLeaf(key), v.MapIndex(key), p.add(mapnode{key}))
+		}
+		pl.line(depth, marker, "", "}")
+		return
+	}
+	pl.line(depth, marker, label, planLeaf(v))
+}
+
+// planField returns the field of the got struct that corresponds to the ith
+// field of the want struct. If the types of the structs differ, see
+// AnonymousWantStructs, the field is looked up by name and the result is
+// invalid if the got struct has no such field.
+func planField(got, want reflect.Value, i int) reflect.Value {
+	if got.Type() == want.Type() {
+		return got.Field(i)
+	}
+	f, ok := got.Type().FieldByName(want.Type().Field(i).Name)
+	if !ok {
+		return reflect.Value{}
+	}
+	v, err := got.FieldByIndexErr(f.Index)
+	if err != nil {
+		return reflect.Value{}
+	}
+	return v
+}
+
+func planMarker(equal bool) string {
+	if equal {
+		return " "
+	}
+	return "~"
+}
+
+// planLeaf returns the textual representation of a leaf value.
+func planLeaf(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return "<nil>"
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// planMapKeys returns the union of the keys of the given maps sorted by
+// their textual representation.
+func planMapKeys(maps ...reflect.Value) (keys []reflect.Value) {
+	seen := make(map[string]bool)
+	for _, m := range maps {
+		for _, key := range m.MapKeys() {
+			if s := planLeaf(key); !seen[s] {
+				seen[s] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return planLeaf(keys[i]) < planLeaf(keys[j])
+	})
+	return keys
+}
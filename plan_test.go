@@ -0,0 +1,177 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	type Item struct {
+		Name string
+		Tags []string
+		Meta map[string]int
+		Next *Item
+	}
+
+	tests := []struct {
+		got, want interface{}
+		plan      string
+	}{{
+		got: 1, want: 1,
+		plan: `  1`,
+	}, {
+		got: 1, want: 2,
+		plan: `~ 1 -> 2`,
+	}, {
+		got: 1, want: "1",
+		plan: `~ 1 -> "1"`,
+	}, {
+		got: nil, want: 1,
+		plan: `+ 1`,
+	}, {
+		got: &Item{Name: "a"}, want: &Item{Name: "a"},
+		plan: "  {\n" +
+			`      Name = "a"` + "\n" +
+			"      Tags = <nil>\n" +
+			"      Meta = <nil>\n" +
+			"      Next = <nil>\n" +
+			"  }",
+	}, {
+		got: &Item{
+			Name: "a",
+			Tags: []string{"x", "y"},
+			Meta: map[string]int{"a": 1, "b": 2},
+		},
+		want: &Item{
+			Name: "b",
+			Tags: []string{"x"},
+			Meta: map[string]int{"a": 1, "c": 3},
+			Next: &Item{Name: "c"},
+		},
+		plan: "~ {\n" +
+			`    ~ Name = "a" -> "b"` + "\n" +
+			"    ~ Tags = [\n" +
+			`          "x"` + "\n" +
+			`        - "y"` + "\n" +
+			"      ]\n" +
+			"    ~ Meta = {\n" +
+			`          "a" = 1` + "\n" +
+			`        - "b" = 2` + "\n" +
+			`        + "c" = 3` + "\n" +
+			"      }\n" +
+			"    ~ Next = <nil> -> &{c [] map[] <nil>}\n" +
+			"  }",
+	}, {
+		got:  []interface{}{1},
+		want: []interface{}{1, []int{2, 3}},
+		plan: "~ [\n" +
+			"      1\n" +
+			"    + [\n" +
+			"        + 2\n" +
+			"        + 3\n" +
+			"    + ]\n" +
+			"  ]",
+	}}
+
+	for _, tt := range tests {
+		if plan := Plan(tt.got, tt.want); plan != tt.plan {
+			t.Errorf("Plan(%v, %v) got:\n%s\nwant:\n%s", tt.got, tt.want, plan, tt.plan)
+		}
+	}
+}
+
+func TestPlanMismatchedTypes(t *testing.T) {
+	type T struct {
+		A int
+		B string
+	}
+
+	tests := []struct {
+		conf      Config
+		got, want interface{}
+		plan      string
+	}{{
+		conf: Config{AutoDeref: true},
+		got:  &T{A: 1}, want: T{A: 1},
+		plan: "  {\n" +
+			"      A = 1\n" +
+			`      B = ""` + "\n" +
+			"  }",
+	}, {
+		conf: Config{AutoDeref: true},
+		got:  T{A: 1}, want: &T{A: 2},
+		plan: "~ {\n" +
+			"    ~ A = 1 -> 2\n" +
+			`      B = ""` + "\n" +
+			"  }",
+	}, {
+		conf: Config{AutoDeref: true},
+		got:  (*T)(nil), want: T{A: 1},
+		plan: "~ <nil> -> {1 }",
+	}, {
+		conf: Config{AnonymousWantStructs: true},
+		got:  T{A: 1, B: "b"}, want: struct{ B string }{B: "b"},
+		plan: "  {\n" +
+			`      B = "b"` + "\n" +
+			"  }",
+	}, {
+		conf: Config{AnonymousWantStructs: true},
+		got:  []T{{A: 1, B: "b"}}, want: []struct{ B, C string }{{B: "c"}},
+		plan: "~ [\n" +
+			"    ~ {\n" +
+			`        ~ B = "b" -> "c"` + "\n" +
+			`        + C = ""` + "\n" +
+			"      }\n" +
+			"  ]",
+	}, {
+		conf: Config{LooseNumericTypes: true},
+		got:  int8(1), want: int64(1),
+		plan: "  1",
+	}}
+
+	for _, tt := range tests {
+		if plan := tt.conf.Plan(tt.got, tt.want); plan != tt.plan {
+			t.Errorf("Plan(%v, %v) got:\n%s\nwant:\n%s", tt.got, tt.want, plan, tt.plan)
+		}
+	}
+}
+
+func TestPlanFieldSelection(t *testing.T) {
+	type T struct {
+		A int
+		B int `cmp:"-"`
+		C int
+		D int `cmp:"+"`
+		E int
+		f int
+	}
+
+	conf := Config{
+		ObserveFieldTag:  "cmp",
+		IgnoreFieldNames: []string{"C"},
+		IgnoreUnexported: true,
+		FieldFilter: func(f reflect.StructField, path string) FieldRule {
+			if path == ".E" {
+				return SkipField
+			}
+			return CompareField
+		},
+	}
+	got := T{A: 1, B: 2, C: 3, D: 1, E: 5, f: 6}
+	want := T{A: 1, B: 3, C: 4, D: 2, E: 6, f: 7}
+	plan := "  {\n" +
+		"      A = 1\n" +
+		"      D = 2\n" +
+		"  }"
+	if got := conf.Plan(got, want); got != plan {
+		t.Errorf("Plan() got:\n%s\nwant:\n%s", got, plan)
+	}
+
+	plan = "+ {\n" +
+		"    + A = 1\n" +
+		"    + D = 2\n" +
+		"+ }"
+	if got := conf.Plan(nil, want); got != plan {
+		t.Errorf("Plan(nil) got:\n%s\nwant:\n%s", got, plan)
+	}
+}
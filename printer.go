@@ -0,0 +1,381 @@
+package compare
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// printer holds the options that control how the errors are rendered.
+type printer struct {
+	// If set, the output will contain no ANSI color codes and any
+	// non-ASCII or control characters will be escaped.
+	ascii bool
+	// If set, the paths are rendered as JSON Pointers.
+	pointer bool
+	// If set, the output will contain no ANSI color codes.
+	nocolor bool
+	// If set, the output will be rendered on a single line, i.e. the
+	// errors are separated by "; " and the newlines and other control
+	// characters of the rendered values are escaped.
+	oneline bool
+	// If set, the first difference of two strings is not highlighted.
+	nohighlight bool
+	// If set, the rendered floats are followed by their bit patterns.
+	floatbits bool
+	// If greater than 0, the single-line strings that differ are trimmed
+	// to their difference and the strctx bytes on either side of it.
+	strctx int
+	// If set, the rendered pointers, maps, slices, and channels are
+	// followed by their addresses.
+	addrs bool
+	// The per-type formatters of the rendered values.
+	formatters map[reflect.Type]func(v interface{}) string
+	// If set, the composite values are rendered in full instead of
+	// being summarized.
+	full bool
+	// If set, the output is rendered in its canonical form, i.e. with no
+	// ANSI color codes, with the errors of every list sorted by their text,
+	// and with the control characters of the rendered values escaped.
+	golden bool
+}
+
+func (conf Config) printer() printer {
+	return printer{
+		ascii:       conf.ASCII,
+		nocolor:     conf.NoColor,
+		nohighlight: conf.NoStringHighlight,
+		pointer:     conf.JSONPointerPaths,
+		floatbits:   conf.FloatBits,
+		addrs:       conf.ShowAddresses,
+		strctx:      conf.StringContext,
+
+		formatters: conf.Formatters,
+	}
+}
+
+// formatter is implemented by the errors of this package.
+type formatter interface {
+	format(pr printer) string
+}
+
+// error renders the given error.
+func (pr printer) error(err error) string {
+	if el, ok := err.(*errorList); ok {
+		return pr.list(el.List)
+	}
+	if f, ok := err.(formatter); ok {
+		return f.format(pr)
+	}
+	return pr.text(err.Error())
+}
+
+// list renders the given list of errors.
+func (pr printer) list(errs []error) string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = pr.error(err)
+	}
+	if pr.golden {
+		sort.Strings(lines)
+	}
+	return strings.Join(lines, pr.newline())
+}
+
+// newline returns the separator of the lines of the output.
+func (pr printer) newline() string {
+	if pr.oneline {
+		return "; "
+	}
+	return "\n"
+}
+
+// colored reports whether the output may contain ANSI color codes.
+func (pr printer) colored() bool {
+	return colorOutput && !pr.ascii && !pr.nocolor && !pr.golden && !test2json()
+}
+
+// colorOutput reports whether the errors may be rendered in color at all.
+var colorOutput = detectColor()
+
+// detectColor reports whether the environment supports colored output, i.e.
+// whether the standard output is a terminal, see Config.NoColor.
+func detectColor() bool {
+	if _, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		return true
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// test2json reports whether the output of the test binary is converted to JSON,
+// i.e. whether the tests were run by "go test -json", in which case the colors
+// are disabled even if FORCE_COLOR is set, since the consumers of the JSON
+// output would show the ANSI codes verbatim. The flag is looked up on every
+// call because the testing flags are registered after the package's init.
+var test2json = func() bool {
+	f := flag.Lookup("test.v")
+	return f != nil && f.Value.String() == "test2json"
+}
+
+// color wraps the string s in the given ANSI color.
+func (pr printer) color(color, s string) string {
+	if !pr.colored() {
+		return s
+	}
+	return color + s + stopColor
+}
+
+// format renders the value v, which is either a value or a reflect.Value of
+// one, using the formatter of its type, if there is one.
+func (pr printer) format(v interface{}) (string, bool) {
+	if len(pr.formatters) == 0 {
+		return "", false
+	}
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	if !rv.IsValid() {
+		return "", false
+	}
+	fn, ok := pr.formatters[rv.Type()]
+	if !ok {
+		return "", false
+	}
+	if v, ok = interfaceOf(rv); !ok {
+		return "", false
+	}
+	return fn(v), true
+}
+
+// value renders the value v according to the given format. The time.Time
+// values are, regardless of the format, rendered in UTC using the RFC 3339
+// layout so that any two rendered times are visually comparable.
+func (pr printer) value(format string, v interface{}) string {
+	if s, ok := pr.format(v); ok {
+		return pr.text(s)
+	}
+	if t, ok := asTime(v); ok {
+		return pr.text(t.UTC().Format(time.RFC3339Nano))
+	}
+	if f, bits, ok := asFloat(v); ok {
+		return pr.float(f, bits)
+	}
+	return pr.text(fmt.Sprintf(format, v))
+}
+
+// maxElems is the number of the elements of a composite value that are
+// rendered in its summary.
+const maxElems = 3
+
+// composite renders the map, slice, or array value v, unless the printer is
+// in full mode, as a summary consisting of the value's type, its length, and
+// its first few elements, values of other kinds are rendered in full. The map
+// elements are summarized in the order of their rendered keys.
+func (pr printer) composite(v reflect.Value) string {
+	if s, ok := pr.format(v); ok {
+		return pr.text(s)
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return pr.value("%#v", v)
+	}
+	if pr.full || v.Len() <= maxElems {
+		return pr.value("%#v", v)
+	}
+
+	var elems []string
+	if v.Kind() == reflect.Map {
+		keys := make([]string, 0, v.Len())
+		vals := make(map[string]reflect.Value, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			k := fmt.Sprintf("%#v", it.Key())
+			keys = append(keys, k)
+			vals[k] = it.Value()
+		}
+		sort.Strings(keys)
+		for _, k := range keys[:maxElems] {
+			elems = append(elems, fmt.Sprintf("%s:%#v", k, vals[k]))
+		}
+	} else {
+		for i := 0; i < maxElems; i++ {
+			elems = append(elems, fmt.Sprintf("%#v", v.Index(i)))
+		}
+	}
+	s := fmt.Sprintf("%s(len=%d){%s, ...}", v.Type(), v.Len(), strings.Join(elems, ", "))
+	return pr.text(s)
+}
+
+// addr returns the address of the value v, if v is a non-nil pointer,
+// map, slice, or channel, formatted as a suffix to the value's rendering.
+func (pr printer) addr(v reflect.Value) string {
+	if !pr.addrs || !v.IsValid() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.UnsafePointer:
+		if !v.IsNil() {
+			return fmt.Sprintf(" (%#x)", v.Pointer())
+		}
+	}
+	return ""
+}
+
+// float renders the float f of the given bit size with the smallest number
+// of digits necessary to represent it exactly, so that any two different
+// floats are rendered differently.
+func (pr printer) float(f float64, bits int) string {
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if pr.floatbits {
+		if bits == 32 {
+			s += fmt.Sprintf(" (%#08x)", math.Float32bits(float32(f)))
+		} else {
+			s += fmt.Sprintf(" (%#016x)", math.Float64bits(f))
+		}
+	}
+	return s
+}
+
+// asFloat returns the float held by v, which is either a float or
+// a reflect.Value of one, along with its bit size.
+func asFloat(v interface{}) (f float64, bits int, ok bool) {
+	switch x := v.(type) {
+	case float32:
+		return float64(x), 32, true
+	case float64:
+		return x, 64, true
+	case reflect.Value:
+		if !x.IsValid() {
+			return 0, 0, false
+		}
+		switch x.Kind() {
+		case reflect.Float32:
+			return x.Float(), 32, true
+		case reflect.Float64:
+			return x.Float(), 64, true
+		}
+	}
+	return 0, 0, false
+}
+
+// asTime returns the time.Time held by v, which is either a time.Time
+// or a reflect.Value of one.
+func asTime(v interface{}) (time.Time, bool) {
+	if rv, ok := v.(reflect.Value); ok {
+		if !rv.IsValid() || rv.Kind() != reflect.Struct || !structIsTime(rv) {
+			return time.Time{}, false
+		}
+		if v, ok = interfaceOf(rv); !ok {
+			return time.Time{}, false
+		}
+	}
+	t, ok := v.(time.Time)
+	return t, ok
+}
+
+// text returns the string s with all of its control characters escaped, and
+// in ASCII mode also all of its non-ASCII characters. The control characters
+// are escaped in every mode so that the rendered values, including any ANSI
+// escape sequences they contain, cannot break the lines of the output or
+// interfere with its colors.
+func (pr printer) text(s string) string {
+	if pr.ascii {
+		return escapeASCII(s)
+	}
+	return escapeControl(s)
+}
+
+// ellipsis returns the mark of a trimmed part of a rendered string, if trimmed
+// is set, or an empty string otherwise.
+func (pr printer) ellipsis(trimmed bool) string {
+	if !trimmed {
+		return ""
+	} else if pr.ascii {
+		return "..."
+	}
+	return "…"
+}
+
+// escapeControl returns the string s with all of its control characters
+// escaped using Go's escape sequences.
+func escapeControl(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			q := strconv.QuoteRune(r)
+			b.WriteString(q[1 : len(q)-1])
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isInvisible reports whether r is an invisible character, i.e. a format
+// character like the byte order mark or the zero-width space, or a space
+// character other than the ASCII space like the no-break space.
+func isInvisible(r rune) bool {
+	return r != ' ' && unicode.In(r, unicode.Cf, unicode.Zs, unicode.Zl, unicode.Zp)
+}
+
+// stripInvisible returns the string s as it would be seen, i.e. with its
+// format characters removed and its space characters replaced by the ASCII
+// space.
+func stripInvisible(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Cf, r) {
+			return -1
+		} else if isInvisible(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// escapeInvisible returns the string s with all of its invisible characters
+// escaped using Go's escape sequences.
+func escapeInvisible(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if isInvisible(r) {
+			q := strconv.QuoteRuneToASCII(r)
+			b.WriteString(q[1 : len(q)-1])
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeASCII returns the string s with all of its non-ASCII and control
+// characters escaped using Go's escape sequences.
+func escapeASCII(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && w == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r < utf8.RuneSelf && strconv.IsPrint(r):
+			b.WriteRune(r)
+		default:
+			q := strconv.QuoteRuneToASCII(r)
+			b.WriteString(q[1 : len(q)-1])
+		}
+		i += w
+	}
+	return b.String()
+}
@@ -0,0 +1,477 @@
+package compare
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// detectTest2JSON is the test2json check that is disabled by TestMain.
+var detectTest2JSON = test2json
+
+func TestMain(m *testing.M) {
+	// the tests expect the colors regardless of where their output goes
+	colorOutput = true
+	test2json = func() bool { return false }
+	os.Exit(m.Run())
+}
+
+func Test_detectColor(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	if !detectColor() {
+		t.Errorf("detectColor() with FORCE_COLOR got=false, want=true")
+	}
+	os.Unsetenv("FORCE_COLOR")
+	t.Setenv("NO_COLOR", "1")
+	if detectColor() {
+		t.Errorf("detectColor() with NO_COLOR got=true, want=false")
+	}
+}
+
+func Test_test2json(t *testing.T) {
+	v := flag.Lookup("test.v").Value.String()
+	if v == "test2json" {
+		t.Skip("the tests are run by go test -json")
+	}
+	test2json = detectTest2JSON
+	defer func() { test2json = func() bool { return false } }()
+
+	err := Compare("ab", "ac")
+	if !strings.Contains(err.Error(), "\033[") {
+		t.Fatalf("Compare() got=%q, want colored output", err.Error())
+	}
+
+	// no output may be written while the flag is set
+	flag.Set("test.v", "test2json")
+	got := err.Error()
+	flag.Set("test.v", v)
+
+	want := "- (string): Value mismatch; got=\"ab\", want=\"ac\"; differs at byte 1 (rune 1)"
+	if got != want {
+		t.Errorf("Error() with -test.v=test2json got=%q, want=%q", got, want)
+	}
+}
+
+func TestCompareNoColor(t *testing.T) {
+	type T struct {
+		S string
+		N []int
+	}
+	a, b := T{S: "ab", N: []int{1}}, T{S: "ac", N: []int{1, 2}}
+
+	if err := Compare(a, b); !strings.Contains(err.Error(), "\033[") {
+		t.Errorf("Compare() got=%q, want colored output", err.Error())
+	}
+
+	want := "- (compare.T).S: Value mismatch; got=\"ab\", want=\"ac\"; differs at byte 1 (rune 1)\n" +
+		"- (compare.T).N: Length of slice mismatch; got=1, want=2"
+	if err := (Config{NoColor: true}).Compare(a, b); err.Error() != want {
+		t.Errorf("Compare() with NoColor got=%q, want=%q", err.Error(), want)
+	}
+
+	colorOutput = false
+	defer func() { colorOutput = true }()
+	err := Compare(a, b)
+	if got := err.Error(); got != want {
+		t.Errorf("Compare() without color output got=%q, want=%q", got, want)
+	}
+	for _, e := range err.(*errorList).List {
+		if strings.Contains(e.Error(), "\033[") {
+			t.Errorf("Error() without color output got=%q, want plain text", e.Error())
+		}
+	}
+}
+
+func Test_escapeASCII(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{s: "", want: ""},
+		{s: "hello world", want: "hello world"},
+		{s: "a\nb\tc\r", want: `a\nb\tc\r`},
+		{s: "\033[91mred\033[0m", want: `\x1b[91mred\x1b[0m`},
+		{s: "café", want: `caf\u00e9`},
+		{s: "海辺", want: `\u6d77\u8fba`},
+		{s: "😀", want: `\U0001f600`},
+		{s: "\xff", want: `\xff`},
+		{s: "\x7f", want: `\x7f`},
+	}
+
+	for i, tt := range tests {
+		if got := escapeASCII(tt.s); got != tt.want {
+			t.Errorf("#%d: escapeASCII(%q) got=%q, want=%q", i, tt.s, got, tt.want)
+		}
+	}
+}
+
+func Test_escapeInvisible(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{s: "", want: ""},
+		{s: "hello world", want: "hello world"},
+		{s: "\ufeffhello", want: `\ufeffhello`},
+		{s: "a\u200bb", want: `a\u200bb`},
+		{s: "a\u00a0b", want: `a\u00a0b`},
+		{s: "a\u2028b", want: `a\u2028b`},
+		{s: "a\tb\n", want: "a\tb\n"},
+		{s: "café", want: "café"},
+	}
+
+	for i, tt := range tests {
+		if got := escapeInvisible(tt.s); got != tt.want {
+			t.Errorf("#%d: escapeInvisible(%q) got=%q, want=%q", i, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestStringErrorInvisible(t *testing.T) {
+	tests := []struct {
+		got, want string
+		pr        printer
+		out       string
+	}{{
+		got: "\ufeffid", want: "id", pr: printer{nocolor: true},
+		out: `- (string): Value mismatch; got="\ufeffid", want="id"; differs at byte 0 (rune 0)`,
+	}, {
+		got: "a\u00a0b", want: "a b", pr: printer{nocolor: true},
+		out: `- (string): Value mismatch; got="a\u00a0b", want="a b"; differs at byte 1 (rune 1)`,
+	}, {
+		got: "a\u00a0b", want: "a-b", pr: printer{nocolor: true},
+		out: "- (string): Value mismatch; got=\"a\u00a0b\", want=\"a-b\"; differs at byte 1 (rune 1)",
+	}, {
+		got: "a\u200bb", want: "ab", pr: printer{},
+		out: "- (string): Value mismatch; got=" +
+			gotColor + `"a` + stopColor + diffGotColor + `\u200b` + diffGotStopColor + gotColor + `b"` + stopColor +
+			", want=" +
+			wantColor + `"a` + stopColor + diffWantColor + `b` + diffWantStopColor + wantColor + `"` + stopColor +
+			"; differs at byte 1 (rune 1)",
+	}}
+
+	for i, tt := range tests {
+		err := newStringError(tt.got, tt.want, path{rootnode{reflect.TypeOf("")}})
+		if out := err.format(tt.pr); out != tt.out {
+			t.Errorf("#%d: format(%q, %q) got=%q, want=%q", i, tt.got, tt.want, out, tt.out)
+		}
+	}
+}
+
+func TestCompareControl(t *testing.T) {
+	type T struct {
+		S string
+		M map[string]int
+	}
+
+	tests := []struct {
+		a, b interface{}
+		want string
+	}{{
+		a: "a\nb", b: "a\tb",
+		want: "- (string): Value mismatch; got=" +
+			gotColor + `"a` + stopColor + diffGotColor + `\n` + diffGotStopColor + gotColor + `b"` + stopColor +
+			", want=" +
+			wantColor + `"a` + stopColor + diffWantColor + `\t` + diffWantStopColor + wantColor + `b"` + stopColor,
+	}, {
+		a:    T{S: "a", M: map[string]int{"\033[2J": 1}},
+		b:    T{S: "a", M: map[string]int{"\033[2J": 2}},
+		want: "- (compare.T).M[\\x1b[2J]: Value mismatch; got=" + gotColor + "1" + stopColor + ", want=" + wantColor + "2" + stopColor,
+	}}
+
+	for _, tt := range tests {
+		if got := errstr(Compare(tt.a, tt.b)); got != tt.want {
+			t.Errorf("Compare(%q, %q) got=%q, want=%q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareASCII(t *testing.T) {
+	type T struct {
+		S string
+		M map[string]int
+	}
+
+	tests := []struct {
+		a, b interface{}
+		want string
+	}{{
+		a: "café", b: "café", want: "<nil>",
+	}, {
+		a: "海辺のカフカ", b: "Kafka\non the Shore",
+		want: `- (string): Value mismatch; got="\u6d77\u8fba\u306e\u30ab\u30d5\u30ab", want="Kafka\non the Shore"`,
+	}, {
+		a:    T{S: "a", M: map[string]int{"é": 1}},
+		b:    T{S: "a", M: map[string]int{"é": 2}},
+		want: `- (compare.T).M[\u00e9]: Value mismatch; got=1, want=2`,
+	}, {
+		a: nil, b: 1,
+		want: `- (int): Validity mismatch; got=INVALID, want=VALID`,
+	}, {
+		a: 1, b: nil,
+		want: `- <nil>: Validity mismatch; got=VALID, want=INVALID`,
+	}}
+
+	conf := Config{ASCII: true}
+	for _, tt := range tests {
+		if got := errstr(conf.Compare(tt.a, tt.b)); got != tt.want {
+			t.Errorf("Compare(%v, %v) got=%q, want=%q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestReason(t *testing.T) {
+	type T struct {
+		S string
+		N []int
+	}
+
+	tests := []struct {
+		a, b   interface{}
+		equal  bool
+		reason string
+	}{{
+		a: T{S: "a"}, b: T{S: "a"}, equal: true, reason: "",
+	}, {
+		a: T{S: "a\nb", N: []int{1}}, b: T{S: "a\tb", N: []int{2}},
+		reason: `- (compare.T).S: Value mismatch; got="a\nb", want="a\tb"; ` +
+			`- (compare.T).N[0]: Value mismatch; got=1, want=2`,
+	}, {
+		a: "日本\x1b[31m", b: "日本",
+		reason: `- (string): Value mismatch; got="日本\x1b[31m", want="日本"; differs at byte 6 (rune 2)`,
+	}, {
+		a: map[int]string{1: "one", 3: "three"}, b: map[int]string{1: "one", 2: "two"},
+		reason: `- (map[int]string)[2]: Key 2 missing in got; want=two`,
+	}}
+
+	for _, tt := range tests {
+		equal, reason := Reason(tt.a, tt.b)
+		if equal != tt.equal || reason != tt.reason {
+			t.Errorf("Reason(%q, %q) got=(%t, %q), want=(%t, %q)", tt.a, tt.b, equal, reason, tt.equal, tt.reason)
+		}
+	}
+
+	conf := Config{AggregateCollectionErrors: true}
+	_, reason := conf.Reason([]int{1, 2}, []int{3, 4})
+	want := `- ([]int): 2 mismatches in slice: - ([]int)[0]: Value mismatch; got=1, want=3; ` +
+		`- ([]int)[1]: Value mismatch; got=2, want=4`
+	if reason != want {
+		t.Errorf("Reason() got=%q, want=%q", reason, want)
+	}
+}
+
+func TestCompareTimeRendering(t *testing.T) {
+	type T struct {
+		At time.Time
+	}
+	cet := time.FixedZone("CET", 3600)
+	now := time.Now()
+
+	tests := []struct {
+		a, b   interface{}
+		reason string
+	}{{
+		a:      time.Date(2024, 1, 1, 12, 0, 0, 5, cet),
+		b:      time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		reason: `- (time.Time): Value mismatch; got=2024-01-01T11:00:00.000000005Z, want=2024-01-01T12:00:00Z`,
+	}, {
+		a: now, b: now.Add(time.Second),
+		reason: `- (time.Time): Value mismatch; got=` + now.UTC().Format(time.RFC3339Nano) +
+			`, want=` + now.Add(time.Second).UTC().Format(time.RFC3339Nano),
+	}, {
+		a:      &T{At: time.Date(2024, 1, 1, 12, 0, 0, 0, cet)},
+		b:      &T{At: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		reason: `- (*compare.T).At: Value mismatch; got=2024-01-01T11:00:00Z, want=2024-01-01T12:00:00Z`,
+	}}
+
+	for _, tt := range tests {
+		if _, reason := Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+	}
+}
+
+func TestCompareFloatRendering(t *testing.T) {
+	x, y := 0.1, 0.2
+
+	tests := []struct {
+		conf   Config
+		a, b   interface{}
+		reason string
+	}{{
+		a: x + y, b: 0.3,
+		reason: `- (float64): Value mismatch; got=0.30000000000000004, want=0.3`,
+	}, {
+		a: float32(1.0000001), b: float32(1),
+		reason: `- (float32): Value mismatch; got=1.0000001, want=1`,
+	}, {
+		conf: Config{FloatBits: true},
+		a:    x + y, b: 0.3,
+		reason: `- (float64): Value mismatch; got=0.30000000000000004 (0x3fd3333333333334), ` +
+			`want=0.3 (0x3fd3333333333333)`,
+	}, {
+		conf: Config{FloatBits: true},
+		a:    []float32{1.0000001}, b: []float32{1},
+		reason: `- ([]float32)[0]: Value mismatch; got=1.0000001 (0x3f800001), want=1 (0x3f800000)`,
+	}}
+
+	for _, tt := range tests {
+		if _, reason := tt.conf.Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+	}
+}
+
+func TestCompareShowAddresses(t *testing.T) {
+	s := []int{1, 2, 3}
+	m := map[string]int{"a": 1}
+
+	tests := []struct {
+		a, b   interface{}
+		reason string
+	}{{
+		a: s[:2], b: s,
+		reason: fmt.Sprintf(`- ([]int): Length of slice mismatch; got=2 (%p), want=3 (%p)`, s, s),
+	}, {
+		a: map[string]int(nil), b: m,
+		reason: fmt.Sprintf(`- (map[string]int): Nil mismatch; got=<nil>, want=map[string]int{"a":1} (%p)`, m),
+	}, {
+		a: s, b: []int(nil),
+		reason: fmt.Sprintf(`- ([]int): Nil mismatch; got=[]int{1, 2, 3} (%p), want=<nil>`, s),
+	}}
+
+	conf := Config{ShowAddresses: true}
+	for _, tt := range tests {
+		if _, reason := conf.Reason(tt.a, tt.b); reason != tt.reason {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, reason, tt.reason)
+		}
+	}
+}
+
+func TestGolden(t *testing.T) {
+	type T struct {
+		M map[string]int
+		S string
+		F []float64
+	}
+	a := T{M: map[string]int{"a": 1, "b": 2, "c": 3}, S: "x\ny", F: []float64{0.5, 1}}
+	b := T{M: map[string]int{"a": 0, "b": 0, "c": 0}, S: "x\tz", F: []float64{0.25, 1}}
+
+	want := "- (compare.T).F[0]: Value mismatch; got=0.5, want=0.25\n" +
+		`- (compare.T).M[a]: Value mismatch; got=1, want=0` + "\n" +
+		`- (compare.T).M[b]: Value mismatch; got=2, want=0` + "\n" +
+		`- (compare.T).M[c]: Value mismatch; got=3, want=0` + "\n" +
+		`- (compare.T).S: Value mismatch; got="x\ny", want="x\tz"` + "\n"
+
+	confs := []Config{{}, {ShowAddresses: true}}
+	for _, conf := range confs {
+		// run a few times to catch the randomized map order
+		for i := 0; i < 10; i++ {
+			if got := Golden(conf.Compare(a, b)); got != want {
+				t.Fatalf("Golden() got:\n%s\nwant:\n%s", got, want)
+			}
+		}
+	}
+
+	conf := Config{AggregateCollectionErrors: true}
+	want = "- (compare.T).M: 3 mismatches in map:\n" +
+		`	- (compare.T).M[a]: Value mismatch; got=1, want=0` + "\n" +
+		`	- (compare.T).M[b]: Value mismatch; got=2, want=0` + "\n" +
+		`	- (compare.T).M[c]: Value mismatch; got=3, want=0` + "\n" +
+		`- (compare.T).S: Value mismatch; got="x\ny", want="x\tz"` + "\n"
+	b.F = a.F
+	for i := 0; i < 10; i++ {
+		if got := Golden(conf.Compare(a, b)); got != want {
+			t.Fatalf("Golden() got:\n%s\nwant:\n%s", got, want)
+		}
+	}
+
+	if got := Golden(nil); got != "" {
+		t.Errorf("Golden(nil) got=%q, want=%q", got, "")
+	}
+}
+
+func TestNilErrorSummary(t *testing.T) {
+	tests := []struct {
+		a, b       interface{}
+		short, all string
+	}{{
+		a: []int{1, 2, 3, 4, 5}, b: []int(nil),
+		short: `- ([]int): Nil mismatch; got=[]int(len=5){1, 2, 3, ...}, want=<nil>`,
+		all:   `- ([]int): Nil mismatch; got=[]int{1, 2, 3, 4, 5}, want=<nil>`,
+	}, {
+		a: map[string]int(nil), b: map[string]int{"d": 4, "c": 3, "b": 2, "a": 1},
+		short: `- (map[string]int): Nil mismatch; got=<nil>, want=map[string]int(len=4){"a":1, "b":2, "c":3, ...}`,
+		all:   `- (map[string]int): Nil mismatch; got=<nil>, want=map[string]int{"a":1, "b":2, "c":3, "d":4}`,
+	}, {
+		a: []int{1, 2}, b: []int(nil),
+		short: `- ([]int): Nil mismatch; got=[]int{1, 2}, want=<nil>`,
+		all:   `- ([]int): Nil mismatch; got=[]int{1, 2}, want=<nil>`,
+	}}
+
+	conf := Config{ASCII: true}
+	for _, tt := range tests {
+		err := conf.Compare(tt.a, tt.b)
+		if got := fmt.Sprintf("%v", err); got != tt.short {
+			t.Errorf("%%v got=%q, want=%q", got, tt.short)
+		}
+		if got := err.Error(); got != tt.short {
+			t.Errorf("Error() got=%q, want=%q", got, tt.short)
+		}
+		if got := fmt.Sprintf("%+v", err); got != tt.all {
+			t.Errorf("%%+v got=%q, want=%q", got, tt.all)
+		}
+	}
+}
+
+type testLevel int
+
+type testColor string
+
+func TestCompareFormatters(t *testing.T) {
+	conf := Config{ASCII: true, Formatters: map[reflect.Type]func(interface{}) string{
+		rtof(testLevel(0)): func(v interface{}) string {
+			return [...]string{"DEBUG", "INFO", "WARN"}[v.(testLevel)]
+		},
+		rtof(testColor("")): func(v interface{}) string {
+			return "#" + string(v.(testColor))
+		},
+		rtof([]byte(nil)): func(v interface{}) string {
+			return base64.StdEncoding.EncodeToString(v.([]byte))
+		},
+	}}
+
+	type T struct {
+		Level testLevel
+		Color testColor
+		Data  []byte
+	}
+
+	tests := []struct {
+		a, b interface{}
+		want string
+	}{{
+		a: testLevel(1), b: testLevel(2),
+		want: `- (compare.testLevel): Value mismatch; got=INFO, want=WARN`,
+	}, {
+		a: testColor("fff"), b: testColor("000"),
+		want: `- (compare.testColor): Value mismatch; got=#fff, want=#000`,
+	}, {
+		a: T{Data: []byte("hello")}, b: T{Data: nil},
+		want: `- (compare.T).Data: Nil mismatch; got=aGVsbG8=, want=<nil>`,
+	}, {
+		a: []testLevel{0}, b: []testLevel{0, 2},
+		want: `- ([]compare.testLevel): Length of slice mismatch; got=1, want=2`,
+	}, {
+		a: testLevel(1), b: testLevel(1), want: "",
+	}}
+
+	for _, tt := range tests {
+		if _, got := conf.Reason(tt.a, tt.b); got != tt.want {
+			t.Errorf("Reason(%v, %v) got=%q, want=%q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package compare
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// StringerProjection is a projection, intended to be used with
+// Config.Projections for the fmt.Stringer type, that projects a value
+// to the result of its String method.
+func StringerProjection(v interface{}) interface{} {
+	return v.(fmt.Stringer).String()
+}
+
+// ReaderProjection is a projection, intended to be used with
+// Config.Projections for the io.Reader type, that projects a reader to the
+// contents read from it up to EOF as a string, or, if the reading fails,
+// to the error. Note that the reader is consumed by the projection and it
+// is therefore seen as empty by any subsequent comparison of the same reader.
+func ReaderProjection(v interface{}) interface{} {
+	b, err := io.ReadAll(v.(io.Reader))
+	if err != nil {
+		return err
+	}
+	return string(b)
+}
+
+// compareProjected compares the projections of the two given interface values
+// that are produced by the given function, see Config.Projections. The ok
+// return value reports whether the projection could be used.
+func (conf Config) compareProjected(fn func(v interface{}) interface{}, got, want reflect.Value, cmp *comparison, p path) (ok bool) {
+	gotx, ok := interfaceOf(got)
+	if !ok {
+		return false
+	}
+	wantx, ok := interfaceOf(want)
+	if !ok {
+		return false
+	}
+	conf.compare(reflect.ValueOf(fn(gotx)), reflect.ValueOf(fn(wantx)), cmp, p)
+	return true
+}
@@ -0,0 +1,61 @@
+package compare
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testName string
+
+func (n testName) String() string { return string(n) }
+
+func TestCompareProjections(t *testing.T) {
+	type T struct {
+		Name fmt.Stringer
+		Body io.Reader
+	}
+	stringerType := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	readerType := reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+	conf := Config{Projections: map[reflect.Type]func(v interface{}) interface{}{
+		stringerType: StringerProjection,
+		readerType:   ReaderProjection,
+	}}
+
+	tests := []struct {
+		a, b T
+		err  string
+	}{{
+		a:   T{Name: testName("127.0.0.1"), Body: strings.NewReader("hello")},
+		b:   T{Name: net.IPv4(127, 0, 0, 1), Body: bytes.NewBufferString("hello")},
+		err: "",
+	}, {
+		a: T{Name: testName("a"), Body: strings.NewReader("hello")},
+		b: T{Name: testName("b"), Body: strings.NewReader("world")},
+		err: "- (compare.T).Body: Value mismatch; got=\"hello\", want=\"world\"; differs at byte 0 (rune 0)\n" +
+			"- (compare.T).Name: Value mismatch; got=\"a\", want=\"b\"; differs at byte 0 (rune 0)\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+	}
+
+	// nil interfaces are not projected
+	diffs := Differences(conf.Compare(T{}, T{Body: strings.NewReader("")}))
+	if len(diffs) != 1 || diffs[0].Kind != NilDiff || diffs[0].Path != ".Body" {
+		t.Errorf("Compare() of a nil reader got=%+v, want a nil difference at .Body", diffs)
+	}
+
+	// without the projections the dynamic types are compared
+	a, b := T{Name: testName("a")}, T{Name: net.IP("a")}
+	if err := Compare(a, b); err == nil {
+		t.Errorf("Compare() without Projections got=nil, want an error")
+	}
+}
@@ -0,0 +1,33 @@
+package compare
+
+import (
+	"reflect"
+)
+
+// compareGetters compares the results of the exported getter methods of the
+// two given struct values, see PublicView.
+func (conf Config) compareGetters(got, want reflect.Value, cmp *comparison, p path) {
+	if got.CanAddr() && want.CanAddr() {
+		got, want = got.Addr(), want.Addr()
+	}
+	typ := want.Type()
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if !isGetter(m.Type) {
+			continue
+		}
+		gotm, wantm := got.Method(i), want.Method(i)
+		if !gotm.CanInterface() || !wantm.CanInterface() {
+			// the struct was obtained from an unexported field
+			return
+		}
+		gotOut, wantOut := gotm.Call(nil)[0], wantm.Call(nil)[0]
+		conf.compare(gotOut, wantOut, cmp, p.add(methodnode{m.Name}))
+	}
+}
+
+// isGetter reports whether the method of the given type, whose first argument
+// is the receiver, takes no other arguments and returns a single result.
+func isGetter(typ reflect.Type) bool {
+	return typ.NumIn() == 1 && typ.NumOut() == 1
+}
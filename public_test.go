@@ -0,0 +1,61 @@
+package compare
+
+import (
+	"testing"
+)
+
+type account struct {
+	ID      int
+	balance int
+	cache   map[string]int
+}
+
+func (a account) Balance() int          { return a.balance }
+func (a *account) Overdrawn() bool      { return a.balance < 0 }
+func (a account) Deposit(n int) account { a.balance += n; return a }
+
+func TestComparePublicView(t *testing.T) {
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: Config{PublicView: true},
+		a:    account{1, 10, map[string]int{"x": 1}}, b: account{1, 10, nil},
+		err: "",
+	}, {
+		conf: Config{PublicView: true},
+		a:    account{1, 10, nil}, b: account{2, 20, nil},
+		err: "- (compare.account).Balance(): Value mismatch; got=10, want=20\n" +
+			"- (compare.account).ID: Value mismatch; got=1, want=2\n",
+	}, {
+		conf: Config{PublicView: true},
+		a:    &account{1, 10, nil}, b: &account{1, -10, nil},
+		err: "- (*compare.account).Balance(): Value mismatch; got=10, want=-10\n" +
+			"- (*compare.account).Overdrawn(): Value mismatch; got=false, want=true\n",
+	}, {
+		conf: Config{PublicView: true},
+		a:    []account{{1, -10, nil}}, b: []account{{1, 20, nil}},
+		err: "- ([]compare.account)[0].Balance(): Value mismatch; got=-10, want=20\n" +
+			"- ([]compare.account)[0].Overdrawn(): Value mismatch; got=true, want=false\n",
+	}, {
+		// the pointer receiver methods of unaddressable structs are not called
+		conf: Config{PublicView: true},
+		a:    account{1, -10, nil}, b: account{1, 20, nil},
+		err: "- (compare.account).Balance(): Value mismatch; got=-10, want=20\n",
+	}, {
+		conf: Config{PublicView: true, AuditSkippedFields: true},
+		a:    account{1, 10, nil}, b: account{1, 10, nil},
+		err: "- (compare.account).balance: Field skipped by PublicView\n" +
+			"- (compare.account).cache: Field skipped by PublicView\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+		if eq := tt.conf.EqualNoReport(tt.a, tt.b); eq != (tt.err == "") {
+			t.Errorf("#%d: EqualNoReport() got=%t, want=%t", i, eq, tt.err == "")
+		}
+	}
+}
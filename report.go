@@ -0,0 +1,94 @@
+package compare
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewReport is a wrapper around DefaultConfig.NewReport.
+func NewReport(root string, diffs []Difference) error {
+	return DefaultConfig.NewReport(root, diffs)
+}
+
+// NewReport returns an error that represents the given differences and that is
+// rendered in the same format, and according to the same options, as the
+// errors returned by Compare. It allows the tools that find the differences by
+// other means, e.g. by comparing the states of two external systems, to report
+// them as this package does. The root is rendered in place of the type of the
+// compared values, e.g. "- (root).Path: ...", and the paths of the differences
+// are rendered as they are. The messages are chosen by the kinds of the
+// differences, e.g. a LenDiff with a LenDifference as its Detail is rendered
+// as a length mismatch. The returned error can be passed to the functions of
+// this package that accept the errors returned by Compare, e.g. Differences
+// and Golden. If there are no differences the result will be nil.
+func (conf Config) NewReport(root string, diffs []Difference) error {
+	list := &errorList{pr: conf.printer()}
+	for _, d := range diffs {
+		list.add(&reportError{root, d})
+	}
+	return list.err()
+}
+
+// reportError represents a Difference reported by NewReport.
+type reportError struct {
+	root string
+	diff Difference
+}
+
+func (err *reportError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *reportError) format(pr printer) string {
+	d := err.diff
+	prefix := "- (" + pr.text(err.root) + ")" + pr.text(d.Path)
+	got := pr.color(gotColor, reportValue(pr, d.Got))
+	want := pr.color(wantColor, reportValue(pr, d.Want))
+
+	switch d.Kind {
+	case ValidityDiff:
+		got, want = pr.color(gotColor, "VALID"), pr.color(wantColor, "VALID")
+		if d.Got == nil {
+			got = pr.color(gotColor, "INVALID")
+		}
+		if d.Want == nil {
+			want = pr.color(wantColor, "INVALID")
+		}
+		return fmt.Sprintf("%s: Validity mismatch; got=%s, want=%s", prefix, got, want)
+	case LenDiff:
+		if ld, ok := d.Detail.(LenDifference); ok {
+			got = pr.color(gotColor, strconv.Itoa(ld.Got))
+			want = pr.color(wantColor, strconv.Itoa(ld.Want))
+			return fmt.Sprintf("%s: Length of %s mismatch; got=%s, want=%s", prefix, ld.Kind, got, want)
+		}
+		return fmt.Sprintf("%s: Length mismatch; got=%s, want=%s", prefix, got, want)
+	case ElemDiff:
+		if d.Got == nil {
+			return fmt.Sprintf("%s: Element missing in got; want=%s", prefix, want)
+		}
+		return fmt.Sprintf("%s: Element unexpected in got; got=%s", prefix, got)
+	case SkipDiff:
+		return fmt.Sprintf("%s: Field skipped by %s", prefix, pr.value("%v", d.Detail))
+	}
+
+	kind := string(d.Kind)
+	if len(kind) == 0 {
+		kind = string(ValueDiff)
+	}
+	kind = strings.ToUpper(kind[:1]) + kind[1:]
+	return fmt.Sprintf("%s: %s mismatch; got=%s, want=%s", prefix, kind, got, want)
+}
+
+func (err *reportError) differences() []Difference {
+	return []Difference{err.diff}
+}
+
+// reportValue renders the got or want value of a reported Difference, strings
+// are quoted as they are by the errors of Compare.
+func reportValue(pr printer, v interface{}) string {
+	if s, ok := v.(string); ok {
+		return pr.text(strconv.Quote(s))
+	}
+	return pr.value("%v", v)
+}
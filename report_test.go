@@ -0,0 +1,40 @@
+package compare
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	diffs := []Difference{
+		{".Users[0].Name", ValueDiff, "Joe", "Jane", nil},
+		{".Users", LenDiff, 2, 3, LenDifference{reflect.Slice, 2, 3}},
+		{".Users[2]", ElemDiff, nil, "Bob", nil},
+		{".Meta", ValidityDiff, nil, 1, nil},
+		{".Port", TypeDiff, rtof(""), rtof(0), nil},
+		{".Secret", SkipDiff, nil, nil, "policy"},
+		{".Count", "", 1, 2, nil},
+	}
+
+	err := Config{NoColor: true}.NewReport("db", diffs)
+	want := "- (db).Users[0].Name: Value mismatch; got=\"Joe\", want=\"Jane\"\n" +
+		"- (db).Users: Length of slice mismatch; got=2, want=3\n" +
+		"- (db).Users[2]: Element missing in got; want=\"Bob\"\n" +
+		"- (db).Meta: Validity mismatch; got=INVALID, want=VALID\n" +
+		"- (db).Port: Type mismatch; got=string, want=int\n" +
+		"- (db).Secret: Field skipped by policy\n" +
+		"- (db).Count: Value mismatch; got=1, want=2"
+	if got := err.Error(); got != want {
+		t.Errorf("NewReport() got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := Differences(err); !reflect.DeepEqual(got, diffs) {
+		t.Errorf("Differences() got=%v, want=%v", got, diffs)
+	}
+	if _, err := json.Marshal(err); err != nil {
+		t.Errorf("json.Marshal() got err=%v", err)
+	}
+	if err := NewReport("db", nil); err != nil {
+		t.Errorf("NewReport() with no differences got=%v, want=<nil>", err)
+	}
+}
@@ -0,0 +1,163 @@
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// PathRule overrides some of the options of a Config for the comparison of the
+// values found at the path of the rule and of the values nested within them,
+// see Config.PathRules.
+type PathRule struct {
+	// The path, in the syntax of IgnorePaths, at which the rule applies.
+	Path string
+	// If set, the order of the elements of arrays and slices is ignored,
+	// see IgnoreArrayOrder.
+	IgnoreOrder bool
+	// If greater than 0, they replace the FloatTolerance and the
+	// FloatRelTolerance of the Config.
+	FloatTolerance    float64
+	FloatRelTolerance float64
+}
+
+// apply returns a copy of the Config with the rule's options applied.
+func (r PathRule) apply(conf Config) Config {
+	if r.IgnoreOrder {
+		conf.IgnoreArrayOrder = true
+	}
+	if r.FloatTolerance > 0 {
+		conf.FloatTolerance = r.FloatTolerance
+	}
+	if r.FloatRelTolerance > 0 {
+		conf.FloatRelTolerance = r.FloatRelTolerance
+	}
+	return conf
+}
+
+// parsedRule is a PathRule with its path parsed.
+type parsedRule struct {
+	steps []pathStep
+	rule  PathRule
+}
+
+// parsePathRules parses the paths of the PathRules, the rules whose paths
+// cannot be parsed are ignored.
+func (conf Config) parsePathRules() (rules []parsedRule) {
+	for _, r := range conf.PathRules {
+		for _, steps := range parsePaths([]string{r.Path}) {
+			rules = append(rules, parsedRule{steps, r})
+		}
+	}
+	return rules
+}
+
+// applyPathRules returns a copy of the Config with the options of the rules
+// that match the path p applied.
+func (cmp *comparison) applyPathRules(conf Config, p path) Config {
+	for _, r := range cmp.rules {
+		if matchPath(r.steps, p) {
+			conf = r.rule.apply(conf)
+		}
+	}
+	return conf
+}
+
+// rulesFile is the content of a rules file, see LoadRules.
+type rulesFile struct {
+	IgnoreOrder       bool                 `json:"ignoreOrder"`
+	IgnoreFields      []string             `json:"ignoreFields"`
+	IgnorePaths       []string             `json:"ignorePaths"`
+	WarnPaths         []string             `json:"warnPaths"`
+	FloatTolerance    float64              `json:"floatTolerance"`
+	FloatRelTolerance float64              `json:"floatRelTolerance"`
+	EquateNaNs        bool                 `json:"equateNaNs"`
+	Paths             map[string]rulesPath `json:"paths"`
+}
+
+type rulesPath struct {
+	Ignore            bool    `json:"ignore"`
+	Warn              bool    `json:"warn"`
+	IgnoreOrder       bool    `json:"ignoreOrder"`
+	FloatTolerance    float64 `json:"floatTolerance"`
+	FloatRelTolerance float64 `json:"floatRelTolerance"`
+}
+
+// LoadRules reads the comparison rules from the JSON document read from r and
+// returns a copy of the Config with the rules applied. The document is an
+// object with the following, optional, members:
+//
+//	{
+//		"ignoreOrder": true,               // IgnoreArrayOrder
+//		"ignoreFields": ["CreatedAt"],     // IgnoreFieldNames
+//		"ignorePaths": ["Items[*].ID"],    // IgnorePaths
+//		"warnPaths": [".Stats"],           // WarnPaths
+//		"floatTolerance": 1e-9,            // FloatTolerance
+//		"floatRelTolerance": 0.01,         // FloatRelTolerance
+//		"equateNaNs": true,                // EquateNaNs
+//		"paths": {                         // the per-path overrides
+//			".Prices": {"floatTolerance": 0.005, "ignoreOrder": true},
+//			".Meta": {"ignore": true},
+//			".Debug": {"warn": true}
+//		}
+//	}
+//
+// The lists are appended to those of the Config, the ignored and the warned
+// paths are appended to its IgnorePaths and WarnPaths, and the rest of the
+// per-path overrides to its PathRules. The unknown members and the paths that
+// cannot be parsed are reported as errors.
+func (conf Config) LoadRules(r io.Reader) (Config, error) {
+	var rf rulesFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rf); err != nil {
+		return conf, fmt.Errorf("compare: failed to decode rules: %w", err)
+	}
+
+	paths := make([]string, 0, len(rf.Paths))
+	for s := range rf.Paths {
+		paths = append(paths, s)
+	}
+	sort.Strings(paths)
+	for _, s := range append(append(paths, rf.IgnorePaths...), rf.WarnPaths...) {
+		if len(parsePaths([]string{s})) == 0 {
+			return conf, fmt.Errorf("compare: failed to parse rules path %q", s)
+		}
+	}
+
+	if rf.IgnoreOrder {
+		conf.IgnoreArrayOrder = true
+	}
+	if rf.FloatTolerance > 0 {
+		conf.FloatTolerance = rf.FloatTolerance
+	}
+	if rf.FloatRelTolerance > 0 {
+		conf.FloatRelTolerance = rf.FloatRelTolerance
+	}
+	if rf.EquateNaNs {
+		conf.EquateNaNs = true
+	}
+	conf.IgnoreFieldNames = append(conf.IgnoreFieldNames[:len(conf.IgnoreFieldNames):len(conf.IgnoreFieldNames)], rf.IgnoreFields...)
+	conf.IgnorePaths = append(conf.IgnorePaths[:len(conf.IgnorePaths):len(conf.IgnorePaths)], rf.IgnorePaths...)
+	conf.WarnPaths = append(conf.WarnPaths[:len(conf.WarnPaths):len(conf.WarnPaths)], rf.WarnPaths...)
+	conf.PathRules = conf.PathRules[:len(conf.PathRules):len(conf.PathRules)]
+	for _, s := range paths {
+		pr := rf.Paths[s]
+		if pr.Ignore {
+			conf.IgnorePaths = append(conf.IgnorePaths, s)
+		}
+		if pr.Warn {
+			conf.WarnPaths = append(conf.WarnPaths, s)
+		}
+		if pr.IgnoreOrder || pr.FloatTolerance > 0 || pr.FloatRelTolerance > 0 {
+			conf.PathRules = append(conf.PathRules, PathRule{
+				Path:              s,
+				IgnoreOrder:       pr.IgnoreOrder,
+				FloatTolerance:    pr.FloatTolerance,
+				FloatRelTolerance: pr.FloatRelTolerance,
+			})
+		}
+	}
+	return conf, nil
+}
@@ -0,0 +1,62 @@
+package compare
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigLoadRules(t *testing.T) {
+	type Item struct {
+		ID    int
+		Price float64
+		Tags  []string
+	}
+	type Order struct {
+		ID        int
+		CreatedAt string
+		Items     []Item
+		Total     float64
+		Debug     string
+	}
+	a := Order{
+		ID: 1, CreatedAt: "a", Total: 10.004, Debug: "x",
+		Items: []Item{{1, 1.004, []string{"a", "b"}}, {2, 2.0, nil}},
+	}
+	b := Order{
+		ID: 2, CreatedAt: "b", Total: 10, Debug: "y",
+		Items: []Item{{3, 1, []string{"b", "a"}}, {4, 2.001, nil}},
+	}
+
+	rules := `{
+		"ignoreFields": ["CreatedAt"],
+		"ignorePaths": ["ID", "Items[*].ID"],
+		"paths": {
+			".Items[*].Price": {"floatTolerance": 0.005},
+			".Items[*].Tags": {"ignoreOrder": true},
+			".Debug": {"warn": true}
+		}
+	}`
+	conf, err := Config{}.LoadRules(strings.NewReader(rules))
+	if err != nil {
+		t.Fatalf("LoadRules() got err=%v", err)
+	}
+	want := "- (compare.Order).Total: Value mismatch; got=10.004, want=10\n" +
+		"[warning] - (compare.Order).Debug: Value mismatch; got=\"x\", want=\"y\"; differs at byte 0 (rune 0)\n"
+	if got := Golden(conf.Compare(a, b)); got != want {
+		t.Errorf("Compare() got:\n%s\nwant:\n%s", got, want)
+	}
+
+	errs := []struct {
+		rules string
+		err   string
+	}{
+		{`{"ignorePaths": ["[oops"]}`, `compare: failed to parse rules path "[oops"`},
+		{`{"paths": {"a..b": {"ignore": true}}}`, `compare: failed to parse rules path "a..b"`},
+		{`{"ignoredPaths": []}`, `compare: failed to decode rules: json: unknown field "ignoredPaths"`},
+	}
+	for _, tt := range errs {
+		if _, err := (Config{}).LoadRules(strings.NewReader(tt.rules)); err == nil || err.Error() != tt.err {
+			t.Errorf("LoadRules(%s) got err=%v, want=%s", tt.rules, err, tt.err)
+		}
+	}
+}
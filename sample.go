@@ -0,0 +1,80 @@
+package compare
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+)
+
+// sampler selects the elements of a large collection to be compared, see
+// Config.SampleMinLen.
+type sampler struct {
+	every   int
+	rate    float64
+	rand    *rand.Rand // nil for the global source
+	index   int        // the index of the next element
+	sampled int        // the number of elements selected so far
+	differ  int        // the number of selected elements that differ
+}
+
+// newSampler returns a sampler for a collection of the given length, or nil
+// if the collection is to be compared in full.
+func (conf Config) newSampler(cmp *comparison, length int) *sampler {
+	if conf.SampleMinLen <= 0 || length < conf.SampleMinLen {
+		return nil
+	}
+	if conf.SampleEvery <= 0 && (conf.SampleRate <= 0 || conf.SampleRate >= 1) {
+		return nil
+	}
+	return &sampler{every: conf.SampleEvery, rate: conf.SampleRate, rand: cmp.rand}
+}
+
+// next reports whether the next element of the collection is to be compared.
+func (s *sampler) next() (ok bool) {
+	if s.every > 0 {
+		ok = s.index%s.every == 0
+	} else if s.rand != nil {
+		ok = s.rand.Float64() < s.rate
+	} else {
+		ok = rand.Float64() < s.rate
+	}
+	if s.index++; ok {
+		s.sampled++
+	}
+	return ok
+}
+
+// done adds the summary of the sample to the errors if any of the sampled
+// elements differ.
+func (s *sampler) done(cmp *comparison, total int, kind reflect.Kind, p path) {
+	if s.differ > 0 {
+		cmp.errs.add(&sampleError{s.sampled, total, s.differ, kind, p})
+	}
+}
+
+type sampleError struct {
+	sampled int // the number of compared elements
+	total   int // the number of elements of the collection
+	differ  int // the number of compared elements that differ
+	kind    reflect.Kind
+	path    path
+}
+
+func (err *sampleError) rate() float64 {
+	return float64(err.differ) / float64(err.sampled)
+}
+
+func (err *sampleError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *sampleError) format(pr printer) string {
+	rate := pr.color(yellowColor, fmt.Sprintf("%.2f%%", err.rate()*100))
+	return fmt.Sprintf("%s: Sampled %d of %d elements of %s, %d differ; estimated mismatch rate=%s",
+		err.path.format(pr), err.sampled, err.total, err.kind, err.differ, rate)
+}
+
+func (err *sampleError) differences() []Difference {
+	detail := SampleDifference{err.sampled, err.total, err.differ, err.rate()}
+	return []Difference{{err.path.relpath(), SampleDiff, nil, nil, detail}}
+}
@@ -0,0 +1,75 @@
+package compare
+
+import (
+	"testing"
+)
+
+func TestCompareSampling(t *testing.T) {
+	got, want := make([]int, 100), make([]int, 100)
+	for i := range want {
+		got[i], want[i] = i, i
+		if i%20 == 0 {
+			got[i] = -1
+		}
+	}
+
+	conf := Config{SampleMinLen: 50, SampleEvery: 10}
+	_, reason := conf.Reason(got, want)
+	wantReason := "- ([]int)[0]: Value mismatch; got=-1, want=0; " +
+		"- ([]int)[20]: Value mismatch; got=-1, want=20; " +
+		"- ([]int)[40]: Value mismatch; got=-1, want=40; " +
+		"- ([]int)[60]: Value mismatch; got=-1, want=60; " +
+		"- ([]int)[80]: Value mismatch; got=-1, want=80; " +
+		"- ([]int): Sampled 10 of 100 elements of slice, 5 differ; estimated mismatch rate=50.00%"
+	if reason != wantReason {
+		t.Errorf("Reason() got=%q, want=%q", reason, wantReason)
+	}
+
+	// the differences of the elements that are not sampled go unnoticed
+	got[5] = -1
+	got[0], got[20], got[40], got[60], got[80] = 0, 20, 40, 60, 80
+	if err := conf.Compare(got, want); err != nil {
+		t.Errorf("Compare() got=%v, want=<nil>", err)
+	}
+
+	// short collections are compared in full
+	if err := conf.Compare(got[:10], want[:10]); err == nil {
+		t.Errorf("Compare() got=<nil>, want an error")
+	}
+
+	m1, m2 := make(map[int]int), make(map[int]int)
+	for i := 0; i < 100; i++ {
+		m1[i], m2[i] = i, i+1
+	}
+	conf = Config{SampleMinLen: 50, SampleRate: 0.5, MaxDiffsPerCollection: 1}
+	diffs := Differences(conf.Compare(m1, m2))
+	d, ok := diffs[len(diffs)-1].Detail.(SampleDifference)
+	if !ok || d.Total != 100 || d.Sampled == 0 || d.Sampled == 100 || d.Differ != d.Sampled || d.Rate != 1 {
+		t.Errorf("Differences() sample got=%+v", diffs[len(diffs)-1])
+	}
+}
+
+func TestCompareSeed(t *testing.T) {
+	m1, m2 := make(map[int]int), make(map[int]int)
+	for i := 0; i < 200; i++ {
+		m1[i], m2[i] = i, i+1
+	}
+
+	conf := Config{SampleMinLen: 50, SampleRate: 0.3, Seed: 42}
+	_, first := conf.Reason(m1, m2)
+	for i := 0; i < 5; i++ {
+		if _, reason := conf.Reason(m1, m2); reason != first {
+			t.Fatalf("Reason() with Seed got=%q, want=%q", reason, first)
+		}
+	}
+
+	// the entries are reported in the order of their keys
+	conf = Config{Seed: 1, IterateMaps: true}
+	_, reason := conf.Reason(map[string]int{"c": 1, "a": 1, "b": 1}, map[string]int{"c": 2, "a": 2, "b": 2})
+	want := "- (map[string]int)[a]: Value mismatch; got=1, want=2; " +
+		"- (map[string]int)[b]: Value mismatch; got=1, want=2; " +
+		"- (map[string]int)[c]: Value mismatch; got=1, want=2"
+	if reason != want {
+		t.Errorf("Reason() with Seed got=%q, want=%q", reason, want)
+	}
+}
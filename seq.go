@@ -0,0 +1,32 @@
+//go:build go1.23
+
+package compare
+
+import (
+	"iter"
+	"reflect"
+)
+
+// CompareSeq consumes the two given sequences and compares the elements they
+// yield in the order in which they were yielded, the paths of the errors hold
+// the indexes of the differing elements. The options are applied to a copy of
+// the DefaultConfig, e.g. with the IgnoreOrder option the elements are compared
+// regardless of their order.
+func CompareSeq[T any](got, want iter.Seq[T], opts ...Option) error {
+	conf := DefaultConfig.With(opts...)
+	return conf.compareSeqs(reflect.ValueOf(got), reflect.ValueOf(want))
+}
+
+// CompareSeq2 consumes the two given sequences and compares the pairs they
+// yield, see CompareSeq. The pairs are compared as structs with the Key and
+// Value fields, e.g. the path "[2].Value" points to the value of the third
+// pair.
+func CompareSeq2[K, V any](got, want iter.Seq2[K, V], opts ...Option) error {
+	conf := DefaultConfig.With(opts...)
+	return conf.compareSeqs(reflect.ValueOf(got), reflect.ValueOf(want))
+}
+
+// compareSeqs compares the elements of the two sequences.
+func (conf Config) compareSeqs(got, want reflect.Value) error {
+	return conf.Compare(collectSeq(got).Interface(), collectSeq(want).Interface())
+}
@@ -0,0 +1,63 @@
+//go:build go1.23
+
+package compare
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestCompareSeq(t *testing.T) {
+	tests := []struct {
+		got, want []int
+		opts      []Option
+		err       string
+	}{{
+		got: []int{1, 2, 3}, want: []int{1, 2, 3},
+		err: "",
+	}, {
+		got: []int{1, 2, 3}, want: []int{1, 4, 3},
+		err: "- ([]int)[1]: Value mismatch; got=2, want=4\n",
+	}, {
+		got: []int{3, 2, 1}, want: []int{1, 2, 3}, opts: []Option{IgnoreOrder()},
+		err: "",
+	}, {
+		got: []int{1, 2}, want: []int{1, 2, 3},
+		err: "- ([]int): Length of slice mismatch; got=2, want=3\n",
+	}, {
+		got: nil, want: nil,
+		err: "",
+	}}
+
+	for i, tt := range tests {
+		err := CompareSeq(slices.Values(tt.got), slices.Values(tt.want), tt.opts...)
+		if got := Golden(err); got != tt.err {
+			t.Errorf("#%d: CompareSeq got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+	}
+	if err := CompareSeq[int](nil, slices.Values([]int{})); err != nil {
+		t.Errorf("CompareSeq with nil sequence got=%v, want=<nil>", err)
+	}
+}
+
+func TestCompareSeq2(t *testing.T) {
+	got := map[string]int{"a": 1, "b": 2}
+	want := map[string]int{"a": 1, "b": 3}
+
+	err := CompareSeq2(maps.All(got), maps.All(want), IgnoreOrder())
+	if err == nil {
+		t.Fatalf("CompareSeq2 got=<nil>, want an error")
+	}
+
+	err = CompareSeq2(slices.All([]string{"a", "b"}), slices.All([]string{"a", "c"}))
+	want2 := "- ([]struct { Key int; Value string })[1].Value: Value mismatch; got=\"b\", want=\"c\"; differs at byte 0 (rune 0)\n"
+	if got := Golden(err); got != want2 {
+		t.Errorf("CompareSeq2 got:\n%s\nwant:\n%s", got, want2)
+	}
+
+	want["b"] = 2
+	if err := CompareSeq2(maps.All(got), maps.All(want), IgnoreOrder()); err != nil {
+		t.Errorf("CompareSeq2 got=%v, want=<nil>", err)
+	}
+}
@@ -0,0 +1,96 @@
+package compare
+
+import (
+	"strings"
+)
+
+// Failures returns the part of the given error, which is expected to be an
+// error returned by Compare, that represents the failures, i.e. all of the
+// differences except the warnings, see Config.WarnPaths. If there are no
+// failures the result will be nil. An error that was not returned by Compare
+// is returned as is.
+func Failures(err error) error {
+	if _, ok := err.(*errorList); !ok {
+		return err
+	}
+	return filterSeverity(err, false)
+}
+
+// Warnings returns the part of the given error, which is expected to be an
+// error returned by Compare, that represents the warnings, see WarnPaths. If
+// there are no warnings, or if err was not returned by Compare, the result
+// will be nil.
+func Warnings(err error) error {
+	return filterSeverity(err, true)
+}
+
+// filterSeverity returns the list of either the warnings or the failures of
+// the given error. The summaries of the Verbose mode are omitted from both,
+// since they may summarize failures and warnings alike, unless they were found
+// at a path of warnings, in which case they are themselves warnings.
+func filterSeverity(err error, warnings bool) error {
+	el, ok := err.(*errorList)
+	if !ok {
+		return nil
+	}
+
+	out := &errorList{pr: el.pr}
+	for _, e := range el.List {
+		switch e := e.(type) {
+		case *warningError:
+			if warnings {
+				out.add(e)
+			}
+		case *caseError:
+			if f := filterSeverity(e.err, warnings); f != nil {
+				out.add(&caseError{e.name, f})
+			}
+		case *fieldsError:
+			// a summary is neither
+		default:
+			if !warnings {
+				out.add(e)
+			}
+		}
+	}
+	return out.err()
+}
+
+// parseWarnPaths parses the WarnPaths, the paths that cannot be parsed are
+// ignored.
+func (conf Config) parseWarnPaths() [][]pathStep {
+	return parsePaths(conf.WarnPaths)
+}
+
+// isWarnPath reports whether the path p matches any of the parsed WarnPaths.
+func (cmp *comparison) isWarnPath(p path) bool {
+	return matchAnyPath(cmp.warn, p)
+}
+
+// markWarnings turns the errors added since the mark into warnings.
+func (cmp *comparison) markWarnings(mark int) {
+	for i, err := range cmp.errs.List[mark:] {
+		if _, ok := err.(*warningError); !ok {
+			cmp.errs.List[mark+i] = &warningError{err}
+		}
+	}
+}
+
+// warningError wraps an error that represents a difference that is to be
+// reported as a warning rather than as a failure.
+type warningError struct {
+	err error
+}
+
+func (err *warningError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *warningError) format(pr printer) string {
+	prefix := pr.color(yellowColor, "[warning]") + " "
+	return prefix + strings.ReplaceAll(pr.error(err.err), "\n", "\n"+prefix)
+}
+
+func (err *warningError) differences() []Difference {
+	return Differences(err.err)
+}
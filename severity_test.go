@@ -0,0 +1,83 @@
+package compare
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testLogT is a testT that also records the calls made to Logf.
+type testLogT struct {
+	testT
+	logs []string
+}
+
+func (t *testLogT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func TestCompareWarnings(t *testing.T) {
+	type Stats struct {
+		Hits, Misses int
+	}
+	type T struct {
+		Name    string
+		Stats   Stats
+		Version int `cmp:"warn"`
+	}
+
+	tests := []struct {
+		conf               Config
+		a, b               interface{}
+		failures, warnings string
+	}{{
+		conf: Config{WarnPaths: []string{".Stats"}},
+		a:    T{Name: "a", Stats: Stats{1, 2}}, b: T{Name: "a", Stats: Stats{1, 3}},
+		failures: "",
+		warnings: "[warning] - (compare.T).Stats.Misses: Value mismatch; got=2, want=3\n",
+	}, {
+		conf: Config{WarnPaths: []string{".Stats.Hits"}, ObserveFieldTag: "cmp"},
+		a:    T{Name: "a", Stats: Stats{1, 2}, Version: 1}, b: T{Name: "b", Stats: Stats{2, 3}, Version: 2},
+		failures: "- (compare.T).Name: Value mismatch; got=\"a\", want=\"b\"; differs at byte 0 (rune 0)\n" +
+			"- (compare.T).Stats.Misses: Value mismatch; got=2, want=3\n",
+		warnings: "[warning] - (compare.T).Stats.Hits: Value mismatch; got=1, want=2\n" +
+			"[warning] - (compare.T).Version: Value mismatch; got=1, want=2\n",
+	}, {
+		// the summaries are reported with the errors they summarize
+		conf: Config{WarnPaths: []string{".Stats"}, Verbose: true},
+		a:    T{Stats: Stats{1, 2}}, b: T{Stats: Stats{1, 3}},
+		failures: "",
+		warnings: "[warning] - (compare.T).Stats.Misses: Value mismatch; got=2, want=3\n" +
+			"[warning] - (compare.T).Stats: 1/2 fields differ\n",
+	}}
+
+	for _, tt := range tests {
+		err := tt.conf.Compare(tt.a, tt.b)
+		if err == nil {
+			t.Errorf("Compare(%v, %v) got=<nil>, want an error", tt.a, tt.b)
+			continue
+		}
+		if got := Golden(Failures(err)); got != tt.failures {
+			t.Errorf("Failures(Compare(%v, %v)) got:\n%s\nwant:\n%s", tt.a, tt.b, got, tt.failures)
+		}
+		if got := Golden(Warnings(err)); got != tt.warnings {
+			t.Errorf("Warnings(Compare(%v, %v)) got:\n%s\nwant:\n%s", tt.a, tt.b, got, tt.warnings)
+		}
+	}
+
+	// the test helper fails only on failures
+	conf := Config{WarnPaths: []string{".Stats"}}
+	tt := new(testLogT)
+	conf.CompareAllT(tt, []Case{
+		{Name: "soft", Got: T{Stats: Stats{1, 2}}, Want: T{Stats: Stats{1, 3}}},
+		{Name: "hard", Got: T{Name: "a"}, Want: T{Name: "b"}},
+	})
+	if len(tt.errors) != 1 || len(tt.logs) != 1 {
+		t.Errorf("CompareAllT() reported %d errors and %d logs, want 1 and 1", len(tt.errors), len(tt.logs))
+	}
+
+	// errors not returned by Compare are failures
+	other := fmt.Errorf("other")
+	if Failures(other) != other || Warnings(other) != nil {
+		t.Errorf("Failures/Warnings of a foreign error got=(%v, %v)", Failures(other), Warnings(other))
+	}
+}
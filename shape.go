@@ -0,0 +1,66 @@
+package compare
+
+import (
+	"reflect"
+)
+
+// compareShape compares the structure of the got value to that of the want
+// value, which is interpreted as a specification of the shape, see ShapeOnly.
+func (conf Config) compareShape(got, want reflect.Value, cmp *comparison, p path) {
+	switch want.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if want.IsNil() {
+			return
+		}
+		if got.IsNil() {
+			cmp.errs.add(&nilError{got, want, p})
+			return
+		}
+		conf.compare(got.Elem(), want.Elem(), cmp, p)
+	case reflect.Slice:
+		if want.IsNil() {
+			return
+		}
+		if got.IsNil() {
+			cmp.errs.add(&nilError{got, want, p})
+			return
+		}
+		if want.Len() == 0 {
+			return
+		}
+		// the first element of want specifies the shape of all the elements
+		elem := want.Index(0)
+		for i := 0; i < got.Len(); i++ {
+			conf.compare(got.Index(i), elem, cmp, p.add(arrnode{i}))
+		}
+	case reflect.Array:
+		for i := 0; i < want.Len(); i++ {
+			conf.compare(got.Index(i), want.Index(i), cmp, p.add(arrnode{i}))
+		}
+	case reflect.Map:
+		if want.IsNil() {
+			return
+		}
+		if got.IsNil() {
+			cmp.errs.add(&nilError{got, want, p})
+			return
+		}
+		for _, key := range want.MapKeys() {
+			q := p.add(mapnode{key})
+			if g := got.MapIndex(key); !g.IsValid() {
+				cmp.errs.add(&validityError{g, want.MapIndex(key), q, key})
+			} else {
+				conf.compare(g, want.MapIndex(key), cmp, q)
+			}
+		}
+	case reflect.Struct:
+		if structIsTime(want) {
+			return
+		}
+		conf.compareStruct(got, want, cmp, p)
+	case reflect.Func, reflect.Chan:
+		if !want.IsNil() && got.IsNil() {
+			cmp.errs.add(&nilError{got, want, p})
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package compare
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareShapeOnly(t *testing.T) {
+	type Author struct {
+		Name string
+		Born time.Time
+	}
+	type Book struct {
+		ID      int
+		Title   string
+		Author  *Author
+		Tags    []string
+		Authors []*Author
+		Attrs   map[string]interface{}
+		Extra   interface{}
+	}
+
+	spec := Book{
+		Author:  &Author{},
+		Tags:    []string{},
+		Authors: []*Author{{}},
+		Attrs:   map[string]interface{}{"pages": 0},
+	}
+	tests := []struct {
+		got    interface{}
+		reason string
+	}{{
+		got: Book{
+			ID: 1, Title: "a", Author: &Author{Name: "x", Born: time.Now()},
+			Tags: []string{"x", "y"}, Authors: []*Author{{Name: "y"}, {Name: "z"}},
+			Attrs: map[string]interface{}{"pages": 100, "isbn": "1"}, Extra: true,
+		},
+		reason: "",
+	}, {
+		got: Book{
+			Author: nil, Tags: nil,
+			Authors: []*Author{{}, nil},
+			Attrs:   map[string]interface{}{"pages": "100"},
+		},
+		reason: `- (compare.Book).Author: Nil mismatch; got=<nil>, want=&compare.Author{Name:"", Born:time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)}; ` +
+			`- (compare.Book).Tags: Nil mismatch; got=<nil>, want=[]string{}; ` +
+			`- (compare.Book).Authors[1]: Nil mismatch; got=<nil>, want=&compare.Author{Name:"", Born:time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)}; ` +
+			`- (compare.Book).Attrs[pages]: Type mismatch; got=string, want=int`,
+	}, {
+		got:    Book{Author: &Author{}, Tags: []string{}, Authors: []*Author{}, Attrs: map[string]interface{}{}},
+		reason: `- (compare.Book).Attrs[pages]: Key pages missing in got; want=0`,
+	}}
+
+	conf := Config{ShapeOnly: true}
+	for _, tt := range tests {
+		if _, reason := conf.Reason(tt.got, spec); reason != tt.reason {
+			t.Errorf("Reason(%v) got=%q, want=%q", tt.got, reason, tt.reason)
+		}
+		if eq := conf.EqualNoReport(tt.got, spec); eq != (tt.reason == "") {
+			t.Errorf("EqualNoReport(%v) got=%t, want=%t", tt.got, eq, tt.reason == "")
+		}
+	}
+}
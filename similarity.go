@@ -0,0 +1,80 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Similarity is a wrapper around DefaultConfig.Similarity.
+func Similarity(got, want interface{}) float64 {
+	return DefaultConfig.Similarity(got, want)
+}
+
+// Similarity compares the two given values and returns a score between 0 and
+// 1 that measures how similar they are, 1 meaning that they are equal. The
+// score is the fraction of the matching leaves, i.e. of the compared values
+// that have no values of their own compared within them, like the fields of
+// basic types, out of the matching leaves and the differences reported by
+// the comparison. For example, two structs that differ in one out of their
+// four fields of basic types have the score of 0.75, and a difference other
+// than that of two leaves, e.g. a map key missing in got or a length of a
+// slice mismatch, reduces the score as one differing leaf would. The options
+// that limit or annotate the reported differences, e.g. MaxErrors, are not
+// applied.
+func (conf Config) Similarity(got, want interface{}) float64 {
+	if conf.Strict {
+		conf = conf.strict()
+	}
+	conf.MaxErrors, conf.FailFast = 0, false
+	conf.Verbose, conf.AuditSkippedFields = false, false
+
+	cmp := newComparison()
+	defer cmp.release()
+	cmp.cover = new(coverage)
+	conf.run(got, want, cmp, path{rootnode{reflect.TypeOf(want)}})
+
+	var match int
+	for _, e := range cmp.cover.entries {
+		if e.leaf && !e.mismatch {
+			match++
+		}
+	}
+	total := match + len(Differences(cmp.errs.err()))
+	if total == 0 {
+		return 1
+	}
+	return float64(match) / float64(total)
+}
+
+// BestMatch is a wrapper around DefaultConfig.BestMatch.
+func BestMatch(got interface{}, candidates interface{}) (index int, report error) {
+	return DefaultConfig.BestMatch(got, candidates)
+}
+
+// BestMatch compares got to each of the elements of candidates, which must be
+// a slice or an array, and returns the index of the candidate with the fewest
+// differences together with the error that reports them, i.e. the error that
+// Compare(got, candidates[index]) returns. Of the candidates with the same
+// number of differences the first one is chosen. If there are no candidates
+// the index is -1. BestMatch panics if candidates is not a slice or an array.
+func (conf Config) BestMatch(got interface{}, candidates interface{}) (index int, report error) {
+	rv := reflect.ValueOf(candidates)
+	if k := rv.Kind(); k != reflect.Slice && k != reflect.Array {
+		panic(fmt.Sprintf("compare: BestMatch of non-slice candidates of type %T", candidates))
+	}
+	if rv.Len() == 0 {
+		return -1, fmt.Errorf("compare: no candidates to match")
+	}
+
+	index, best := -1, 0
+	for i := 0; i < rv.Len(); i++ {
+		err := conf.Compare(got, rv.Index(i).Interface())
+		if err == nil {
+			return i, nil
+		}
+		if n := len(Differences(err)); index < 0 || n < best {
+			index, best, report = i, n, err
+		}
+	}
+	return index, report
+}
@@ -0,0 +1,109 @@
+package compare
+
+import (
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	type User struct {
+		Name  string
+		Email string
+		Age   int
+		Tags  []string
+	}
+	user := User{"Joe", "joe@example.com", 30, []string{"a", "b"}}
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		want float64
+	}{{
+		a: user, b: user, want: 1,
+	}, {
+		a: User{"Joe", "joe@example.org", 30, []string{"a", "c"}}, b: user,
+		want: 3.0 / 5,
+	}, {
+		a: User{"Jim", "jim@example.org", 31, nil}, b: user,
+		want: 0,
+	}, {
+		a: User{"Joe", "joe@example.com", 30, []string{"a"}}, b: user,
+		want: 3.0 / 4,
+	}, {
+		a: map[string]int{"a": 1}, b: map[string]int{"a": 1, "b": 2},
+		want: 0,
+	}, {
+		conf: Config{ReportMapKeys: true},
+		a:    map[string]int{"a": 1}, b: map[string]int{"a": 1, "b": 2},
+		want: 1.0 / 3,
+	}, {
+		conf: Config{IgnoreArrayOrder: true},
+		a:    []int{3, 2, 1, 9}, b: []int{1, 2, 3, 4},
+		want: 3.0 / 4,
+	}, {
+		a: struct{}{}, b: struct{}{}, want: 1,
+	}, {
+		conf: Config{FailFast: true},
+		a:    []int{1, 2, 3, 4}, b: []int{1, 0, 0, 4},
+		want: 2.0 / 4,
+	}}
+
+	for i, tt := range tests {
+		if got := tt.conf.Similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("#%d: Similarity() got=%v, want=%v", i, got, tt.want)
+		}
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	type User struct {
+		Name  string
+		Email string
+		Age   int
+	}
+	users := []User{
+		{"Jim", "jim@example.com", 40},
+		{"Joe", "joe@example.org", 30},
+		{"Joe", "joe@example.com", 31},
+		{"Joe", "joe@example.net", 30},
+	}
+
+	tests := []struct {
+		got        interface{}
+		candidates interface{}
+		index      int
+		err        string
+	}{{
+		got: User{"Joe", "joe@example.com", 30}, candidates: users,
+		index: 1,
+		err:   `- (compare.User).Email: Value mismatch; got="joe@example.com", want="joe@example.org"; differs at byte 12 (rune 12)`,
+	}, {
+		got: User{"Joe", "joe@example.net", 30}, candidates: users,
+		index: 3,
+		err:   "<nil>",
+	}, {
+		got: 3, candidates: [3]int{1, 3, 3},
+		index: 1,
+		err:   "<nil>",
+	}, {
+		got: User{}, candidates: []User{},
+		index: -1,
+		err:   "compare: no candidates to match",
+	}}
+
+	for i, tt := range tests {
+		index, err := Config{NoColor: true}.BestMatch(tt.got, tt.candidates)
+		if index != tt.index {
+			t.Errorf("#%d: BestMatch() got index=%d, want=%d", i, index, tt.index)
+		}
+		if got := errstr(err); got != tt.err {
+			t.Errorf("#%d: BestMatch() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("BestMatch() of non-slice candidates did not panic")
+		}
+	}()
+	BestMatch(1, 2)
+}
@@ -0,0 +1,90 @@
+package compare
+
+import (
+	"reflect"
+)
+
+// sliceKeyField returns the index of the key field of the struct elements of
+// the given array or slice type, see SliceKey. It reports false if the type
+// has no key field, or if the type of the key field is not comparable.
+func (conf Config) sliceKeyField(typ reflect.Type) (index []int, ok bool) {
+	name, ok := conf.SliceKey[typ.Elem()]
+	if !ok {
+		return nil, false
+	}
+	st := typ.Elem()
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return nil, false
+	}
+	f, ok := st.FieldByName(name)
+	if !ok || !f.Type.Comparable() {
+		return nil, false
+	}
+	return f.Index, true
+}
+
+// sliceKey returns the value of the key field of the given element, or nil if
+// the element is a nil pointer, or if the value of the field cannot be retrieved.
+func sliceKey(elem reflect.Value, index []int) interface{} {
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return nil
+		}
+		elem = elem.Elem()
+	}
+	f, err := elem.FieldByIndexErr(index)
+	if err != nil || !f.Comparable() {
+		// a nil embedded pointer, or an interface
+		// holding a value of an incomparable type
+		return nil
+	}
+	key, _ := interfaceOf(f)
+	return key
+}
+
+// compareArrayByKey compares the contents of the two array values ignoring the
+// order of their elements, the elements of got and want are paired up by the
+// values of their key fields, see SliceKey, and the paired elements are then
+// compared to one another. The elements of want with no pair are reported as
+// missing in got, and the elements of got with no pair as unexpected in got.
+// Elements with the same key are paired up in the order of their appearance.
+func (conf Config) compareArrayByKey(index []int, got, want reflect.Value, cmp *comparison, p path) {
+	if got.Len() != want.Len() {
+		cmp.errs.add(&lenError{got, want, p})
+	}
+
+	gotidx := make(map[interface{}][]int, got.Len())
+	for j := 0; j < got.Len(); j++ {
+		key := sliceKey(got.Index(j), index)
+		gotidx[key] = append(gotidx[key], j)
+	}
+
+	matched := make([]bool, got.Len())
+	diffs := conf.newElemDiffs(cmp)
+	for i := 0; i < want.Len(); i++ {
+		q := p.add(arrnode{i})
+		ithWant := want.Index(i)
+		key := sliceKey(ithWant, index)
+
+		mark := diffs.mark()
+		if js := gotidx[key]; len(js) > 0 {
+			gotidx[key] = js[1:]
+			matched[js[0]] = true
+			conf.compare(got.Index(js[0]), ithWant, cmp, q)
+		} else {
+			cmp.errs.add(&elemError{want: ithWant, path: q})
+		}
+		diffs.check(mark)
+	}
+	for j := range matched {
+		if !matched[j] {
+			mark := diffs.mark()
+			cmp.errs.add(&elemError{got: got.Index(j), path: p.add(arrnode{j})})
+			diffs.check(mark)
+		}
+	}
+	diffs.done(want.Kind(), p)
+}
@@ -0,0 +1,62 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareSliceKey(t *testing.T) {
+	type User struct {
+		ID   int
+		Name string
+	}
+
+	conf := Config{IgnoreArrayOrder: true, SliceKey: map[reflect.Type]string{
+		reflect.TypeOf(User{}):  "ID",
+		reflect.TypeOf(&User{}): "ID",
+	}}
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: conf,
+		a:    []User{{1, "a"}, {2, "b"}, {3, "c"}},
+		b:    []User{{3, "c"}, {1, "a"}, {2, "b"}},
+		err:  "",
+	}, {
+		conf: conf,
+		a:    []User{{1, "a"}, {2, "b"}, {3, "c"}},
+		b:    []User{{3, "c"}, {1, "x"}, {2, "b"}},
+		err:  "- ([]compare.User)[1].Name: Value mismatch; got=\"a\", want=\"x\"; differs at byte 0 (rune 0)\n",
+	}, {
+		conf: conf,
+		a:    []User{{1, "a"}, {4, "d"}},
+		b:    []User{{2, "b"}, {1, "a"}, {3, "c"}},
+		err: "- ([]compare.User): Length of slice mismatch; got=2, want=3\n" +
+			"- ([]compare.User)[0]: Element missing in got; want={2 b}\n" +
+			"- ([]compare.User)[1]: Element unexpected in got; got={4 d}\n" +
+			"- ([]compare.User)[2]: Element missing in got; want={3 c}\n",
+	}, {
+		conf: conf,
+		a:    []*User{{1, "a"}, nil},
+		b:    []*User{nil, {1, "b"}},
+		err:  "- ([]*compare.User)[1].Name: Value mismatch; got=\"a\", want=\"b\"; differs at byte 0 (rune 0)\n",
+	}, {
+		// without IgnoreArrayOrder the order matters
+		conf: Config{SliceKey: conf.SliceKey},
+		a:    []User{{1, "a"}, {2, "b"}},
+		b:    []User{{2, "b"}, {1, "a"}},
+		err: "- ([]compare.User)[0].ID: Value mismatch; got=1, want=2\n" +
+			"- ([]compare.User)[0].Name: Value mismatch; got=\"a\", want=\"b\"; differs at byte 0 (rune 0)\n" +
+			"- ([]compare.User)[1].ID: Value mismatch; got=2, want=1\n" +
+			"- ([]compare.User)[1].Name: Value mismatch; got=\"b\", want=\"a\"; differs at byte 0 (rune 0)\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare(%v, %v) got:\n%s\nwant:\n%s", i, tt.a, tt.b, got, tt.err)
+		}
+	}
+}
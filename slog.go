@@ -0,0 +1,24 @@
+package compare
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogDifferences emits each of the differences represented by the given error,
+// which is expected to be an error returned by Compare, as a separate record
+// to the logger at the given level. Each record has the attributes "path",
+// "kind", "got", and "want" that correspond to the fields of the Difference.
+// LogDifferences reports whether any records were emitted.
+func LogDifferences(ctx context.Context, logger *slog.Logger, level slog.Level, err error) bool {
+	diffs := Differences(err)
+	for _, d := range diffs {
+		logger.LogAttrs(ctx, level, "compare: values differ",
+			slog.String("path", d.Path),
+			slog.String("kind", string(d.Kind)),
+			slog.Any("got", d.Got),
+			slog.Any("want", d.Want),
+		)
+	}
+	return len(diffs) > 0
+}
@@ -0,0 +1,40 @@
+package compare
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestLogDifferences(t *testing.T) {
+	type T struct {
+		Name  string
+		Count int
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	err := Compare(T{"a", 1}, T{"b", 2})
+	if ok := LogDifferences(context.Background(), logger, slog.LevelWarn, err); !ok {
+		t.Errorf("LogDifferences got=false, want=true")
+	}
+	want := `level=WARN msg="compare: values differ" path=.Name kind=value got=a want=b` + "\n" +
+		`level=WARN msg="compare: values differ" path=.Count kind=value got=1 want=2` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("LogDifferences got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if ok := LogDifferences(context.Background(), logger, slog.LevelWarn, nil); ok || buf.Len() > 0 {
+		t.Errorf("LogDifferences(nil) got=%t, %q want=false, \"\"", ok, buf.String())
+	}
+}
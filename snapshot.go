@@ -0,0 +1,101 @@
+package compare
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// SnapshotStore is the storage of the snapshots used by CompareSnapshot and
+// UpdateSnapshot. The snapshots are identified by their names, which use the
+// forward slash as the separator, e.g. "users/list.json". Implementations
+// other than DirStore and FSStore, e.g. one that is backed by a remote object
+// store, can be used in environments where the tests cannot write to the
+// repository tree.
+type SnapshotStore interface {
+	// Load returns the content of the named snapshot. If the snapshot
+	// does not exist the returned error must wrap fs.ErrNotExist.
+	Load(name string) ([]byte, error)
+	// Save creates or replaces the named snapshot.
+	Save(name string, data []byte) error
+}
+
+// DirStore returns a SnapshotStore that keeps the snapshots as files in the
+// given directory of the local filesystem. The missing parent directories of
+// the files are created by Save.
+func DirStore(dir string) SnapshotStore {
+	return dirStore(dir)
+}
+
+type dirStore string
+
+func (dir dirStore) Load(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(string(dir), filepath.FromSlash(name)))
+}
+
+func (dir dirStore) Save(name string, data []byte) error {
+	file := filepath.Join(string(dir), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+// FSStore returns a read-only SnapshotStore that loads the snapshots from the
+// given file system, e.g. an embed.FS. Its Save method always fails.
+func FSStore(fsys fs.FS) SnapshotStore {
+	return fsStore{fsys}
+}
+
+type fsStore struct {
+	fsys fs.FS
+}
+
+func (s fsStore) Load(name string) ([]byte, error) {
+	return fs.ReadFile(s.fsys, name)
+}
+
+func (s fsStore) Save(name string, data []byte) error {
+	return &fs.PathError{Op: "save", Path: name, Err: errors.ErrUnsupported}
+}
+
+// CompareSnapshot is a wrapper around DefaultConfig.CompareSnapshot.
+func CompareSnapshot(store SnapshotStore, name string, got interface{}) error {
+	return DefaultConfig.CompareSnapshot(store, name, got)
+}
+
+// CompareSnapshot loads the named snapshot from the store, decodes it into a
+// new value of got's type, and compares got to the decoded value, as want.
+// The snapshots are encoded as indented JSON, see UpdateSnapshot. A snapshot
+// that does not exist is reported as an error, it is not created.
+func (conf Config) CompareSnapshot(store SnapshotStore, name string, got interface{}) error {
+	if got == nil {
+		return errors.New("compare: cannot snapshot a nil value")
+	}
+	data, err := store.Load(name)
+	if err != nil {
+		return fmt.Errorf("compare: failed to load snapshot %q: %w", name, err)
+	}
+	want := reflect.New(reflect.TypeOf(got))
+	if err := json.Unmarshal(data, want.Interface()); err != nil {
+		return fmt.Errorf("compare: failed to decode snapshot %q: %w", name, err)
+	}
+	return conf.Compare(got, want.Elem().Interface())
+}
+
+// UpdateSnapshot encodes v as indented JSON and saves it in the store as the
+// named snapshot, replacing the existing one, if any.
+func UpdateSnapshot(store SnapshotStore, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return fmt.Errorf("compare: failed to encode snapshot %q: %w", name, err)
+	}
+	if err := store.Save(name, append(data, '\n')); err != nil {
+		return fmt.Errorf("compare: failed to save snapshot %q: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package compare
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+// memStore is a SnapshotStore that keeps the snapshots in memory.
+type memStore map[string][]byte
+
+func (s memStore) Load(name string) ([]byte, error) {
+	if data, ok := s[name]; ok {
+		return data, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (s memStore) Save(name string, data []byte) error {
+	s[name] = data
+	return nil
+}
+
+func TestCompareSnapshot(t *testing.T) {
+	type User struct {
+		Name  string
+		Email string
+		Tags  []string
+	}
+	user := User{Name: "Joe", Email: "joe@example.com", Tags: []string{"admin"}}
+
+	stores := []struct {
+		name  string
+		store SnapshotStore
+	}{
+		{"dir", DirStore(t.TempDir())},
+		{"mem", memStore{}},
+	}
+	for _, s := range stores {
+		err := CompareSnapshot(s.store, "users/joe.json", user)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: CompareSnapshot() with no snapshot got=%v, want fs.ErrNotExist", s.name, err)
+		}
+		if err := UpdateSnapshot(s.store, "users/joe.json", user); err != nil {
+			t.Fatalf("%s: UpdateSnapshot() got=%v", s.name, err)
+		}
+		if err := CompareSnapshot(s.store, "users/joe.json", user); err != nil {
+			t.Errorf("%s: CompareSnapshot() got=%v, want=<nil>", s.name, err)
+		}
+
+		changed := user
+		changed.Email = "joe@example.org"
+		want := "- (compare.User).Email: Value mismatch; got=\"joe@example.org\", want=\"joe@example.com\"; differs at byte 12 (rune 12)\n"
+		if got := Golden(CompareSnapshot(s.store, "users/joe.json", changed)); got != want {
+			t.Errorf("%s: CompareSnapshot() got:\n%s\nwant:\n%s", s.name, got, want)
+		}
+	}
+
+	fsys := fstest.MapFS{"joe.json": {Data: []byte(`{"Name": "Joe", "Tags": ["admin"]}`)}}
+	store := FSStore(fsys)
+	want := "- (compare.User).Email: Value mismatch; got=\"joe@example.com\", want=\"\"; differs at byte 0 (rune 0)\n"
+	if got := Golden(CompareSnapshot(store, "joe.json", user)); got != want {
+		t.Errorf("fs: CompareSnapshot() got:\n%s\nwant:\n%s", got, want)
+	}
+	if err := UpdateSnapshot(store, "joe.json", user); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("fs: UpdateSnapshot() got=%v, want errors.ErrUnsupported", err)
+	}
+}
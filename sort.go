@@ -0,0 +1,55 @@
+package compare
+
+import (
+	"reflect"
+	"sort"
+)
+
+// sortElems returns the sorted copies of the two given array or slice values
+// if a less function is registered for their element type, see SortSlices.
+// It reports whether the values were sorted, if not, they are returned as is.
+func (conf Config) sortElems(got, want reflect.Value) (sortedGot, sortedWant reflect.Value, ok bool) {
+	less, ok := conf.SortSlices[got.Type().Elem()]
+	if !ok {
+		return got, want, false
+	}
+	sortedGot, ok = sortedCopy(got, less)
+	if !ok {
+		return got, want, false
+	}
+	sortedWant, ok = sortedCopy(want, less)
+	if !ok {
+		return got, want, false
+	}
+	return sortedGot, sortedWant, true
+}
+
+// sortedCopy returns a copy of the given array, or a slice with the elements
+// of the given slice, with the elements sorted, stably, using the given less
+// function. It reports false if the elements cannot be retrieved as interface{}
+// values.
+func sortedCopy(v reflect.Value, less func(a, b interface{}) bool) (reflect.Value, bool) {
+	x, ok := interfaceOf(v)
+	if !ok {
+		return v, false
+	}
+	src := reflect.ValueOf(x)
+	elems := make([]interface{}, src.Len())
+	for i := range elems {
+		elems[i] = src.Index(i).Interface()
+	}
+	sort.SliceStable(elems, func(i, j int) bool { return less(elems[i], elems[j]) })
+
+	var out reflect.Value
+	if v.Kind() == reflect.Array {
+		out = reflect.New(v.Type()).Elem()
+	} else {
+		out = reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), len(elems), len(elems))
+	}
+	for i, e := range elems {
+		if e != nil {
+			out.Index(i).Set(reflect.ValueOf(e))
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,99 @@
+package compare
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCompareSortSlices(t *testing.T) {
+	type User struct {
+		ID   int
+		Name string
+	}
+	type T struct {
+		users []User
+		IDs   [3]int
+	}
+
+	conf := Config{SortSlices: map[reflect.Type]func(a, b interface{}) bool{
+		reflect.TypeOf(User{}): func(a, b interface{}) bool { return a.(User).ID < b.(User).ID },
+		reflect.TypeOf(0):      func(a, b interface{}) bool { return a.(int) < b.(int) },
+	}}
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: conf,
+		a:    []User{{3, "c"}, {1, "a"}, {2, "b"}},
+		b:    []User{{1, "a"}, {2, "b"}, {3, "c"}},
+		err:  "",
+	}, {
+		conf: conf,
+		a:    []User{{3, "c"}, {1, "a"}, {2, "x"}},
+		b:    []User{{2, "b"}, {3, "c"}, {1, "a"}},
+		err:  "- ([]compare.User)[1].Name: Value mismatch; got=\"x\", want=\"b\"; differs at byte 0 (rune 0)\n",
+	}, {
+		conf: conf,
+		a:    &T{users: []User{{2, "b"}, {1, "a"}}, IDs: [3]int{3, 2, 1}},
+		b:    &T{users: []User{{1, "a"}, {2, "b"}}, IDs: [3]int{1, 4, 2}},
+		err:  "- (*compare.T).IDs[2]: Value mismatch; got=3, want=4\n",
+	}, {
+		// the sorted copies of arrays are arrays
+		conf: Config{SortSlices: conf.SortSlices, MaxDiffsPerCollection: 1},
+		a:    [3]int{3, 2, 1}, b: [3]int{4, 5, 1},
+		err: "- ([3]int): ...and 1 more differing elements of array\n" +
+			"- ([3]int)[1]: Value mismatch; got=2, want=4\n",
+	}, {
+		conf: conf,
+		a:    []int{2, 1}, b: []int{1, 2, 3},
+		err: "- ([]int): Length of slice mismatch; got=2, want=3\n",
+	}, {
+		// the sorting takes precedence over IgnoreArrayOrder
+		conf: Config{SortSlices: conf.SortSlices, IgnoreArrayOrder: true},
+		a:    []int{3, 1, 2}, b: []int{2, 3, 5},
+		err: "- ([]int)[0]: Value mismatch; got=1, want=2\n" +
+			"- ([]int)[1]: Value mismatch; got=2, want=3\n" +
+			"- ([]int)[2]: Value mismatch; got=3, want=5\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare(%v, %v) got:\n%s\nwant:\n%s", i, tt.a, tt.b, got, tt.err)
+		}
+	}
+
+	// the compared values are not modified
+	a := []int{2, 1}
+	if err := conf.Compare(a, []int{1, 2}); err != nil || a[0] != 2 {
+		t.Errorf("Compare() got=%v, a=%v, want=<nil>, a=[2 1]", err, a)
+	}
+}
+
+func TestCompareSortSlicesCollectedCopies(t *testing.T) {
+	type leaf struct{ N int }
+	type node struct{ In []*leaf }
+
+	// the less func runs the garbage collector so that the sorted copies of
+	// the compared elements are collected, and their addresses possibly
+	// reused, while the comparison is still being done
+	conf := Config{SortSlices: map[reflect.Type]func(a, b interface{}) bool{
+		reflect.TypeOf(&leaf{}): func(a, b interface{}) bool {
+			runtime.GC()
+			return a.(*leaf).N < b.(*leaf).N
+		},
+	}}
+
+	got, want := make([]node, 50), make([]node, 50)
+	for i := range got {
+		got[i] = node{In: []*leaf{{i}, {-i}}}
+		want[i] = node{In: []*leaf{{i + 1}, {-i}}}
+	}
+	want[0] = got[0]
+
+	if n := len(Differences(conf.Compare(got, want))); n != 49 {
+		t.Errorf("Compare() got %d differences, want 49", n)
+	}
+}
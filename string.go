@@ -1,6 +1,8 @@
 package compare
 
 import (
+	"fmt"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -90,3 +92,82 @@ func strim(s string, pos, max int) string {
 	}
 	return s
 }
+
+// swindow returns the bounds of the window of the string s that contains the
+// s[start:end] part and at most context of the bytes on either side of it. The
+// bounds are aligned to the boundaries of the runes of s.
+func swindow(s string, start, end, context int) (lo, hi int) {
+	lo, hi = max(0, start-context), min(len(s), end+context)
+	for lo > 0 && !utf8.RuneStart(s[lo]) {
+		lo--
+	}
+	for hi < len(s) && !utf8.RuneStart(s[hi]) {
+		hi++
+	}
+	return lo, hi
+}
+
+// lineContext is the number of the unchanged lines shown around the changed
+// lines of a lineDiff.
+const lineContext = 3
+
+// lineDiff returns the line-based diff of the strings a and b in the style of
+// a unified diff, i.e. the lines of the returned hunks are each prefixed with
+// "-" if they are missing in b, with "+" if they are missing in a, and with
+// " " otherwise, and each hunk is preceded by its "@@ -l,s +l,s @@" header.
+// At most context unchanged lines are shown around the changed ones.
+func lineDiff(a, b string, context int) (lines []string) {
+	al, bl := strings.Split(a, "\n"), strings.Split(b, "\n")
+	edits := diffEdits(len(al), len(bl), func(i, j int) bool { return al[i] == bl[j] })
+
+	for i := 0; i < len(edits); {
+		if edits[i].Kind == KeepEdit {
+			i++
+			continue
+		}
+
+		// extend the hunk over the changes that are at most
+		// 2*context unchanged lines apart from each other
+		start, end := max(0, i-context), i
+		for j := i; j < len(edits) && j <= end+2*context+1; j++ {
+			if edits[j].Kind != KeepEdit {
+				end = j
+			}
+		}
+		end = min(len(edits), end+context+1)
+
+		var astart, bstart, alen, blen int
+		for _, e := range edits[:start] {
+			if e.A >= 0 {
+				astart++
+			}
+			if e.B >= 0 {
+				bstart++
+			}
+		}
+		hunk := make([]string, 0, end-start)
+		for _, e := range edits[start:end] {
+			switch e.Kind {
+			case KeepEdit:
+				hunk = append(hunk, " "+al[e.A])
+				alen, blen = alen+1, blen+1
+			case DeleteEdit:
+				hunk = append(hunk, "-"+al[e.A])
+				alen++
+			case InsertEdit:
+				hunk = append(hunk, "+"+bl[e.B])
+				blen++
+			}
+		}
+		if alen > 0 {
+			astart++
+		}
+		if blen > 0 {
+			bstart++
+		}
+		lines = append(lines, fmt.Sprintf("@@ -%d,%d +%d,%d @@", astart, alen, bstart, blen))
+		lines = append(lines, hunk...)
+		i = end
+	}
+	return lines
+}
@@ -2,6 +2,7 @@ package compare
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -84,3 +85,180 @@ func Test_strim(t *testing.T) {
 		}
 	}
 }
+
+func TestStringErrorOffset(t *testing.T) {
+	tests := []struct {
+		got, want string
+		out       string
+	}{{
+		got: "abc", want: "abd",
+		out: `- (string): Value mismatch; got="abc", want="abd"; differs at byte 2 (rune 2)`,
+	}, {
+		got: "日本語", want: "日本人",
+		out: `- (string): Value mismatch; got="日本語", want="日本人"; differs at byte 6 (rune 2)`,
+	}, {
+		got: "abc", want: "abcd",
+		out: `- (string): Value mismatch; got="abc", want="abcd"; differs at byte 3 (rune 3)`,
+	}, {
+		got: "a\nb", want: "a\nc",
+		out: `- (string): Value mismatch; got="a\nb", want="a\nc"`,
+	}}
+
+	for i, tt := range tests {
+		err := newStringError(tt.got, tt.want, path{rootnode{reflect.TypeOf("")}})
+		if out := err.format(printer{nocolor: true}); out != tt.out {
+			t.Errorf("#%d: format(%q, %q) got=%q, want=%q", i, tt.got, tt.want, out, tt.out)
+		}
+	}
+}
+
+func Test_lineDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []string
+	}{{
+		a: "a\nb\nc", b: "a\nb\nc",
+		want: nil,
+	}, {
+		a: "a\nb\nc", b: "a\nx\nc",
+		want: []string{"@@ -1,3 +1,3 @@", " a", "-b", "+x", " c"},
+	}, {
+		a: "a\nb", b: "a\nb\nc",
+		want: []string{"@@ -1,2 +1,3 @@", " a", " b", "+c"},
+	}, {
+		a: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12", b: "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny",
+		want: []string{
+			"@@ -1,4 +1,4 @@", "-1", "+x", " 2", " 3", " 4",
+			"@@ -9,4 +9,4 @@", " 9", " 10", " 11", "-12", "+y",
+		},
+	}, {
+		a: "1\n2\n3\n4\n5", b: "1\n2\n3\n4\n5\n6",
+		want: []string{"@@ -3,3 +3,4 @@", " 3", " 4", " 5", "+6"},
+	}}
+
+	for i, tt := range tests {
+		got := lineDiff(tt.a, tt.b, 3)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d: lineDiff(%q, %q) got=%q, want=%q", i, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareLineDiff(t *testing.T) {
+	type Q struct{ SQL string }
+	got := Q{SQL: "SELECT a\nFROM t\nWHERE b = 1"}
+	want := Q{SQL: "SELECT a\nFROM t\nWHERE b = 2"}
+
+	conf := Config{LineDiff: true, NoColor: true}
+	out := "- (compare.Q).SQL: Value mismatch (-got +want):\n" +
+		"\t@@ -1,3 +1,3 @@\n" +
+		"\t SELECT a\n" +
+		"\t FROM t\n" +
+		"\t-WHERE b = 1\n" +
+		"\t+WHERE b = 2"
+	if err := conf.Compare(got, want); err == nil || err.Error() != out {
+		t.Errorf("Compare() with LineDiff got=%v, want=%q", err, out)
+	}
+
+	// single-line strings keep the default rendering
+	out = `- (string): Value mismatch; got="ab", want="ac"; differs at byte 1 (rune 1)`
+	if err := conf.Compare("ab", "ac"); err == nil || err.Error() != out {
+		t.Errorf("Compare() with LineDiff got=%v, want=%q", err, out)
+	}
+
+	// the differences are unaffected by the rendering
+	diffs := Differences(conf.Compare(got, want))
+	if len(diffs) != 1 || diffs[0].Got != got.SQL || diffs[0].Want != want.SQL {
+		t.Errorf("Diff() with LineDiff got=%+v", diffs)
+	}
+}
+
+func Test_swindow(t *testing.T) {
+	tests := []struct {
+		s               string
+		start, end, ctx int
+		wantLo, wantHi  int
+	}{
+		{s: "abcdefghij", start: 5, end: 6, ctx: 2, wantLo: 3, wantHi: 8},
+		{s: "abcdefghij", start: 1, end: 2, ctx: 3, wantLo: 0, wantHi: 5},
+		{s: "abcdefghij", start: 9, end: 10, ctx: 3, wantLo: 6, wantHi: 10},
+		{s: "abcdefghij", start: 10, end: 10, ctx: 3, wantLo: 7, wantHi: 10},
+		{s: "日本語日本語", start: 9, end: 12, ctx: 4, wantLo: 3, wantHi: 18},
+	}
+
+	for i, tt := range tests {
+		lo, hi := swindow(tt.s, tt.start, tt.end, tt.ctx)
+		if lo != tt.wantLo || hi != tt.wantHi {
+			t.Errorf("#%d: swindow(%q, %d, %d, %d) got=%d,%d, want=%d,%d",
+				i, tt.s, tt.start, tt.end, tt.ctx, lo, hi, tt.wantLo, tt.wantHi)
+		}
+	}
+}
+
+func TestCompareStringContext(t *testing.T) {
+	tests := []struct {
+		conf      Config
+		got, want string
+		out       string
+	}{{
+		conf: Config{StringContext: 5, NoColor: true},
+		got:  "the quick brown fox jumps over the lazy dog",
+		want: "the quick brown cat jumps over the lazy dog",
+		out: `- (string): Value mismatch; got="…rown fox jump…", want="…rown cat jump…"; ` +
+			`differs at byte 16 (rune 16)`,
+	}, {
+		conf: Config{StringContext: 5, NoColor: true},
+		got:  "abc", want: "abd",
+		out: `- (string): Value mismatch; got="abc", want="abd"; differs at byte 2 (rune 2)`,
+	}, {
+		conf: Config{StringContext: 4, NoColor: true},
+		got:  "lorem ipsum dolor", want: "lorem ipsum",
+		out: `- (string): Value mismatch; got="…psum dolor", want="…psum"; differs at byte 11 (rune 11)`,
+	}, {
+		conf: Config{StringContext: 3, ASCII: true},
+		got:  "0123456789", want: "0123x56789",
+		out: `- (string): Value mismatch; got="...1234567...", want="...123x567..."; ` +
+			`differs at byte 4 (rune 4)`,
+	}, {
+		conf: Config{StringContext: 3, NoColor: true},
+		got:  "ab\ncdefgh", want: "ab\ncdefgX",
+		out: `- (string): Value mismatch; got="ab\ncdefgh", want="ab\ncdefgX"`,
+	}}
+
+	for i, tt := range tests {
+		if err := tt.conf.Compare(tt.got, tt.want); err == nil || err.Error() != tt.out {
+			t.Errorf("#%d: Compare(%q, %q) got=%v, want=%q", i, tt.got, tt.want, err, tt.out)
+		}
+	}
+
+	// the highlighted rendering is trimmed the same way
+	err := newStringError("0123456789", "0123x56789", path{rootnode{reflect.TypeOf("")}})
+	out := err.format(printer{strctx: 2})
+	want := gotColor + `"…23` + stopColor + diffGotColor + "4" + diffGotStopColor + gotColor + `56…"` + stopColor
+	if !strings.Contains(out, want) {
+		t.Errorf("format() got=%q, want it to contain %q", out, want)
+	}
+}
+
+func TestCompareNoStringHighlight(t *testing.T) {
+	err := Config{NoStringHighlight: true}.Compare("abc", "abd")
+	out := err.Error()
+	got, want := gotColor+`"abc"`+stopColor, wantColor+`"abd"`+stopColor
+	if !strings.Contains(out, got) || !strings.Contains(out, want) {
+		t.Errorf("Compare() with NoStringHighlight got=%q, want it to contain %q and %q", out, got, want)
+	}
+	if strings.Contains(out, diffGotColor) || strings.Contains(out, diffWantColor) {
+		t.Errorf("Compare() with NoStringHighlight got=%q, want no highlight", out)
+	}
+
+	// without the highlight the invisible characters are escaped
+	out = Config{NoStringHighlight: true}.Compare("a\u200bb", "ab").Error()
+	if want := gotColor + `"a\u200bb"` + stopColor; !strings.Contains(out, want) {
+		t.Errorf("Compare() with NoStringHighlight got=%q, want it to contain %q", out, want)
+	}
+
+	out = Compare("abc", "abd").Error()
+	if !strings.Contains(out, diffGotColor) {
+		t.Errorf("Compare() got=%q, want the difference highlighted", out)
+	}
+}
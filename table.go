@@ -0,0 +1,230 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Expectation is a single row of a table of expectations, see CompareTable.
+type Expectation struct {
+	// The path to the expected value, in the same syntax as that used by
+	// Difference.Path, e.g. ".Authors[0].FirstName". Map keys of kind string
+	// can be either quoted, e.g. `["a.b"]`, or unquoted, e.g. `[a]`.
+	Path string
+	// The expected value.
+	Want interface{}
+}
+
+// CompareTable is a wrapper around DefaultConfig.CompareTable.
+func CompareTable(got interface{}, table []Expectation) error {
+	return DefaultConfig.CompareTable(got, table)
+}
+
+// CompareTable compares the values found in got at the paths of the table's
+// rows to the rows' expected values, and if any of the comparisons fails it
+// returns an error that indicates where the values differ. The values of got
+// that are not referenced by the table are not compared. Paths that cannot be
+// resolved, e.g. because of a misspelled field name or an out of range index,
+// are reported as errors.
+//
+// Since the expected values are often written as untyped constants, numbers
+// are compared by their numeric value regardless of their types, as if the
+// LooseNumericTypes option was set.
+func (conf Config) CompareTable(got interface{}, table []Expectation) error {
+	if conf.Strict {
+		conf = conf.strict()
+	}
+	conf.LooseNumericTypes = true
+
+	gotv := reflect.ValueOf(got)
+	cmp := newComparison()
+	defer cmp.release()
+	cmp.errs.pr = conf.printer()
+	cmp.keysAt = conf.parseIgnoredMapKeysAt()
+	for _, row := range table {
+		p := path{rootnode{reflect.TypeOf(got)}}
+		steps, err := parsePath(row.Path)
+		if err != nil {
+			cmp.errs.add(&pathError{p.add(elemnode{row.Path}), err.Error()})
+			continue
+		}
+		v, p, ok := resolvePath(gotv, steps, row.Want, cmp, p)
+		if !ok {
+			continue
+		}
+		conf.compare(v, reflect.ValueOf(row.Want), cmp, p)
+	}
+	return cmp.errs.err()
+}
+
+// pathStep is a single step of a parsed path, i.e. either ".name" or "[key]".
+type pathStep struct {
+	field    string // set for the ".name" steps
+	key      string // the unquoted content of the "[key]" steps
+	index    bool   // set for the "[key]" steps
+	wildcard bool   // set for the ".*" and the unquoted "[*]" steps
+}
+
+func (s pathStep) String() string {
+	if s.index {
+		return "[" + s.key + "]"
+	}
+	return "." + s.field
+}
+
+// parsePath parses the given path, e.g. `.Authors[0].FirstName` or `["a"].b`.
+func parsePath(s string) (steps []pathStep, err error) {
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			j := i + 1
+			for j < len(s) && s[j] != '.' && s[j] != '[' {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("empty field name at offset %d", i)
+			}
+			steps = append(steps, pathStep{field: s[i+1 : j], wildcard: s[i+1:j] == "*"})
+			i = j
+		case '[':
+			if i+1 < len(s) && s[i+1] == '"' {
+				q, err := strconv.QuotedPrefix(s[i+1:])
+				if err != nil || i+1+len(q) >= len(s) || s[i+1+len(q)] != ']' {
+					return nil, fmt.Errorf("malformed quoted key at offset %d", i)
+				}
+				key, _ := strconv.Unquote(q)
+				steps = append(steps, pathStep{key: key, index: true})
+				i += len(q) + 2
+				continue
+			}
+			j := strings.IndexByte(s[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("missing ] at offset %d", i)
+			}
+			steps = append(steps, pathStep{key: s[i+1 : i+j], index: true, wildcard: s[i+1:i+j] == "*"})
+			i += j + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+		}
+	}
+	return steps, nil
+}
+
+// resolvePath returns the value found at the given steps from v and its path.
+// If the path cannot be resolved, an error is added to the comparison and the
+// ok return value is false.
+func resolvePath(v reflect.Value, steps []pathStep, want interface{}, cmp *comparison, p path) (_ reflect.Value, _ path, ok bool) {
+	for _, step := range steps {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				cmp.errs.add(&pathError{p.add(elemnode{step.String()}), "nil " + v.Kind().String()})
+				return v, p, false
+			}
+			v = v.Elem()
+		}
+
+		switch {
+		case !step.index && v.Kind() == reflect.Struct:
+			f, found := v.Type().FieldByName(step.field)
+			if !found {
+				reason := fmt.Sprintf("no field %s in %s", step.field, v.Type())
+				cmp.errs.add(&pathError{p.add(elemnode{step.String()}), reason})
+				return v, p, false
+			}
+			v, p = v.FieldByIndex(f.Index), p.add(structnode{step.field})
+		case step.index && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+			i, err := strconv.Atoi(step.key)
+			if err != nil || i < 0 || i >= v.Len() {
+				reason := fmt.Sprintf("index %s out of range with length %d", step.key, v.Len())
+				cmp.errs.add(&pathError{p.add(elemnode{step.String()}), reason})
+				return v, p, false
+			}
+			v, p = v.Index(i), p.add(arrnode{i})
+		case step.index && v.Kind() == reflect.Map:
+			key, err := parseKey(step.key, v.Type().Key())
+			if err != nil {
+				cmp.errs.add(&pathError{p.add(elemnode{step.String()}), err.Error()})
+				return v, p, false
+			}
+			q := p.add(mapnode{key})
+			if v = v.MapIndex(key); !v.IsValid() {
+				cmp.errs.add(&validityError{v, reflect.ValueOf(want), q, key})
+				return v, q, false
+			}
+			p = q
+		default:
+			reason := fmt.Sprintf("cannot apply %s to %s", step, typeString(typeOf(v)))
+			cmp.errs.add(&pathError{p.add(elemnode{step.String()}), reason})
+			return v, p, false
+		}
+	}
+
+	// the want value is never of an interface kind
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v, p, true
+}
+
+// parseKey parses the string s as a map key of the given type.
+func parseKey(s string, typ reflect.Type) (reflect.Value, error) {
+	key := reflect.New(typ).Elem()
+	var err error
+	switch typ.Kind() {
+	case reflect.String:
+		key.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, typ.Bits()); err == nil {
+			key.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, typ.Bits()); err == nil {
+			key.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, typ.Bits()); err == nil {
+			key.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			key.SetBool(b)
+		}
+	default:
+		return key, fmt.Errorf("unsupported map key type %s", typ)
+	}
+	if err != nil {
+		return key, fmt.Errorf("invalid %s map key %q", typ, s)
+	}
+	return key, nil
+}
+
+// typeOf returns the type of v, or nil if v is invalid.
+func typeOf(v reflect.Value) reflect.Type {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Type()
+}
+
+type pathError struct {
+	path   path
+	reason string
+}
+
+func (err *pathError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *pathError) format(pr printer) string {
+	return fmt.Sprintf("%s: Invalid path; %s", err.path.format(pr), pr.color(yellowColor, pr.text(err.reason)))
+}
+
+func (err *pathError) differences() []Difference {
+	return []Difference{{err.path.relpath(), PathDiff, nil, nil, nil}}
+}
@@ -0,0 +1,113 @@
+package compare
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareTable(t *testing.T) {
+	type Author struct {
+		FirstName string
+		LastName  string
+	}
+	type Book struct {
+		Title   string
+		Pages   int
+		Authors []*Author
+		Meta    map[string]interface{}
+		Ratings map[int]float64
+		isbn    string
+	}
+	book := &Book{
+		Title:   "Kafka on the Shore",
+		Pages:   505,
+		Authors: []*Author{{FirstName: "Haruki", LastName: "Murakami"}},
+		Meta:    map[string]interface{}{"lang": "en", "a.b": []int{1}},
+		Ratings: map[int]float64{5: 0.5},
+		isbn:    "0099458322",
+	}
+
+	tests := []struct {
+		table []Expectation
+		err   error
+	}{{
+		table: []Expectation{
+			{Path: ".Title", Want: "Kafka on the Shore"},
+			{Path: ".Pages", Want: 505.0},
+			{Path: ".Authors[0].LastName", Want: "Murakami"},
+			{Path: ".Authors[0]", Want: &Author{FirstName: "Haruki", LastName: "Murakami"}},
+			{Path: ".Meta[lang]", Want: "en"},
+			{Path: `.Meta["a.b"][0]`, Want: 1},
+			{Path: ".Ratings[5]", Want: 0.5},
+			{Path: ".isbn", Want: "0099458322"},
+			{Path: "", Want: book},
+		},
+		err: nil,
+	}, {
+		table: []Expectation{
+			{Path: ".Pages", Want: 506},
+			{Path: ".Meta[missing]", Want: "x"},
+		},
+		err: elist(&valueError{
+			got: int(505), want: int(506),
+			path: path{rootnode{rtof(book)}, structnode{field: "Pages"}},
+		}, &validityError{
+			got: rvof(nil), want: rvof("x"),
+			path: path{rootnode{rtof(book)}, structnode{field: "Meta"}, mapnode{rvof("missing")}},
+			key:  rvof("missing"),
+		}),
+	}, {
+		table: []Expectation{
+			{Path: ".Titel", Want: "x"},
+			{Path: ".Authors[1].LastName", Want: "x"},
+			{Path: ".Ratings[x]", Want: 1},
+			{Path: ".Title[0]", Want: "x"},
+			{Path: "Title", Want: "x"},
+		},
+		err: elist(&pathError{
+			path:   path{rootnode{rtof(book)}, elemnode{".Titel"}},
+			reason: "no field Titel in compare.Book",
+		}, &pathError{
+			path:   path{rootnode{rtof(book)}, structnode{field: "Authors"}, elemnode{"[1]"}},
+			reason: "index 1 out of range with length 1",
+		}, &pathError{
+			path:   path{rootnode{rtof(book)}, structnode{field: "Ratings"}, elemnode{"[x]"}},
+			reason: `invalid int map key "x"`,
+		}, &pathError{
+			path:   path{rootnode{rtof(book)}, structnode{field: "Title"}, elemnode{"[0]"}},
+			reason: "cannot apply [0] to string",
+		}, &pathError{
+			path:   path{rootnode{rtof(book)}, elemnode{"Title"}},
+			reason: `unexpected 'T' at offset 0`,
+		}),
+	}}
+
+	for _, tt := range tests {
+		if err := CompareTable(book, tt.table); errstr(err) != errstr(tt.err) {
+			t.Errorf("CompareTable(%v) = %v\n\n", tt.table, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(tt.err))
+		}
+	}
+}
+
+func Test_parsePath(t *testing.T) {
+	tests := []struct {
+		s     string
+		steps []pathStep
+		err   error
+	}{
+		{s: "", steps: nil},
+		{s: ".A.b", steps: []pathStep{{field: "A"}, {field: "b"}}},
+		{s: "[0][k]", steps: []pathStep{{key: "0", index: true}, {key: "k", index: true}}},
+		{s: `["a]\"b"].C`, steps: []pathStep{{key: `a]"b`, index: true}, {field: "C"}}},
+		{s: ".", err: errors.New("empty field name at offset 0")},
+		{s: "[0", err: errors.New("missing ] at offset 0")},
+		{s: `["a"`, err: errors.New("malformed quoted key at offset 0")},
+	}
+	for _, tt := range tests {
+		steps, err := parsePath(tt.s)
+		if errstr(err) != errstr(tt.err) || (err == nil && !EqualNoReport(steps, tt.steps)) {
+			t.Errorf("parsePath(%q) got=(%v, %v), want=(%v, %v)", tt.s, steps, err, tt.steps, tt.err)
+		}
+	}
+}
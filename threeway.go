@@ -0,0 +1,378 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Compare3 is a wrapper around DefaultConfig.Compare3.
+func Compare3(base, got, want interface{}) error {
+	return DefaultConfig.Compare3(base, got, want)
+}
+
+// Compare3 compares the got and want values to their common ancestor, the
+// base value, and if either of them differs from the base it returns an error
+// that lists the changes made on each side relative to the base. The changes
+// made on both sides at the same path are reported either as identical, if
+// the got and want values are equal at that path, or as conflicting. The
+// changes are reported at the deepest path at which all of the three values
+// can still be compared element by element, e.g. the change of a single field
+// of a struct is reported at that field, while the change of the length of a
+// slice is reported at the slice.
+func (conf Config) Compare3(base, got, want interface{}) error {
+	if conf.Strict {
+		conf = conf.strict()
+	}
+
+	tw := &threeWay{errs: &errorList{pr: conf.printer()}, visits: make(map[visit3]bool)}
+	tw.cmp = &comparison{keysAt: conf.parseIgnoredMapKeysAt()}
+	p := path{rootnode{reflect.TypeOf(base)}}
+	conf.compare3(reflect.ValueOf(base), reflect.ValueOf(got), reflect.ValueOf(want), tw, p)
+	return tw.errs.err()
+}
+
+// threeWay holds the state of the Compare3 function.
+type threeWay struct {
+	errs   *errorList
+	visits map[visit3]bool // track pointers already descended into
+	cmp    *comparison     // holds the parsed paths used by equals
+}
+
+// visit3 is the key of the visits map of a three-way comparison.
+type visit3 struct {
+	base, got, want unsafe.Pointer
+	typ             reflect.Type
+}
+
+func (conf Config) compare3(base, got, want reflect.Value, tw *threeWay, p path) {
+	gotChanged, wantChanged := !conf.equals(got, base, tw.cmp, p), !conf.equals(want, base, tw.cmp, p)
+	if !gotChanged && !wantChanged {
+		return
+	}
+	if conf.descend3(base, got, want, tw, p) {
+		return
+	}
+
+	var side changeSide
+	switch {
+	case !wantChanged:
+		side = gotChange
+	case !gotChanged:
+		side = wantChange
+	case conf.equals(got, want, tw.cmp, p):
+		side = bothChange
+	default:
+		side = conflictChange
+	}
+	tw.errs.add(&changeError{side, base, got, want, p})
+}
+
+// descend3 compares the elements of the three values if they are of the same
+// shape, that is, if they are of the same type and, depending on their kind,
+// are either all non-nil or all of the same length. It reports whether the
+// elements were compared.
+func (conf Config) descend3(base, got, want reflect.Value, tw *threeWay, p path) bool {
+	if !base.IsValid() || !got.IsValid() || !want.IsValid() {
+		return false
+	}
+	if typ := base.Type(); got.Type() != typ || want.Type() != typ {
+		return false
+	}
+
+	switch base.Kind() {
+	case reflect.Ptr:
+		if base.IsNil() || got.IsNil() || want.IsNil() {
+			return false
+		}
+		v := visit3{base.UnsafePointer(), got.UnsafePointer(), want.UnsafePointer(), base.Type()}
+		if tw.visits[v] {
+			return true
+		}
+		tw.visits[v] = true
+		conf.compare3(base.Elem(), got.Elem(), want.Elem(), tw, p)
+	case reflect.Interface:
+		if base.IsNil() || got.IsNil() || want.IsNil() {
+			return false
+		}
+		return conf.descend3(base.Elem(), got.Elem(), want.Elem(), tw, p)
+	case reflect.Struct:
+		if structIsTime(base) {
+			return false
+		}
+		for i, n := 0, base.NumField(); i < n; i++ {
+			if f := base.Type().Field(i); !conf.isOmittedField(f) {
+				q := p.add(structnode{f.Name})
+				conf.compare3(base.Field(i), got.Field(i), want.Field(i), tw, q)
+			}
+		}
+	case reflect.Map:
+		if base.IsNil() || got.IsNil() || want.IsNil() {
+			return false
+		}
+		for _, key := range mapKeys3(base, got, want) {
+			q := p.add(mapnode{key})
+			conf.compare3(base.MapIndex(key), got.MapIndex(key), want.MapIndex(key), tw, q)
+		}
+	case reflect.Slice, reflect.Array:
+		if base.Kind() == reflect.Slice && (base.IsNil() || got.IsNil() || want.IsNil()) {
+			return false
+		}
+		if n := base.Len(); got.Len() != n || want.Len() != n {
+			return false
+		}
+		for i, n := 0, base.Len(); i < n; i++ {
+			conf.compare3(base.Index(i), got.Index(i), want.Index(i), tw, p.add(arrnode{i}))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// isOmittedField reports whether the struct field is omitted from comparison
+// regardless of its value, i.e. by IgnoreFieldNames or by the "-" tag option.
+func (conf Config) isOmittedField(f reflect.StructField) bool {
+	if conf.isIgnoredField(f.Name) {
+		return true
+	}
+	return len(conf.ObserveFieldTag) > 0 && f.Tag.Get(conf.ObserveFieldTag) == "-"
+}
+
+// mapKeys3 returns the union of the keys of the three maps, sorted by their
+// textual representation.
+func mapKeys3(maps ...reflect.Value) []reflect.Value {
+	var keys []reflect.Value
+	seen := make(map[string]bool)
+	for _, m := range maps {
+		for _, key := range m.MapKeys() {
+			if s := fmt.Sprintf("%#v", key); !seen[s] {
+				seen[s] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sortMapKeys(keys)
+	return keys
+}
+
+// changeSide identifies the side of a three-way comparison on which a change
+// was made.
+type changeSide uint8
+
+const (
+	gotChange changeSide = iota
+	wantChange
+	bothChange
+	conflictChange
+)
+
+type changeError struct {
+	side changeSide
+	base reflect.Value
+	got  reflect.Value
+	want reflect.Value
+	path path
+}
+
+func (err *changeError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *changeError) format(pr printer) string {
+	base := pr.change(err.base)
+	got := pr.color(gotColor, pr.change(err.got))
+	want := pr.color(wantColor, pr.change(err.want))
+
+	switch err.side {
+	case gotChange:
+		return fmt.Sprintf("%s: Changed in got; base=%s, got=%s", err.path.format(pr), base, got)
+	case wantChange:
+		return fmt.Sprintf("%s: Changed in want; base=%s, want=%s", err.path.format(pr), base, want)
+	case bothChange:
+		return fmt.Sprintf("%s: Changed in both; base=%s, got=%s, want=%s", err.path.format(pr), base, got, want)
+	}
+	return fmt.Sprintf("%s: Conflicting changes; base=%s, got=%s, want=%s", err.path.format(pr), base, got, want)
+}
+
+func (err *changeError) differences() []Difference {
+	kind := [...]DiffKind{GotChangeDiff, WantChangeDiff, BothChangeDiff, ConflictDiff}[err.side]
+	detail := ChangeDifference{valueOf(err.base)}
+	return []Difference{{err.path.relpath(), kind, valueOf(err.got), valueOf(err.want), detail}}
+}
+
+// change renders one of the values of a three-way comparison, the values
+// that are missing, e.g. the entries of a map that were added or removed,
+// are rendered as "<none>".
+func (pr printer) change(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<none>"
+	}
+	return pr.composite(v)
+}
+
+// Merge is a wrapper around DefaultConfig.Merge.
+func Merge(base, got, want interface{}) (merged interface{}, conflicts []Difference, err error) {
+	return DefaultConfig.Merge(base, got, want)
+}
+
+// Merge merges the changes made in the got and want values relative to their
+// common ancestor, the base value, into a new value and returns it, together
+// with the list of the conflicting changes, i.e. those made differently on
+// both sides at the same path, see Compare3. The paths with conflicting changes
+// retain the base value in the merged value. The parts of the merged value that
+// were not changed, or that were changed on only one side, may share memory
+// with the corresponding parts of the base, got, or want values. Merge returns
+// an error if the three values are not of the same type.
+func (conf Config) Merge(base, got, want interface{}) (merged interface{}, conflicts []Difference, err error) {
+	if conf.Strict {
+		conf = conf.strict()
+	}
+
+	typ := reflect.TypeOf(base)
+	if reflect.TypeOf(got) != typ || reflect.TypeOf(want) != typ {
+		return nil, nil, fmt.Errorf("compare: cannot merge values of types %s, %s, and %s",
+			typeString(typ), typeString(reflect.TypeOf(got)), typeString(reflect.TypeOf(want)))
+	}
+	if typ == nil {
+		return nil, nil, nil
+	}
+
+	m := &merger{errs: new(errorList), visits: make(map[visit3]reflect.Value)}
+	m.cmp = &comparison{keysAt: conf.parseIgnoredMapKeysAt()}
+	baseCopy, gotCopy, wantCopy := ptrCopy(reflect.ValueOf(base)), ptrCopy(reflect.ValueOf(got)), ptrCopy(reflect.ValueOf(want))
+	v := conf.merge3(baseCopy.Elem(), gotCopy.Elem(), wantCopy.Elem(), m, path{rootnode{typ}})
+	return v.Interface(), m.errs.differences(), nil
+}
+
+// merger holds the state of the Merge function.
+type merger struct {
+	errs   *errorList // the conflicts
+	visits map[visit3]reflect.Value
+	cmp    *comparison // holds the parsed paths used by equals
+}
+
+// merge3 returns the merged value of the three given values, the result is
+// invalid if the value was removed on the side whose change was merged.
+func (conf Config) merge3(base, got, want reflect.Value, m *merger, p path) reflect.Value {
+	base, got, want = exportedValue(base), exportedValue(got), exportedValue(want)
+	gotChanged, wantChanged := !conf.equals(got, base, m.cmp, p), !conf.equals(want, base, m.cmp, p)
+	switch {
+	case !gotChanged && !wantChanged:
+		return base
+	case !wantChanged:
+		return got
+	case !gotChanged:
+		return want
+	}
+	if v, ok := conf.mergeElems(base, got, want, m, p); ok {
+		return v
+	}
+	if conf.equals(got, want, m.cmp, p) {
+		return got
+	}
+	m.errs.add(&changeError{conflictChange, base, got, want, p})
+	return base
+}
+
+// mergeElems merges the elements of the three values if they are of the same
+// shape, see descend3. It reports whether the elements were merged.
+func (conf Config) mergeElems(base, got, want reflect.Value, m *merger, p path) (reflect.Value, bool) {
+	if !base.IsValid() || !got.IsValid() || !want.IsValid() {
+		return base, false
+	}
+	typ := base.Type()
+	if got.Type() != typ || want.Type() != typ {
+		return base, false
+	}
+
+	switch base.Kind() {
+	case reflect.Ptr:
+		if base.IsNil() || got.IsNil() || want.IsNil() {
+			return base, false
+		}
+		v := visit3{base.UnsafePointer(), got.UnsafePointer(), want.UnsafePointer(), typ}
+		if ptr, ok := m.visits[v]; ok {
+			return ptr, true
+		}
+		ptr := reflect.New(typ.Elem())
+		m.visits[v] = ptr
+		setValue(ptr.Elem(), conf.merge3(base.Elem(), got.Elem(), want.Elem(), m, p))
+		return ptr, true
+	case reflect.Interface:
+		if base.IsNil() || got.IsNil() || want.IsNil() {
+			return base, false
+		}
+		elem, ok := conf.mergeElems(base.Elem(), got.Elem(), want.Elem(), m, p)
+		if !ok {
+			return base, false
+		}
+		out := reflect.New(typ).Elem()
+		out.Set(elem)
+		return out, true
+	case reflect.Struct:
+		if structIsTime(base) {
+			return base, false
+		}
+		if !base.CanAddr() || !got.CanAddr() || !want.CanAddr() {
+			// the unexported fields of addressable structs can be read
+			base, got, want = ptrCopy(base).Elem(), ptrCopy(got).Elem(), ptrCopy(want).Elem()
+		}
+		out := reflect.New(typ).Elem()
+		out.Set(base)
+		for i, n := 0, typ.NumField(); i < n; i++ {
+			if f := typ.Field(i); !conf.isOmittedField(f) {
+				q := p.add(structnode{f.Name})
+				setValue(out.Field(i), conf.merge3(base.Field(i), got.Field(i), want.Field(i), m, q))
+			}
+		}
+		return out, true
+	case reflect.Map:
+		if base.IsNil() || got.IsNil() || want.IsNil() {
+			return base, false
+		}
+		out := reflect.MakeMapWithSize(typ, base.Len())
+		for _, key := range mapKeys3(base, got, want) {
+			q := p.add(mapnode{key})
+			if v := conf.merge3(base.MapIndex(key), got.MapIndex(key), want.MapIndex(key), m, q); v.IsValid() {
+				out.SetMapIndex(key, v)
+			}
+		}
+		return out, true
+	case reflect.Slice, reflect.Array:
+		if base.Kind() == reflect.Slice && (base.IsNil() || got.IsNil() || want.IsNil()) {
+			return base, false
+		}
+		n := base.Len()
+		if got.Len() != n || want.Len() != n {
+			return base, false
+		}
+		out := reflect.New(typ).Elem()
+		if base.Kind() == reflect.Slice {
+			out = reflect.MakeSlice(typ, n, n)
+		}
+		for i := 0; i < n; i++ {
+			setValue(out.Index(i), conf.merge3(base.Index(i), got.Index(i), want.Index(i), m, p.add(arrnode{i})))
+		}
+		return out, true
+	}
+	return base, false
+}
+
+// exportedValue returns v such that it can be used to set other values, even
+// if v was obtained from an unexported struct field, provided v is addressable.
+func exportedValue(v reflect.Value) reflect.Value {
+	if !v.IsValid() || v.CanInterface() || !v.CanAddr() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// setValue sets the addressable value dst to v, even if dst was obtained from
+// an unexported struct field.
+func setValue(dst, v reflect.Value) {
+	if !v.IsValid() {
+		return
+	}
+	exportedValue(dst).Set(v)
+}
@@ -0,0 +1,151 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare3(t *testing.T) {
+	type Server struct {
+		Host  string
+		Port  int
+		Tags  []string
+		Env   map[string]string
+		Proxy *Server
+	}
+	base := Server{Host: "a", Port: 80, Tags: []string{"x"}, Env: map[string]string{"A": "1", "B": "2"}}
+
+	tests := []struct {
+		got, want Server
+		golden    string
+	}{{
+		got: base, want: base, golden: "",
+	}, {
+		got:  Server{Host: "b", Port: 80, Tags: []string{"x"}, Env: map[string]string{"A": "1", "B": "2"}},
+		want: Server{Host: "a", Port: 81, Tags: []string{"x"}, Env: map[string]string{"A": "1", "B": "2"}},
+		golden: "- (compare.Server).Host: Changed in got; base=\"a\", got=\"b\"\n" +
+			"- (compare.Server).Port: Changed in want; base=80, want=81\n",
+	}, {
+		got:  Server{Host: "a", Port: 80, Tags: []string{"x", "y"}, Env: map[string]string{"A": "1"}},
+		want: Server{Host: "a", Port: 80, Tags: []string{"z"}, Env: map[string]string{"A": "1", "B": "2", "C": "3"}},
+		golden: "- (compare.Server).Env[B]: Changed in got; base=\"2\", got=<none>\n" +
+			"- (compare.Server).Env[C]: Changed in want; base=<none>, want=\"3\"\n" +
+			"- (compare.Server).Tags: Conflicting changes; base=[]string{\"x\"}, got=[]string{\"x\", \"y\"}, want=[]string{\"z\"}\n",
+	}, {
+		got:    Server{Host: "c", Port: 80, Tags: []string{"x"}, Env: map[string]string{"A": "1", "B": "2"}},
+		want:   Server{Host: "c", Port: 80, Tags: []string{"x"}, Env: map[string]string{"A": "1", "B": "2"}},
+		golden: "- (compare.Server).Host: Changed in both; base=\"a\", got=\"c\", want=\"c\"\n",
+	}}
+
+	for _, tt := range tests {
+		err := Compare3(base, tt.got, tt.want)
+		if got := Golden(err); got != tt.golden {
+			t.Errorf("Compare3(%v, %v) got:\n%s\nwant:\n%s", tt.got, tt.want, got, tt.golden)
+		}
+	}
+
+	// pointers are descended into and cycles are terminated
+	b := &Server{Host: "a"}
+	b.Proxy = b
+	g := &Server{Host: "b"}
+	g.Proxy = g
+	w := &Server{Host: "a"}
+	w.Proxy = w
+	err := Compare3(b, g, w)
+	want := "- (*compare.Server).Host: Changed in got; base=\"a\", got=\"b\"\n"
+	if got := Golden(err); got != want {
+		t.Errorf("Compare3() got:\n%s\nwant:\n%s", got, want)
+	}
+
+	diffs := Differences(Compare3(1, 2, 3))
+	wantDiffs := []Difference{{Path: "", Kind: ConflictDiff, Got: 2, Want: 3, Detail: ChangeDifference{1}}}
+	if !reflect.DeepEqual(diffs, wantDiffs) {
+		t.Errorf("Differences(Compare3()) got=%#v, want=%#v", diffs, wantDiffs)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	type Limits struct {
+		CPU, Mem int
+		note     string
+	}
+	type Config struct {
+		Name   string
+		Port   int
+		Tags   []string
+		Env    map[string]string
+		Limits *Limits
+		hidden Limits
+	}
+	base := Config{
+		Name: "a", Port: 80, Tags: []string{"x", "y"},
+		Env:    map[string]string{"A": "1", "B": "2"},
+		Limits: &Limits{CPU: 1, Mem: 1}, hidden: Limits{note: "n"},
+	}
+
+	tests := []struct {
+		got, want Config
+		merged    Config
+		conflicts []Difference
+	}{{
+		got: base, want: base, merged: base,
+	}, {
+		got: Config{
+			Name: "b", Port: 80, Tags: []string{"x", "z"},
+			Env:    map[string]string{"A": "1"},
+			Limits: &Limits{CPU: 2, Mem: 1}, hidden: Limits{note: "m"},
+		},
+		want: Config{
+			Name: "a", Port: 81, Tags: []string{"x", "y"},
+			Env:    map[string]string{"A": "1", "B": "2", "C": "3"},
+			Limits: &Limits{CPU: 1, Mem: 2}, hidden: Limits{note: "n"},
+		},
+		merged: Config{
+			Name: "b", Port: 81, Tags: []string{"x", "z"},
+			Env:    map[string]string{"A": "1", "C": "3"},
+			Limits: &Limits{CPU: 2, Mem: 2}, hidden: Limits{note: "m"},
+		},
+	}, {
+		got: Config{
+			Name: "b", Port: 80, Tags: []string{"x"},
+			Env: map[string]string{"A": "1", "B": "2"}, Limits: &Limits{CPU: 1, Mem: 1},
+			hidden: Limits{note: "n"},
+		},
+		want: Config{
+			Name: "c", Port: 80, Tags: []string{"x"},
+			Env: map[string]string{"A": "1", "B": "2"}, Limits: &Limits{CPU: 1, Mem: 1},
+			hidden: Limits{note: "n"},
+		},
+		merged: Config{
+			Name: "a", Port: 80, Tags: []string{"x"},
+			Env: map[string]string{"A": "1", "B": "2"}, Limits: &Limits{CPU: 1, Mem: 1},
+			hidden: Limits{note: "n"},
+		},
+		conflicts: []Difference{
+			{Path: ".Name", Kind: ConflictDiff, Got: "b", Want: "c", Detail: ChangeDifference{"a"}},
+		},
+	}}
+
+	for _, tt := range tests {
+		merged, conflicts, err := Merge(base, tt.got, tt.want)
+		if err != nil {
+			t.Errorf("Merge(%v, %v) error: %v", tt.got, tt.want, err)
+			continue
+		}
+		if e := Compare(merged, tt.merged); e != nil {
+			t.Errorf("Merge(%v, %v) merged:\n%v", tt.got, tt.want, e)
+		}
+		if !reflect.DeepEqual(conflicts, tt.conflicts) {
+			t.Errorf("Merge(%v, %v) conflicts got=%#v, want=%#v", tt.got, tt.want, conflicts, tt.conflicts)
+		}
+	}
+
+	// the inputs are not modified
+	if base.Env["B"] != "2" || base.Limits.CPU != 1 {
+		t.Errorf("Merge() modified the base value: %v", base)
+	}
+
+	if _, _, err := Merge(1, "a", 1); err == nil {
+		t.Errorf("Merge() of different types got=<nil>, want an error")
+	}
+}
@@ -0,0 +1,72 @@
+package compare
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// compareTimeParts compares the location and the monotonic clock reading of
+// the two times of the same instant, if CompareTimeLocation and, respectively,
+// CompareTimeMonotonic is set.
+func (conf Config) compareTimeParts(gotv, wantv reflect.Value, cmp *comparison, p path) {
+	got, want := gotv.Interface().(time.Time), wantv.Interface().(time.Time)
+	if conf.CompareTimeLocation && !conf.IgnoreTimeZone && got.Location().String() != want.Location().String() {
+		cmp.errs.add(&timeError{got, want, "location", p})
+	}
+	if conf.CompareTimeMonotonic && monotonic(got) != monotonic(want) {
+		cmp.errs.add(&timeError{got, want, "monotonic clock reading", p})
+	}
+}
+
+// compareTimeDelta compares the two times by the duration between them, see
+// TimeTolerance and IgnoreTimeZone. The components of the times that do not
+// differ by more than the TimeTolerance are then compared as usual.
+func (conf Config) compareTimeDelta(gotv, wantv reflect.Value, cmp *comparison, p path) {
+	got, want := gotv.Interface().(time.Time), wantv.Interface().(time.Time)
+	if conf.IgnoreTimeZone {
+		got, want = wallClock(got), wallClock(want)
+	}
+	delta := got.Sub(want)
+	if delta < 0 {
+		delta = -delta
+	}
+	if delta < 0 || delta > conf.TimeTolerance {
+		// a negated minimum duration overflows
+		cmp.errs.add(&timeDeltaError{got, want, got.Sub(want), p})
+		return
+	}
+	if conf.CompareTimeLocation || conf.CompareTimeMonotonic {
+		conf.compareTimeParts(gotv, wantv, cmp, p)
+	}
+}
+
+// wallClock returns the time in UTC with the same wall clock reading as t.
+func wallClock(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+	return time.Date(y, mo, d, h, mi, s, t.Nanosecond(), time.UTC)
+}
+
+// timeComponent returns the textual representation of the given component of
+// the time t, see timeError.
+func timeComponent(t time.Time, component string) string {
+	if component == "location" {
+		return t.Location().String()
+	}
+	if m := monotonic(t); m != "" {
+		return m
+	}
+	return "<none>"
+}
+
+// monotonic returns the monotonic clock reading of t as it is rendered by the
+// String method of time.Time, e.g. "m=+0.001000001", or an empty string if t
+// has no monotonic clock reading.
+func monotonic(t time.Time) string {
+	s := t.String()
+	if i := strings.LastIndex(s, " m="); i >= 0 {
+		return s[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package compare
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareTimeParts(t *testing.T) {
+	type T struct {
+		At time.Time
+	}
+	utc := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	est := utc.In(time.FixedZone("EST", -5*60*60))
+	now := time.Now()
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: Config{},
+		a:    T{utc}, b: T{est},
+		err: "",
+	}, {
+		conf: Config{CompareTimeLocation: true},
+		a:    T{utc}, b: T{est},
+		err: "- (compare.T).At: Time location mismatch; got=UTC, want=EST\n",
+	}, {
+		conf: Config{CompareTimeLocation: true},
+		a:    T{utc}, b: T{utc.Add(time.Second)},
+		err: "- (compare.T).At: Value mismatch; got=2024-05-01T12:00:00Z, want=2024-05-01T12:00:01Z\n",
+	}, {
+		conf: Config{CompareTimeLocation: true},
+		a:    utc, b: utc.In(time.FixedZone("UTC", 0)),
+		err: "",
+	}, {
+		conf: Config{CompareTimeMonotonic: true},
+		a:    now, b: now.Round(0),
+		err: "- (time.Time): Time monotonic clock reading mismatch; got=" + monotonic(now) + ", want=<none>\n",
+	}, {
+		conf: Config{CompareTimeMonotonic: true},
+		a:    now.Round(0), b: now.Round(0).In(time.UTC),
+		err: "",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+		if eq := tt.conf.EqualNoReport(tt.a, tt.b); eq != (tt.err == "") {
+			t.Errorf("#%d: EqualNoReport() got=%t, want=%t", i, eq, tt.err == "")
+		}
+	}
+
+	diffs := Differences(Config{CompareTimeLocation: true}.Compare(utc, est))
+	want := []Difference{{"", ValueDiff, "UTC", "EST", TimeDifference{"location"}}}
+	if err := Compare(diffs, want); err != nil {
+		t.Errorf("Differences() %v", err)
+	}
+}
+
+func TestCompareTimeDelta(t *testing.T) {
+	type T struct {
+		At time.Time
+	}
+	utc := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	est := time.FixedZone("EST", -5*60*60)
+
+	tests := []struct {
+		conf Config
+		a, b interface{}
+		err  string
+	}{{
+		conf: Config{TimeTolerance: time.Millisecond},
+		a:    T{utc.Add(999 * time.Microsecond)}, b: T{utc},
+		err: "",
+	}, {
+		conf: Config{TimeTolerance: time.Millisecond},
+		a:    T{utc.Add(-time.Millisecond)}, b: T{utc},
+		err: "",
+	}, {
+		conf: Config{TimeTolerance: time.Millisecond},
+		a:    T{utc.Add(-1500 * time.Microsecond)}, b: T{utc},
+		err: "- (compare.T).At: Value mismatch; got=2024-05-01T11:59:59.9985Z, want=2024-05-01T12:00:00Z, delta=-1.5ms\n",
+	}, {
+		conf: Config{TimeTolerance: time.Second, CompareTimeLocation: true},
+		a:    T{utc.Add(time.Millisecond)}, b: T{utc.In(est)},
+		err: "- (compare.T).At: Time location mismatch; got=UTC, want=EST\n",
+	}, {
+		conf: Config{IgnoreTimeZone: true},
+		a:    T{utc}, b: T{time.Date(2024, 5, 1, 12, 0, 0, 0, est)},
+		err: "",
+	}, {
+		conf: Config{IgnoreTimeZone: true, CompareTimeLocation: true},
+		a:    T{utc}, b: T{time.Date(2024, 5, 1, 12, 0, 0, 0, est)},
+		err: "",
+	}, {
+		conf: Config{TimeTolerance: time.Hour},
+		a:    time.Time{}, b: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC),
+		err: "- (time.Time): Value mismatch; got=0001-01-01T00:00:00Z, want=9999-01-01T00:00:00Z, delta=-2562047h47m16.854775808s\n",
+	}}
+
+	for i, tt := range tests {
+		if got := Golden(tt.conf.Compare(tt.a, tt.b)); got != tt.err {
+			t.Errorf("#%d: Compare() got:\n%s\nwant:\n%s", i, got, tt.err)
+		}
+		if eq := tt.conf.EqualNoReport(tt.a, tt.b); eq != (tt.err == "") {
+			t.Errorf("#%d: EqualNoReport() got=%t, want=%t", i, eq, tt.err == "")
+		}
+	}
+
+	// the wall clock readings are reported
+	err := Config{IgnoreTimeZone: true}.Compare(utc, utc.In(est))
+	want := "- (time.Time): Value mismatch; got=2024-05-01T12:00:00Z, want=2024-05-01T07:00:00Z, delta=5h0m0s\n"
+	if got := Golden(err); got != want {
+		t.Errorf("Compare() with IgnoreTimeZone got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,303 @@
+package compare
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// CompareTypes is a wrapper around DefaultConfig.CompareTypes.
+func CompareTypes(got, want reflect.Type) error {
+	return DefaultConfig.CompareTypes(got, want)
+}
+
+// CompareTypes compares the structure of the two given types, and if the
+// comparison fails it returns an error that indicates where the two types
+// differ. Unlike Compare, which compares values, CompareTypes is intended
+// for checking that two type definitions, e.g. a generated model and its
+// hand-written counterpart, stay in sync.
+//
+// Two types are considered to be in sync if they are of the same kind and
+// if their element, key, and field types are in sync. Struct types must have
+// the same set of fields, matched by their names regardless of their order,
+// and the fields must have the same tags. If ObserveFieldTag is set, only the
+// value of that tag key is compared. Named types of basic kinds are in sync
+// with any other type of the same kind, whereas func and interface types must
+// be identical.
+func (conf Config) CompareTypes(got, want reflect.Type) error {
+	p := path{rootnode{want}}
+	tc := &typeComparison{errs: new(errorList), visits: make(map[[2]reflect.Type]bool)}
+	tc.errs.pr = conf.printer()
+	conf.compareTypeDef(got, want, tc, p)
+	return tc.errs.err()
+}
+
+// typeComparison holds the state of the CompareTypes function.
+type typeComparison struct {
+	errs   *errorList
+	visits map[[2]reflect.Type]bool // track types already compared
+}
+
+func (conf Config) compareTypeDef(got, want reflect.Type, tc *typeComparison, p path) {
+	if got == want {
+		return
+	}
+	if got == nil || want == nil || got.Kind() != want.Kind() {
+		tc.errs.add(&typeDefError{got, want, p})
+		return
+	}
+
+	// recursive types would be recursed into indefinitely
+	v := [2]reflect.Type{got, want}
+	if tc.visits[v] {
+		return
+	}
+	tc.visits[v] = true
+
+	switch want.Kind() {
+	case reflect.Ptr:
+		conf.compareTypeDef(got.Elem(), want.Elem(), tc, p)
+	case reflect.Slice:
+		conf.compareTypeDef(got.Elem(), want.Elem(), tc, p.add(elemnode{"[*]"}))
+	case reflect.Array:
+		if got.Len() != want.Len() {
+			tc.errs.add(&typeDefError{got, want, p})
+			return
+		}
+		conf.compareTypeDef(got.Elem(), want.Elem(), tc, p.add(elemnode{"[*]"}))
+	case reflect.Chan:
+		if got.ChanDir() != want.ChanDir() {
+			tc.errs.add(&typeDefError{got, want, p})
+			return
+		}
+		conf.compareTypeDef(got.Elem(), want.Elem(), tc, p.add(elemnode{"[*]"}))
+	case reflect.Map:
+		conf.compareTypeDef(got.Key(), want.Key(), tc, p.add(elemnode{"[key]"}))
+		conf.compareTypeDef(got.Elem(), want.Elem(), tc, p.add(elemnode{"[*]"}))
+	case reflect.Struct:
+		conf.compareFields(got, want, tc, p)
+	case reflect.Func, reflect.Interface:
+		tc.errs.add(&typeDefError{got, want, p})
+	}
+}
+
+// compareFields compares the fields of the two struct types.
+func (conf Config) compareFields(got, want reflect.Type, tc *typeComparison, p path) {
+	for i, n := 0, want.NumField(); i < n; i++ {
+		fw := want.Field(i)
+		q := p.add(structnode{field: fw.Name})
+		fg, ok := got.FieldByName(fw.Name)
+		if !ok || len(fg.Index) > 1 {
+			tc.errs.add(&fieldError{nil, fw.Type, q})
+			continue
+		}
+
+		tg, tw := string(fg.Tag), string(fw.Tag)
+		if len(conf.ObserveFieldTag) > 0 {
+			tg, tw = fg.Tag.Get(conf.ObserveFieldTag), fw.Tag.Get(conf.ObserveFieldTag)
+		}
+		if tg != tw {
+			tc.errs.add(&tagError{tg, tw, q})
+		}
+		conf.compareTypeDef(fg.Type, fw.Type, tc, q)
+	}
+
+	for i, n := 0, got.NumField(); i < n; i++ {
+		fg := got.Field(i)
+		if fw, ok := want.FieldByName(fg.Name); !ok || len(fw.Index) > 1 {
+			tc.errs.add(&fieldError{fg.Type, nil, p.add(structnode{field: fg.Name})})
+		}
+	}
+}
+
+type typeDefError struct {
+	got  reflect.Type
+	want reflect.Type
+	path path
+}
+
+func (err *typeDefError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *typeDefError) format(pr printer) string {
+	got := pr.color(gotColor, pr.text(typeString(err.got)))
+	want := pr.color(wantColor, pr.text(typeString(err.want)))
+	return fmt.Sprintf("%s: Type mismatch; got=%s, want=%s", err.path.format(pr), got, want)
+}
+
+func (err *typeDefError) differences() []Difference {
+	return []Difference{{err.path.relpath(), TypeDiff, err.got, err.want, nil}}
+}
+
+type fieldError struct {
+	got  reflect.Type // nil if the field is missing from got
+	want reflect.Type // nil if the field is missing from want
+	path path
+}
+
+func (err *fieldError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *fieldError) format(pr printer) string {
+	got, want := "<none>", "<none>"
+	if err.got != nil {
+		got = pr.text(err.got.String())
+	}
+	if err.want != nil {
+		want = pr.text(err.want.String())
+	}
+	got = pr.color(gotColor, got)
+	want = pr.color(wantColor, want)
+	return fmt.Sprintf("%s: Field mismatch; got=%s, want=%s", err.path.format(pr), got, want)
+}
+
+func (err *fieldError) differences() []Difference {
+	var got, want interface{}
+	if err.got != nil {
+		got = err.got
+	}
+	if err.want != nil {
+		want = err.want
+	}
+	return []Difference{{err.path.relpath(), FieldDiff, got, want, nil}}
+}
+
+type tagError struct {
+	got  string
+	want string
+	path path
+}
+
+func (err *tagError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *tagError) format(pr printer) string {
+	got := pr.color(gotColor, "`"+pr.text(err.got)+"`")
+	want := pr.color(wantColor, "`"+pr.text(err.want)+"`")
+	return fmt.Sprintf("%s: Tag mismatch; got=%s, want=%s", err.path.format(pr), got, want)
+}
+
+func (err *tagError) differences() []Difference {
+	return []Difference{{err.path.relpath(), TagDiff, err.got, err.want, nil}}
+}
+
+// elemnode represents the element or the key type of a composite type.
+type elemnode struct {
+	label string
+}
+
+func (n elemnode) str(pr printer) string {
+	return n.label
+}
+
+// typeString returns the textual representation of the type t.
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
+
+// Implements is a wrapper around DefaultConfig.Implements.
+func Implements(got interface{}, iface reflect.Type) error {
+	return DefaultConfig.Implements(got, iface)
+}
+
+// Implements checks whether the given value implements the interface type
+// iface, and if it doesn't it returns an error that indicates which of the
+// interface's methods are missing from the value's method set, or which of
+// them have a signature different from the one declared by the interface.
+// A method that is missing from the value's method set only because it has
+// a pointer receiver is reported as such.
+func (conf Config) Implements(got interface{}, iface reflect.Type) error {
+	if iface == nil || iface.Kind() != reflect.Interface {
+		return fmt.Errorf("compare: %s is not an interface type", typeString(iface))
+	}
+
+	p := path{rootnode{iface}}
+	errs := new(errorList)
+	errs.pr = conf.printer()
+
+	typ := reflect.TypeOf(got)
+	if typ == nil {
+		errs.add(&typeDefError{nil, iface, p})
+		return errs.err()
+	}
+	for i, n := 0, iface.NumMethod(); i < n; i++ {
+		want := iface.Method(i)
+		q := p.add(methodnode{want.Name})
+
+		m, ok := typ.MethodByName(want.Name)
+		if !ok {
+			_, ptr := reflect.PointerTo(typ).MethodByName(want.Name)
+			errs.add(&methodError{nil, want.Type, ptr && typ.Kind() != reflect.Ptr, q})
+			continue
+		}
+		if sig := methodSig(typ, m); sig != want.Type {
+			errs.add(&methodError{sig, want.Type, false, q})
+		}
+	}
+	return errs.err()
+}
+
+// methodSig returns the signature of the method m of the type typ without
+// its receiver, i.e. in the same form as that of an interface method.
+func methodSig(typ reflect.Type, m reflect.Method) reflect.Type {
+	if typ.Kind() == reflect.Interface {
+		return m.Type
+	}
+	in := make([]reflect.Type, m.Type.NumIn()-1)
+	for i := range in {
+		in[i] = m.Type.In(i + 1)
+	}
+	out := make([]reflect.Type, m.Type.NumOut())
+	for i := range out {
+		out[i] = m.Type.Out(i)
+	}
+	return reflect.FuncOf(in, out, m.Type.IsVariadic())
+}
+
+type methodError struct {
+	got  reflect.Type // nil if the method is missing
+	want reflect.Type
+	// set if the method is missing because it has a pointer receiver
+	ptr  bool
+	path path
+}
+
+func (err *methodError) Error() string {
+	return err.format(printer{})
+}
+
+func (err *methodError) format(pr printer) string {
+	got := "<none>"
+	if err.got != nil {
+		got = pr.text(err.got.String())
+	}
+	got = pr.color(gotColor, got)
+	want := pr.color(wantColor, pr.text(err.want.String()))
+
+	res := fmt.Sprintf("%s: Method mismatch; got=%s, want=%s", err.path.format(pr), got, want)
+	if err.ptr {
+		res += " (The method has a pointer receiver)"
+	}
+	return res
+}
+
+func (err *methodError) differences() []Difference {
+	var got interface{}
+	if err.got != nil {
+		got = err.got
+	}
+	return []Difference{{err.path.relpath(), MethodDiff, got, err.want, nil}}
+}
+
+type methodnode struct {
+	name string
+}
+
+func (n methodnode) str(pr printer) string {
+	return fmt.Sprintf(".%s()", pr.text(n.name))
+}
@@ -0,0 +1,189 @@
+package compare
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestCompareTypes(t *testing.T) {
+	type Name string
+	type A struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Tags  []string
+		Attrs map[string]int
+	}
+	type B struct {
+		Name  Name `json:"name"`
+		ID    int  `json:"id"`
+		Tags  []string
+		Attrs map[string]int
+	}
+	type C struct {
+		ID    int64  `json:"id"`
+		Name  string `json:"full_name" db:"name"`
+		Tags  []int
+		Attrs map[int]int
+		Extra bool
+	}
+	type Node struct {
+		Next *Node
+		Val  int
+	}
+	type Node2 struct {
+		Next *Node2
+		Val  int
+	}
+	type Node3 struct {
+		Next *Node3
+		Val  uint
+	}
+
+	tests := []struct {
+		conf Config
+		a, b reflect.Type
+		err  error
+	}{{
+		a: rtof(A{}), b: rtof(A{}), err: nil,
+	}, {
+		// field order and named basic types don't matter
+		a: rtof(B{}), b: rtof(A{}), err: nil,
+	}, {
+		a: rtof(Node2{}), b: rtof(Node{}), err: nil,
+	}, {
+		a: rtof(C{}), b: rtof(A{}),
+		err: elist(&typeDefError{
+			got: rtof(int64(0)), want: rtof(int(0)),
+			path: path{rootnode{rtof(A{})}, structnode{field: "ID"}},
+		}, &tagError{
+			got: `json:"full_name" db:"name"`, want: `json:"name"`,
+			path: path{rootnode{rtof(A{})}, structnode{field: "Name"}},
+		}, &typeDefError{
+			got: rtof(int(0)), want: rtof(""),
+			path: path{rootnode{rtof(A{})}, structnode{field: "Tags"}, elemnode{"[*]"}},
+		}, &typeDefError{
+			got: rtof(int(0)), want: rtof(""),
+			path: path{rootnode{rtof(A{})}, structnode{field: "Attrs"}, elemnode{"[key]"}},
+		}, &fieldError{
+			got: rtof(false), want: nil,
+			path: path{rootnode{rtof(A{})}, structnode{field: "Extra"}},
+		}),
+	}, {
+		conf: Config{ObserveFieldTag: "json"},
+		a:    rtof(C{}), b: rtof(A{}),
+		err: elist(&typeDefError{
+			got: rtof(int64(0)), want: rtof(int(0)),
+			path: path{rootnode{rtof(A{})}, structnode{field: "ID"}},
+		}, &tagError{
+			got: `full_name`, want: `name`,
+			path: path{rootnode{rtof(A{})}, structnode{field: "Name"}},
+		}, &typeDefError{
+			got: rtof(int(0)), want: rtof(""),
+			path: path{rootnode{rtof(A{})}, structnode{field: "Tags"}, elemnode{"[*]"}},
+		}, &typeDefError{
+			got: rtof(int(0)), want: rtof(""),
+			path: path{rootnode{rtof(A{})}, structnode{field: "Attrs"}, elemnode{"[key]"}},
+		}, &fieldError{
+			got: rtof(false), want: nil,
+			path: path{rootnode{rtof(A{})}, structnode{field: "Extra"}},
+		}),
+	}, {
+		a: rtof(struct{ X int }{}), b: rtof(struct{ Y int }{}),
+		err: elist(&fieldError{
+			got: nil, want: rtof(int(0)),
+			path: path{rootnode{rtof(struct{ Y int }{})}, structnode{field: "Y"}},
+		}, &fieldError{
+			got: rtof(int(0)), want: nil,
+			path: path{rootnode{rtof(struct{ Y int }{})}, structnode{field: "X"}},
+		}),
+	}, {
+		a: rtof(Node3{}), b: rtof(Node{}),
+		err: elist(&typeDefError{
+			got: rtof(uint(0)), want: rtof(int(0)),
+			path: path{rootnode{rtof(Node{})}, structnode{field: "Val"}},
+		}),
+	}, {
+		a: rtof([3]int{}), b: rtof([4]int{}),
+		err: elist(&typeDefError{
+			got: rtof([3]int{}), want: rtof([4]int{}),
+			path: path{rootnode{rtof([4]int{})}},
+		}),
+	}, {
+		a: rtof(func(int) {}), b: rtof(func(string) {}),
+		err: elist(&typeDefError{
+			got: rtof(func(int) {}), want: rtof(func(string) {}),
+			path: path{rootnode{rtof(func(string) {})}},
+		}),
+	}}
+
+	for _, tt := range tests {
+		err := tt.conf.CompareTypes(tt.a, tt.b)
+		if errstr(err) != errstr(tt.err) {
+			t.Errorf("CompareTypes(%v, %v) = %v\n\n", tt.a, tt.b, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(tt.err))
+		}
+	}
+}
+
+type reader struct{}
+
+func (reader) Read(p []byte) (int, error) { return 0, nil }
+func (*reader) Close() error              { return nil }
+
+type badReader struct{}
+
+func (badReader) Read(p []byte) int { return 0 }
+
+func TestImplements(t *testing.T) {
+	rc := reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+	rd := reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+	tests := []struct {
+		v     interface{}
+		iface reflect.Type
+		err   error
+	}{{
+		v: reader{}, iface: rd, err: nil,
+	}, {
+		v: &reader{}, iface: rc, err: nil,
+	}, {
+		v: reader{}, iface: rc,
+		err: elist(&methodError{
+			got: nil, want: rtof(func() error { return nil }), ptr: true,
+			path: path{rootnode{rc}, methodnode{"Close"}},
+		}),
+	}, {
+		v: badReader{}, iface: rc,
+		err: elist(&methodError{
+			got: nil, want: rtof(func() error { return nil }),
+			path: path{rootnode{rc}, methodnode{"Close"}},
+		}, &methodError{
+			got:  rtof(func([]byte) int { return 0 }),
+			want: rtof(func([]byte) (int, error) { return 0, nil }),
+			path: path{rootnode{rc}, methodnode{"Read"}},
+		}),
+	}, {
+		v: nil, iface: rd,
+		err: elist(&typeDefError{got: nil, want: rd, path: path{rootnode{rd}}}),
+	}, {
+		v: reader{}, iface: rtof(reader{}),
+		err: errors.New("compare: compare.reader is not an interface type"),
+	}}
+
+	for _, tt := range tests {
+		err := Implements(tt.v, tt.iface)
+		if errstr(err) != errstr(tt.err) {
+			t.Errorf("Implements(%T, %v) = %v\n\n", tt.v, tt.iface, err)
+			t.Errorf("\"%s\" != \"%s\"", errstr(err), errstr(tt.err))
+		}
+	}
+
+	// the message of a method with a pointer receiver
+	err := Implements(reader{}, rc)
+	want := "- (io.ReadCloser).Close(): Method mismatch; got=<none>, want=func() error (The method has a pointer receiver)"
+	if Golden(err) != want+"\n" {
+		t.Errorf("Implements() got=%q, want=%q", Golden(err), want)
+	}
+}
@@ -0,0 +1,65 @@
+package compare
+
+import (
+	"reflect"
+)
+
+// WalkAction is returned by a WalkFunc to control the traversal of Walk.
+type WalkAction int
+
+const (
+	// The traversal continues with the contents of the visited values.
+	WalkContinue WalkAction = iota
+	// The contents of the visited values are skipped, they are neither
+	// visited nor compared.
+	WalkSkip
+	// The traversal is stopped, no more values are visited or compared.
+	WalkStop
+)
+
+// Path is the path of the values visited by Walk, in the same syntax as that
+// of Difference.Path, e.g. ".Authors[0].FirstName". The path of the root values
+// is empty.
+type Path string
+
+// WalkFunc is the func called by Walk for each of the visited pairs of values.
+type WalkFunc func(path Path, got, want reflect.Value) WalkAction
+
+// Walk is a wrapper around DefaultConfig.Walk.
+func Walk(got, want interface{}, visit WalkFunc) error {
+	return DefaultConfig.Walk(got, want, visit)
+}
+
+// Walk compares the two given values just like Compare does, and calls visit
+// for each of the pairs of values the comparison visits, before their contents
+// are compared. It allows building custom analyses, e.g. counting the values
+// by their type, on top of the same traversal as that of Compare. The visited
+// values are those that are valid, of the same type, and not yet visited, i.e.
+// the values that are reported as different by the validity and type checks,
+// as well as the values matched by a Matcher, are not visited, and a cycle of
+// the values is visited only once. The pointers and interfaces are visited at
+// the same path as the values they hold. The values obtained from unexported
+// struct fields are visited as is, and therefore may not be retrievable with
+// Interface. The action returned by visit controls whether the traversal
+// continues into the contents of the values, skips them, or stops, see
+// WalkAction. Walk returns the error reporting the differences found by the
+// comparison, if any, and it does not use the ResultCache.
+func (conf Config) Walk(got, want interface{}, visit WalkFunc) error {
+	if conf.Strict {
+		conf = conf.strict()
+	}
+	return conf.compareRoot(got, want, visit)
+}
+
+// visitWalk calls the walk func of the comparison for the two given values and
+// reports whether their comparison needs to continue.
+func (cmp *comparison) visitWalk(got, want reflect.Value, p path) (ok bool) {
+	switch cmp.walk(Path(p.relpath()), got, want) {
+	case WalkSkip:
+		return false
+	case WalkStop:
+		cmp.halted = true
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	type Item struct {
+		Name string
+		Tags []string
+	}
+	type T struct {
+		ID    int
+		Items []Item
+		Skip  *Item
+	}
+	a := T{ID: 1, Items: []Item{{"a", []string{"x"}}}, Skip: &Item{Name: "a"}}
+	b := T{ID: 2, Items: []Item{{"a", []string{"y"}}}, Skip: &Item{Name: "b"}}
+
+	var paths []Path
+	err := Walk(a, b, func(p Path, got, want reflect.Value) WalkAction {
+		paths = append(paths, p)
+		if p == ".Skip" {
+			return WalkSkip
+		}
+		return WalkContinue
+	})
+	want := []Path{"", ".ID", ".Items", ".Items[0]", ".Items[0].Name", ".Items[0].Tags", ".Items[0].Tags[0]", ".Skip"}
+	if e := Compare(paths, want); e != nil {
+		t.Errorf("Walk() paths: %v", e)
+	}
+	if got := Differences(err); len(got) != 2 || got[0].Path != ".ID" || got[1].Path != ".Items[0].Tags[0]" {
+		t.Errorf("Walk() differences got=%+v", got)
+	}
+
+	// the values can be counted by their kind
+	kinds := map[reflect.Kind]int{}
+	c := T{ID: 1, Items: []Item{{"a", []string{"x"}}}, Skip: &Item{Name: "a"}}
+	Walk(a, c, func(p Path, got, want reflect.Value) WalkAction {
+		kinds[got.Kind()]++
+		return WalkContinue
+	})
+	if kinds[reflect.String] != 3 || kinds[reflect.Slice] != 3 {
+		t.Errorf("Walk() kinds got=%v", kinds)
+	}
+
+	// the walk can be stopped
+	paths = nil
+	err = Walk(a, b, func(p Path, got, want reflect.Value) WalkAction {
+		paths = append(paths, p)
+		if p == ".ID" {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	if e := Compare(paths, []Path{"", ".ID"}); e != nil || err != nil {
+		t.Errorf("Walk() with WalkStop paths: %v, err=%v", e, err)
+	}
+}